@@ -17,4 +17,12 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(helloCmd)
+	rootCmd.AddCommand(migrateCmd)
+
+	// 数据库迁移子命令
+	for _, c := range []*cobra.Command{migrateUpCmd, migrateDownCmd, migrateRedoCmd} {
+		c.Flags().BoolVar(&migrateDryRun, "dry-run", false, "只输出会执行的 SQL，不修改数据库")
+	}
+	migrateCreateCmd.Flags().StringVar(&migrateDir, "dir", "internal/data/migrations", "迁移文件目录")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateRedoCmd, migrateStatusCmd, migrateCreateCmd, migrateForceCmd)
 }
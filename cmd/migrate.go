@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/HoronLee/GinHub/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateDryRun bool   // 只输出会执行的 SQL，不修改数据库
+	migrateDir    string // 迁移文件目录
+)
+
+// migrateCmd 是管理数据库迁移的命令
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "管理数据库迁移",
+	Long:  `管理数据库迁移，使用 -c 指定的配置文件中的数据库连接。关闭启动时自动迁移（database.migration.disable_auto）后，需要在启动服务前执行 migrate up`,
+}
+
+// migrateUpCmd 是执行迁移的命令
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "执行未执行的迁移，指定 N 时最多执行 N 个",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, err := parseSteps(args, 0)
+		if err != nil {
+			return err
+		}
+		return cli.DoMigrateUp(steps, migrateDryRun)
+	},
+}
+
+// migrateDownCmd 是回滚迁移的命令
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "回滚最近执行的 N 个迁移，默认为 1 个",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, err := parseSteps(args, 1)
+		if err != nil {
+			return err
+		}
+		return cli.DoMigrateDown(steps, migrateDryRun)
+	},
+}
+
+// migrateRedoCmd 是重新执行最近一个迁移的命令
+var migrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "回滚最近执行的一个迁移后重新执行",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoMigrateRedo(migrateDryRun)
+	},
+}
+
+// migrateStatusCmd 是查看迁移状态的命令
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看迁移的执行状态",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoMigrateStatus()
+	},
+}
+
+// migrateCreateCmd 是创建迁移文件的命令
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "为每个数据库驱动创建新的迁移文件",
+	Long:  `在迁移目录下为每个数据库驱动创建下一个版本的 up 和 down 迁移文件。迁移文件会嵌入到程序中，修改后需要重新编译`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoMigrateCreate(migrateDir, args[0])
+	},
+}
+
+// migrateForceCmd 是强制设置迁移版本的命令
+var migrateForceCmd = &cobra.Command{
+	Use:   "force <version>",
+	Short: "不执行迁移，直接将迁移记录设置为指定版本",
+	Long:  `不执行迁移，直接将指定版本及之前的迁移记录为已执行，并删除之后的记录。用于迁移执行失败、人工修复表结构后修正迁移记录，版本为 0 时清空所有记录`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[0])
+		}
+		return cli.DoMigrateForce(version)
+	},
+}
+
+// parseSteps 解析迁移数量参数，未指定时返回 def
+func parseSteps(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("invalid number of migrations: %s", args[0])
+	}
+	return steps, nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/tui"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/migrate"
+)
+
+// newMigrator 按配置连接数据库并创建迁移器，dryRun 为 true 时只输出会执行的 SQL
+func newMigrator(dryRun bool) (*migrate.Migrator, func(), error) {
+	db, err := data.OpenDB(&config.Config, util.NewLogger(&config.Config))
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	m, err := data.NewMigrator(db, &config.Config)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if dryRun {
+		m = m.DryRun(os.Stdout)
+	}
+	return m, cleanup, nil
+}
+
+// DoMigrateUp 执行未执行的迁移，steps 大于 0 时最多执行 steps 个
+func DoMigrateUp(steps int, dryRun bool) error {
+	m, cleanup, err := newMigrator(dryRun)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	applied, err := m.Up(context.Background(), steps)
+	if !dryRun {
+		printMigrations("✅ 执行迁移", applied)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		tui.PrintCLIInfo("🎉 执行迁移", "数据库已是最新版本")
+	}
+	return nil
+}
+
+// DoMigrateDown 回滚最近执行的 steps 个迁移
+func DoMigrateDown(steps int, dryRun bool) error {
+	m, cleanup, err := newMigrator(dryRun)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	reverted, err := m.Down(context.Background(), steps)
+	if !dryRun {
+		printMigrations("↩️ 回滚迁移", reverted)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		tui.PrintCLIInfo("⚠️ 回滚迁移", "没有已执行的迁移")
+	}
+	return nil
+}
+
+// DoMigrateRedo 回滚最近执行的一个迁移后重新执行
+func DoMigrateRedo(dryRun bool) error {
+	m, cleanup, err := newMigrator(dryRun)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	mig, err := m.Redo(context.Background())
+	if errors.Is(err, migrate.ErrNoAppliedMigration) {
+		tui.PrintCLIInfo("⚠️ 重新执行迁移", "没有已执行的迁移")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		tui.PrintCLIInfo("🔁 重新执行迁移", mig.String())
+	}
+	return nil
+}
+
+// DoMigrateForce 不执行迁移，直接将迁移记录设置为 version
+func DoMigrateForce(version uint64) error {
+	m, cleanup, err := newMigrator(false)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err = m.Force(context.Background(), version); err != nil {
+		return err
+	}
+	tui.PrintCLIInfo("📌 设置迁移版本", strconv.FormatUint(version, 10))
+	return nil
+}
+
+// DoMigrateStatus 打印所有迁移的执行状态
+func DoMigrateStatus() error {
+	m, cleanup, err := newMigrator(false)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		note := ""
+		switch {
+		case s.Unknown:
+			note = "unknown to this build"
+		case s.ChecksumMismatch:
+			note = "checksum mismatch"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
+
+// DoMigrateCreate 在 dir 下为每个数据库驱动创建新的迁移文件
+func DoMigrateCreate(dir, name string) error {
+	files, err := data.CreateMigration(dir, name)
+	for _, file := range files {
+		tui.PrintCLIInfo("📝 创建迁移文件", file)
+	}
+	return err
+}
+
+// printMigrations 逐个打印迁移
+func printMigrations(title string, migrations []*migrate.Migration) {
+	for _, mig := range migrations {
+		tui.PrintCLIInfo(title, mig.String())
+	}
+}
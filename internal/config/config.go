@@ -27,18 +27,156 @@ type AppConfig struct {
 		Mode string `mapstructure:"mode"` // 运行模式，可能的值为 "debug" 或 "release"
 	} `mapstructure:"server"`
 	Database struct {
-		Driver  string `mapstructure:"type"`    // 数据库驱动
-		Source  string `mapstructure:"source"`  // 数据库连接字符串
+		Driver  string `mapstructure:"type"`    // 数据库驱动，支持 mysql、postgres 和 sqlite
+		Source  string `mapstructure:"source"`  // 数据库连接字符串，postgres 驱动使用 postgres 中的配置拼接
 		LogMode string `mapstructure:"logmode"` // 数据库日志模式
+
+		QueryTimeout int `mapstructure:"query_timeout"` // 单条 SQL 的执行超时时间，单位为秒，0 表示不限制
+
+		Pool struct {
+			MaxOpenConns    int `mapstructure:"max_open_conns"`     // 最大打开连接数，0 时使用默认值 100
+			MaxIdleConns    int `mapstructure:"max_idle_conns"`     // 最大空闲连接数，0 时使用默认值 10
+			ConnMaxLifetime int `mapstructure:"conn_max_lifetime"`  // 连接的最长存活时间，单位为秒，0 时使用默认值 3600
+			ConnMaxIdleTime int `mapstructure:"conn_max_idle_time"` // 连接的最长空闲时间，单位为秒，0 表示不限制
+		} `mapstructure:"pool"`
+
+		Connect struct {
+			Retries       int `mapstructure:"retries"`         // 启动时数据库不可达的重试次数，0 表示不重试
+			RetryDelay    int `mapstructure:"retry_delay"`     // 首次重试前的等待时间，单位为秒，之后每次翻倍
+			MaxRetryDelay int `mapstructure:"max_retry_delay"` // 重试等待时间的上限，单位为秒
+		} `mapstructure:"connect"`
+
+		Postgres struct {
+			Host       string `mapstructure:"host"`        // 主机地址，以 / 开头时为 Unix socket 目录
+			Port       int    `mapstructure:"port"`        // 端口
+			User       string `mapstructure:"user"`        // 用户名
+			Password   string `mapstructure:"password"`    // 密码
+			DBName     string `mapstructure:"dbname"`      // 数据库名
+			SSLMode    string `mapstructure:"sslmode"`     // SSL 模式：disable、require、verify-ca、verify-full
+			SearchPath string `mapstructure:"search_path"` // schema 搜索路径，为空时使用数据库默认值
+		} `mapstructure:"postgres"`
+
+		Transaction struct {
+			MaxRetries int `mapstructure:"max_retries"` // 事务遇到死锁或序列化冲突时的最大重试次数，0 表示不重试
+			RetryDelay int `mapstructure:"retry_delay"` // 首次重试前的等待时间，单位为毫秒，之后每次翻倍
+		} `mapstructure:"transaction"`
+
+		Replicas struct {
+			Sources             []string `mapstructure:"sources"`               // 只读副本的连接字符串，格式与 source 相同，postgres 驱动使用 URL 格式；为空时不启用读写分离
+			Policy              string   `mapstructure:"policy"`                // 副本选择策略：round_robin 轮询，least_latency 选择延迟最低的副本
+			HealthCheckInterval int      `mapstructure:"health_check_interval"` // 副本健康检查间隔，单位为秒，检查失败的副本移出轮询直到恢复
+		} `mapstructure:"replicas"`
+
+		Migration struct {
+			DisableAuto bool `mapstructure:"disable_auto"` // 启动时不自动执行数据库迁移，只检查表结构是否为最新版本
+			LockTimeout int  `mapstructure:"lock_timeout"` // 等待其他实例释放迁移锁的最长时间，单位为秒
+		} `mapstructure:"migration"`
 	} `mapstructure:"database"`
 	Auth struct {
 		Jwt struct {
-			Secret   string `mapstructure:"secret"`   // JWT的密钥
-			Expires  int    `mapstructure:"expires"`  // JWT的过期时间，单位为秒
-			Issuer   string `mapstructure:"issuer"`   // JWT的发行者
-			Audience string `mapstructure:"audience"` // JWT的受众
+			Secret         string `mapstructure:"secret"`          // JWT的密钥
+			Expires        int    `mapstructure:"expires"`         // JWT的过期时间，单位为秒
+			Issuer         string `mapstructure:"issuer"`          // JWT的发行者
+			Audience       string `mapstructure:"audience"`        // JWT的受众
+			Leeway         int    `mapstructure:"leeway"`          // 校验过期时间和生效时间时允许的时钟偏差，单位为秒
+			RefreshExpires int    `mapstructure:"refresh_expires"` // 刷新令牌的过期时间，单位为秒
+			Algorithm      string `mapstructure:"algorithm"`       // JWT的签名算法，可能的值为 "HS256"、"RS256"、"ES256" 或 "EdDSA"
+			ActiveKeyID    string `mapstructure:"active_kid"`      // 非对称算法用于签名的密钥ID，为空时使用第一个带私钥的密钥
+			Keys           []struct {
+				ID         string `mapstructure:"kid"`         // 密钥ID，写入令牌头部的 kid
+				PrivateKey string `mapstructure:"private_key"` // PEM 私钥文件路径，仅用于验证的旧密钥可留空
+				PublicKey  string `mapstructure:"public_key"`  // PEM 公钥文件路径，提供私钥时可留空
+			} `mapstructure:"keys"` // 非对称算法的密钥集，轮换期间可同时配置新旧密钥
 		} `mapstructure:"jwt"`
+		TokenLookup struct {
+			Header           string `mapstructure:"header"`             // 读取访问令牌的请求头，为空时不从请求头读取
+			Scheme           string `mapstructure:"scheme"`             // 请求头中令牌的认证方案前缀
+			Cookie           string `mapstructure:"cookie"`             // 读取访问令牌的Cookie名称，为空时不从Cookie读取
+			Query            string `mapstructure:"query"`              // 读取访问令牌的查询参数名称，为空时不从查询参数读取
+			QueryUpgradeOnly bool   `mapstructure:"query_upgrade_only"` // 是否只在协议升级请求（如WebSocket）中读取查询参数
+		} `mapstructure:"token_lookup"`
+		APIKey struct {
+			Header           string `mapstructure:"header"`             // 读取 API Key 的请求头，为空时不从该请求头读取
+			Scheme           string `mapstructure:"scheme"`             // Authorization 请求头中 API Key 的认证方案，为空时不从 Authorization 读取
+			LastUsedInterval int    `mapstructure:"last_used_interval"` // 最近使用时间的最小更新间隔，单位为秒
+		} `mapstructure:"api_key"`
+		Password struct {
+			Algorithm  string `mapstructure:"algorithm"`   // 密码哈希算法，可能的值为 "argon2id" 或 "bcrypt"
+			BcryptCost int    `mapstructure:"bcrypt_cost"` // bcrypt 的计算成本
+			Argon2     struct {
+				Memory      uint32 `mapstructure:"memory"`      // argon2id 内存开销，单位为KiB
+				Iterations  uint32 `mapstructure:"iterations"`  // argon2id 迭代次数
+				Parallelism uint8  `mapstructure:"parallelism"` // argon2id 并行度
+				SaltLength  uint32 `mapstructure:"salt_length"` // 盐长度，单位为字节
+				KeyLength   uint32 `mapstructure:"key_length"`  // 哈希长度，单位为字节
+			} `mapstructure:"argon2"`
+		} `mapstructure:"password"`
+		Revocation struct {
+			Store      string `mapstructure:"store"`       // 令牌吊销列表存储，可能的值为 "memory" 或 "database"
+			GCInterval int    `mapstructure:"gc_interval"` // 过期吊销记录的清理间隔，单位为秒
+		} `mapstructure:"revocation"`
+		Login struct {
+			Store           string `mapstructure:"store"`            // 登录失败计数存储，可能的值为 "memory" 或 "database"
+			Window          int    `mapstructure:"window"`           // 失败计数的统计窗口，超过该时间没有新的失败则计数清零，单位为秒
+			FreeAttempts    int    `mapstructure:"free_attempts"`    // 不触发退避等待的失败次数
+			BackoffBase     int    `mapstructure:"backoff_base"`     // 退避等待的初始时长，之后每次失败翻倍，单位为秒
+			BackoffMax      int    `mapstructure:"backoff_max"`      // 退避等待的最大时长，单位为秒
+			MaxAttempts     int    `mapstructure:"max_attempts"`     // 同一用户名连续失败达到该次数后锁定账户，为0时不锁定
+			IPMaxAttempts   int    `mapstructure:"ip_max_attempts"`  // 同一IP失败达到该次数后在锁定时长内拒绝该IP登录，为0时不限制
+			LockoutDuration int    `mapstructure:"lockout_duration"` // 账户或IP的锁定时长，单位为秒
+		} `mapstructure:"login"`
+		PasswordReset struct {
+			Expires   int    `mapstructure:"expires"`    // 密码重置令牌的有效期，单位为秒
+			URL       string `mapstructure:"url"`        // 邮件中的重置链接，{token} 会被替换为重置令牌；为空时邮件中只包含令牌
+			UserLimit int    `mapstructure:"user_limit"` // 同一账户每小时最多发送的重置邮件数，超过后不再发送，默认3
+			IPLimit   int    `mapstructure:"ip_limit"`   // 同一IP每小时最多申请重置密码的次数，超过后不再发送，默认10
+		} `mapstructure:"password_reset"`
+		EmailVerification struct {
+			Required  bool   `mapstructure:"required"`   // 是否要求验证邮箱后才能登录
+			Expires   int    `mapstructure:"expires"`    // 邮箱验证令牌的有效期，单位为秒
+			URL       string `mapstructure:"url"`        // 邮件中的验证链接，{token} 会被替换为验证令牌；为空时邮件中只包含令牌
+			UserLimit int    `mapstructure:"user_limit"` // 同一账户每小时最多重新发送的验证邮件数，超过后不再发送，默认3
+			IPLimit   int    `mapstructure:"ip_limit"`   // 同一IP每小时最多请求重新发送的次数，超过后不再发送，默认10
+		} `mapstructure:"email_verification"`
+		Session struct {
+			LastSeenInterval int `mapstructure:"last_seen_interval"` // 会话最近活跃时间的最小更新间隔，单位为秒
+		} `mapstructure:"session"`
+		Deletion struct {
+			GracePeriod   int `mapstructure:"grace_period"`   // 已删除用户的保留期，超过后彻底删除且不能恢复，为0时不清理，单位为秒
+			PurgeInterval int `mapstructure:"purge_interval"` // 清理已删除用户的间隔，单位为秒
+		} `mapstructure:"deletion"`
+		MFA struct {
+			Issuer           string `mapstructure:"issuer"`            // 身份验证器中显示的发行方名称
+			ChallengeExpires int    `mapstructure:"challenge_expires"` // 两步验证挑战令牌的过期时间，单位为秒
+			RecoveryCodes    int    `mapstructure:"recovery_codes"`    // 开启两步验证时生成的恢复码数量
+			MaxAttempts      int    `mapstructure:"max_attempts"`      // 每个挑战令牌允许输错验证码的次数，达到后挑战令牌失效，需重新登录
+		} `mapstructure:"mfa"`
+		OAuth struct {
+			Issuer             string `mapstructure:"issuer"`               // OpenID Connect 签发者，即对外访问的服务地址，各端点地址由其拼接而成
+			LoginURL           string `mapstructure:"login_url"`            // 授权时用户未登录跳转的登录页面，{return_to} 会被替换为授权地址；为空时提示用户先登录
+			CodeExpires        int    `mapstructure:"code_expires"`         // 授权码和授权确认页面的有效期，单位为秒
+			AccessTokenExpires int    `mapstructure:"access_token_expires"` // OAuth2 访问令牌和 ID Token 的有效期，单位为秒
+		} `mapstructure:"oauth"`
+		SSO struct {
+			StateExpires int            `mapstructure:"state_expires"` // 外部登录流程（state、nonce 和 PKCE 参数）的有效期，单位为秒
+			Providers    []OIDCProvider `mapstructure:"providers"`     // 外部 OpenID Connect 身份提供方
+		} `mapstructure:"sso"`
+		RBAC struct {
+			DefaultRole string   `mapstructure:"default_role"` // 注册时默认分配的角色
+			Admins      []string `mapstructure:"admins"`       // 启动时授予管理员角色的已有用户名，注册时不会授予
+		} `mapstructure:"rbac"`
 	} `mapstructure:"auth"`
+	Mail struct {
+		Driver string `mapstructure:"driver"` // 邮件发送方式，可能的值为 "log"、"file" 或 "smtp"
+		From   string `mapstructure:"from"`   // 发件人地址
+		Dir    string `mapstructure:"dir"`    // file 方式保存邮件的目录
+		SMTP   struct {
+			Host     string `mapstructure:"host"`     // SMTP 服务器地址
+			Port     int    `mapstructure:"port"`     // SMTP 服务器端口
+			Username string `mapstructure:"username"` // SMTP 认证用户名，为空时不认证
+			Password string `mapstructure:"password"` // SMTP 认证密码
+		} `mapstructure:"smtp"`
+	} `mapstructure:"mail"`
 	Swagger struct {
 		Host         string   `mapstructure:"host"`          // Swagger文档的主机地址
 		BasePath     string   `mapstructure:"basepath"`      // API基础路径
@@ -54,6 +192,17 @@ type AppConfig struct {
 	} `mapstructure:"swagger"`
 }
 
+// OIDCProvider 外部 OpenID Connect 身份提供方配置
+type OIDCProvider struct {
+	Name         string   `mapstructure:"name"`          // 提供方标识，用于登录地址 /api/v1/auth/{name}/login
+	Issuer       string   `mapstructure:"issuer"`        // 提供方的签发者地址，各端点通过其发现文档获取
+	ClientID     string   `mapstructure:"client_id"`     // 在提供方注册的客户端标识
+	ClientSecret string   `mapstructure:"client_secret"` // 在提供方注册的客户端密钥
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 在提供方登记的回调地址，指向 /api/v1/auth/{name}/callback
+	Scopes       []string `mapstructure:"scopes"`        // 申请的权限范围，openid 始终包含
+	AutoRegister bool     `mapstructure:"auto_register"` // 首次登录时是否自动创建本地账户
+}
+
 //go:embed config.yaml
 var configData []byte
 
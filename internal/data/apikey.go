@@ -0,0 +1,100 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+)
+
+// apiKeyRepo API Key 数据访问实现
+type apiKeyRepo struct {
+	data *Data
+}
+
+// NewAPIKeyRepo 创建APIKeyRepo实例
+func NewAPIKeyRepo(data *Data) service.APIKeyRepo {
+	return &apiKeyRepo{
+		data: data,
+	}
+}
+
+// CreateAPIKey 创建 API Key 记录
+func (r *apiKeyRepo) CreateAPIKey(ctx context.Context, key *user.APIKey) error {
+	r.data.log.Debug("Creating api key", zap.Uint("user_id", key.UserID), zap.String("prefix", key.Prefix))
+	err := r.data.DB(ctx).Create(key).Error
+	if err != nil {
+		r.data.log.Error("Failed to create api key", zap.Error(err), zap.Uint("user_id", key.UserID))
+		return err
+	}
+	r.data.log.Info("API key created", zap.Uint("user_id", key.UserID), zap.String("prefix", key.Prefix))
+	return nil
+}
+
+// ListUserAPIKeys 查询用户未吊销的 API Key
+func (r *apiKeyRepo) ListUserAPIKeys(ctx context.Context, userID uint) ([]user.APIKey, error) {
+	var keys []user.APIKey
+	err := r.data.DB(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("id").Find(&keys).Error
+	if err != nil {
+		r.data.log.Error("Failed to list api keys", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash 根据密钥摘要查询 API Key
+func (r *apiKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*user.APIKey, error) {
+	var key user.APIKey
+	err := r.data.DB(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		r.data.log.Debug("API key not found", zap.Error(err))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey 吊销用户的 API Key
+func (r *apiKeyRepo) RevokeAPIKey(ctx context.Context, userID, id uint) (bool, error) {
+	result := r.data.DB(ctx).Model(&user.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to revoke api key", zap.Error(result.Error), zap.Uint("id", id))
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		r.data.log.Info("API key revoked", zap.Uint("id", id), zap.Uint("user_id", userID))
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeUserAPIKeys 吊销用户全部未吊销的 API Key
+func (r *apiKeyRepo) RevokeUserAPIKeys(ctx context.Context, userID uint) error {
+	result := r.data.DB(ctx).Model(&user.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to revoke user api keys", zap.Error(result.Error), zap.Uint("user_id", userID))
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		r.data.log.Info("User api keys revoked", zap.Uint("user_id", userID), zap.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
+
+// UpdateAPIKeyLastUsed 更新 API Key 的最近使用时间
+func (r *apiKeyRepo) UpdateAPIKeyLastUsed(ctx context.Context, id uint, at time.Time) error {
+	err := r.data.DB(ctx).Model(&user.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+	if err != nil {
+		r.data.log.Error("Failed to update api key last used", zap.Error(err), zap.Uint("id", id))
+		return err
+	}
+	return nil
+}
@@ -1,22 +1,28 @@
 package data
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/HoronLee/GinHub/internal/config"
-	"github.com/HoronLee/GinHub/internal/model/helloworld"
-	"github.com/HoronLee/GinHub/internal/model/user"
 	util "github.com/HoronLee/GinHub/internal/util/log"
 	"github.com/google/wire"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewDB, NewData, NewHelloWorldRepo, NewUserRepo)
+var ProviderSet = wire.NewSet(NewDB, NewData, NewTransactor, NewHelloWorldRepo, NewUserRepo, NewRefreshTokenRepo, NewRevocationStore, NewRoleRepo, NewAPIKeyRepo, NewMFARepo, NewLoginAttemptStore, NewPasswordResetRepo, NewEmailVerificationRepo, NewSessionRepo, NewOAuthRepo, NewIdentityRepo)
 
 // Data 统一的数据访问层结构体
 type Data struct {
@@ -24,10 +30,13 @@ type Data struct {
 	log *util.Logger
 }
 
-// NewData 创建Data实例
+// NewData 创建Data实例，cleanup 时关闭数据库连接
 func NewData(db *gorm.DB, logger *util.Logger) (*Data, func(), error) {
 	cleanup := func() {
 		logger.Info("closing the data resources")
+		if err := closeDB(db); err != nil {
+			logger.Error("failed to close database", zap.Error(err))
+		}
 	}
 	return &Data{
 		db:  db,
@@ -35,50 +44,213 @@ func NewData(db *gorm.DB, logger *util.Logger) (*Data, func(), error) {
 	}, cleanup, nil
 }
 
-// NewDB 创建数据库连接
+// DBHealth 数据库健康状态
+type DBHealth struct {
+	Latency  time.Duration   // Ping 耗时
+	Stats    sql.DBStats     // 连接池统计
+	Replicas []ReplicaHealth // 只读副本的健康状态，未启用读写分离时为空
+}
+
+// ReplicaHealth 只读副本的健康状态
+type ReplicaHealth struct {
+	Healthy bool          // 最近一次健康检查是否成功，不健康的副本不参与读取
+	Latency time.Duration // 最近一次成功的健康检查耗时
+	Stats   sql.DBStats   // 连接池统计
+}
+
+// Health 检查数据库连接，返回 Ping 耗时和连接池统计，Ping 失败时同时返回统计和错误
+func (d *Data) Health(ctx context.Context) (*DBHealth, error) {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	health := &DBHealth{
+		Latency: time.Since(start),
+		Stats:   sqlDB.Stats(),
+	}
+	if replicas := replicasOf(d.db); replicas != nil {
+		health.Replicas = replicas.health()
+	}
+	return health, err
+}
+
+// NewDB 创建数据库连接，执行数据库迁移并初始化内置数据
 func NewDB(cfg *config.AppConfig, logger *util.Logger) (*gorm.DB, error) {
+	db, err := OpenDB(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行数据库迁移
+	if err = migrateDB(db, cfg, logger); err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	// 初始化内置角色和权限
+	if err = seedRBAC(db, cfg); err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("failed to seed roles: %w", err)
+	}
+
+	// 迁移可能耗时较长，迁移完成后才限制单条 SQL 的执行时间
+	if err = registerQueryTimeout(db, time.Duration(cfg.Database.QueryTimeout)*time.Second); err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("failed to register query timeout: %w", err)
+	}
+
+	// 迁移和初始化数据需要读到主库的最新状态，完成后才启用只读副本
+	if err = setupReplicas(db, cfg, logger); err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("failed to setup replicas: %w", err)
+	}
+
+	return db, nil
+}
+
+const (
+	defaultMaxOpenConns    = 100
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = time.Hour
+)
+
+// OpenDB 按配置连接数据库，不执行迁移。数据库暂不可达时按配置的次数重试，等待时间逐次翻倍
+func OpenDB(cfg *config.AppConfig, logger *util.Logger) (*gorm.DB, error) {
+	connect := cfg.Database.Connect
+	delay := time.Duration(connect.RetryDelay) * time.Second
+	maxDelay := time.Duration(connect.MaxRetryDelay) * time.Second
+
+	var db *gorm.DB
+	var err error
+	for attempt := 0; ; attempt++ {
+		db, err = openDB(cfg, logger)
+		if err == nil || errors.Is(err, errUnsupportedDriver) || attempt >= connect.Retries {
+			break
+		}
+
+		logger.Warn("Database not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Int("retries", connect.Retries),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		time.Sleep(delay)
+		delay *= 2
+		if maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置连接池
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	applyPool(sqlDB, cfg)
+
+	logger.Info("Database connected successfully", zap.String("driver", cfg.Database.Driver))
+
+	return db, nil
+}
+
+var errUnsupportedDriver = errors.New("unsupported database driver")
+
+// openDB 连接数据库并检查连通性，每次调用都创建新的驱动实例
+func openDB(cfg *config.AppConfig, logger *util.Logger) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 
 	// 根据配置选择数据库驱动
 	switch cfg.Database.Driver {
 	case "mysql":
 		dialector = mysql.Open(cfg.Database.Source)
+	case "postgres":
+		dialector = postgres.Open(postgresDSN(cfg))
 	case "sqlite":
 		dialector = sqlite.Open(cfg.Database.Source)
 	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
+		return nil, fmt.Errorf("%w: %s", errUnsupportedDriver, cfg.Database.Driver)
 	}
 
 	// 配置GORM日志
 	gormLogger := util.NewGormLogger(logger)
 
-	// 打开数据库连接
+	// 打开数据库连接，gorm 会 Ping 数据库，连接失败时返回错误
 	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormLogger,
+		// 将各数据库的唯一索引冲突等错误统一转换为 gorm.ErrDuplicatedKey 等错误
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
+	return db, nil
+}
 
-	// 配置连接池
+// applyPool 按配置设置连接池参数
+func applyPool(sqlDB *sql.DB, cfg *config.AppConfig) {
+	pool := cfg.Database.Pool
+	sqlDB.SetMaxOpenConns(orDefault(pool.MaxOpenConns, defaultMaxOpenConns))
+	sqlDB.SetMaxIdleConns(orDefault(pool.MaxIdleConns, defaultMaxIdleConns))
+	sqlDB.SetConnMaxLifetime(orDefault(time.Duration(pool.ConnMaxLifetime)*time.Second, defaultConnMaxLifetime))
+	sqlDB.SetConnMaxIdleTime(time.Duration(pool.ConnMaxIdleTime) * time.Second)
+}
+
+// closeDB 关闭数据库连接池，包括只读副本
+func closeDB(db *gorm.DB) error {
+	var errs []error
+	if replicas := replicasOf(db); replicas != nil {
+		errs = append(errs, replicas.close())
+	}
 	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	if err == nil {
+		err = sqlDB.Close()
 	}
+	return errors.Join(append(errs, err)...)
+}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-
-	logger.Info("Database connected successfully", zap.String("driver", cfg.Database.Driver))
+// orDefault 配置值为零时返回默认值
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
 
-	// 自动迁移数据库表
-	if err = db.AutoMigrate(
-		&helloworld.HelloWorld{},
-		&user.User{},
-	); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+// postgresDSN 使用 URL 格式拼接 PostgreSQL 连接字符串，用户名、密码等参数中的特殊字符会被转义
+func postgresDSN(cfg *config.AppConfig) string {
+	pg := cfg.Database.Postgres
+	query := url.Values{}
+	if pg.SSLMode != "" {
+		query.Set("sslmode", pg.SSLMode)
+	}
+	if pg.SearchPath != "" {
+		query.Set("search_path", pg.SearchPath)
 	}
 
-	return db, nil
+	dsn := url.URL{
+		Scheme: "postgres",
+		Path:   "/" + pg.DBName,
+	}
+	if pg.Password != "" {
+		dsn.User = url.UserPassword(pg.User, pg.Password)
+	} else if pg.User != "" {
+		dsn.User = url.User(pg.User)
+	}
+	port := strconv.Itoa(pg.Port)
+	if strings.HasPrefix(pg.Host, "/") {
+		// Unix socket 目录不能放在 URL 的主机部分
+		query.Set("host", pg.Host)
+		query.Set("port", port)
+	} else {
+		dsn.Host = net.JoinHostPort(pg.Host, port)
+	}
+	dsn.RawQuery = query.Encode()
+	return dsn.String()
 }
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// emailVerificationRepo 邮箱验证令牌数据访问实现
+type emailVerificationRepo struct {
+	data *Data
+}
+
+// NewEmailVerificationRepo 创建EmailVerificationRepo实例
+// 注意：返回的是 service.EmailVerificationRepo 接口类型
+func NewEmailVerificationRepo(data *Data) service.EmailVerificationRepo {
+	return &emailVerificationRepo{
+		data: data,
+	}
+}
+
+// CreateVerificationToken 创建邮箱验证令牌记录
+func (r *emailVerificationRepo) CreateVerificationToken(ctx context.Context, t *user.EmailVerificationToken) error {
+	r.data.log.Debug("Creating email verification token", zap.Uint("user_id", t.UserID))
+	err := r.data.DB(ctx).Create(t).Error
+	if err != nil {
+		r.data.log.Error("Failed to create email verification token", zap.Error(err), zap.Uint("user_id", t.UserID))
+		return err
+	}
+	return nil
+}
+
+// ConsumeVerificationToken 将未使用且未过期的验证令牌标记为已使用
+// 通过 used_at IS NULL 条件保证同一令牌只能被成功使用一次
+func (r *emailVerificationRepo) ConsumeVerificationToken(ctx context.Context, tokenHash string) (*user.EmailVerificationToken, error) {
+	var t user.EmailVerificationToken
+	err := r.data.DB(ctx).Where("token_hash = ?", tokenHash).First(&t).Error
+	if err != nil {
+		r.data.log.Debug("Email verification token not found", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	result := r.data.DB(ctx).Model(&user.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", t.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		r.data.log.Error("Failed to consume email verification token", zap.Error(result.Error), zap.Uint("id", t.ID))
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	t.UsedAt = &now
+	return &t, nil
+}
+
+// DeleteUserVerificationTokens 删除用户的全部验证令牌
+func (r *emailVerificationRepo) DeleteUserVerificationTokens(ctx context.Context, userID uint) error {
+	err := r.data.DB(ctx).Where("user_id = ?", userID).Delete(&user.EmailVerificationToken{}).Error
+	if err != nil {
+		r.data.log.Error("Failed to delete email verification tokens", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	return nil
+}
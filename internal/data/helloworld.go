@@ -11,21 +11,21 @@ import (
 // helloworldRepo HelloWorld数据访问实现
 type helloworldRepo struct {
 	data *Data
+	hws  *Repository[helloworld.HelloWorld, uint]
 }
 
 // NewHelloWorldRepo 创建HelloWorldRepo实例
 func NewHelloWorldRepo(data *Data) service.HelloWorldRepo {
 	return &helloworldRepo{
 		data: data,
+		hws:  NewRepository[helloworld.HelloWorld, uint](data),
 	}
 }
 
 // CreateHelloWorld 创建HelloWorld记录
 func (r *helloworldRepo) CreateHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error {
 	r.data.log.Debug("Creating HelloWorld record", zap.String("message", hw.Message))
-	err := r.data.db.WithContext(ctx).Create(hw).Error
-	if err != nil {
-		r.data.log.Error("Failed to create HelloWorld record", zap.Error(err))
+	if err := r.hws.Create(ctx, hw); err != nil {
 		return err
 	}
 	r.data.log.Info("HelloWorld record created successfully", zap.Uint("id", hw.ID))
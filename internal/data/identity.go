@@ -0,0 +1,62 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// identityRepo 外部身份数据访问实现
+type identityRepo struct {
+	data *Data
+}
+
+// NewIdentityRepo 创建IdentityRepo实例
+func NewIdentityRepo(data *Data) service.IdentityRepo {
+	return &identityRepo{
+		data: data,
+	}
+}
+
+// GetIdentity 根据身份提供方和提供方的用户标识查询外部身份
+func (r *identityRepo) GetIdentity(ctx context.Context, provider, subject string) (*user.Identity, error) {
+	var identity user.Identity
+	err := r.data.DB(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		r.data.log.Debug("Identity not found", zap.String("provider", provider), zap.Error(err))
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateUserWithIdentity 在事务中创建用户并关联外部身份
+func (r *identityRepo) CreateUserWithIdentity(ctx context.Context, u *user.User, identity *user.Identity) error {
+	err := r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(u).Error; err != nil {
+			return err
+		}
+		identity.UserID = u.ID
+		return tx.Create(identity).Error
+	})
+	if err != nil {
+		r.data.log.Error("Failed to create user with identity", zap.Error(err),
+			zap.String("username", u.Username), zap.String("provider", identity.Provider))
+		return err
+	}
+	r.data.log.Info("User created from external identity", zap.String("username", u.Username),
+		zap.Uint("id", u.ID), zap.String("provider", identity.Provider))
+	return nil
+}
+
+// UpdateIdentityLogin 记录外部身份的最近登录时间和邮箱
+func (r *identityRepo) UpdateIdentityLogin(ctx context.Context, id uint, email string, at time.Time) error {
+	return r.data.DB(ctx).Model(&user.Identity{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_login_at": at}).Error
+}
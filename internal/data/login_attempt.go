@@ -0,0 +1,148 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultLoginAttemptGCInterval 未配置统计窗口时失败计数的清理间隔
+const defaultLoginAttemptGCInterval = 10 * time.Minute
+
+// NewLoginAttemptStore 根据配置创建登录失败计数存储，并在后台定期清理过期记录
+func NewLoginAttemptStore(cfg *config.AppConfig, data *Data) (service.LoginAttemptStore, func(), error) {
+	var store service.LoginAttemptStore
+	switch cfg.Auth.Login.Store {
+	case "memory", "":
+		store = newMemoryLoginAttemptStore()
+	case "database":
+		store = &gormLoginAttemptStore{data: data}
+	default:
+		return nil, nil, fmt.Errorf("unsupported login attempt store: %s", cfg.Auth.Login.Store)
+	}
+
+	interval := time.Duration(cfg.Auth.Login.Window) * time.Second
+	if interval <= 0 {
+		interval = defaultLoginAttemptGCInterval
+	}
+
+	cleanup := startExpiryGC(data, "login attempts", interval, store.DeleteExpired)
+	return store, cleanup, nil
+}
+
+// memoryLoginAttemptStore 基于内存的失败计数，仅适用于单实例部署
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*user.LoginAttempt
+}
+
+func newMemoryLoginAttemptStore() *memoryLoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]*user.LoginAttempt)}
+}
+
+func (s *memoryLoginAttemptStore) Get(_ context.Context, key string) (*user.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok || !time.Now().Before(attempt.ExpiresAt) {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *memoryLoginAttemptStore) RecordFailure(_ context.Context, key string, now time.Time, ttl time.Duration) (*user.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok || !now.Before(attempt.ExpiresAt) {
+		attempt = &user.LoginAttempt{Key: key}
+		s.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = now
+	attempt.ExpiresAt = now.Add(ttl)
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for key, attempt := range s.attempts {
+		if !now.Before(attempt.ExpiresAt) {
+			delete(s.attempts, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// gormLoginAttemptStore 基于数据库的失败计数，支持多实例部署。
+// 计数从主库读取，避免副本复制延迟期间绕过锁定
+type gormLoginAttemptStore struct {
+	data *Data
+}
+
+func (s *gormLoginAttemptStore) Get(ctx context.Context, key string) (*user.LoginAttempt, error) {
+	var attempt user.LoginAttempt
+	err := s.data.DB(dbctx.WithPrimary(ctx)).Where("attempt_key = ? AND expires_at > ?", key, time.Now()).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *gormLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, ttl time.Duration) (*user.LoginAttempt, error) {
+	// 原子地累加计数，计数已过期时从 1 重新开始；failures 必须排在 expires_at 之前更新（MySQL 按顺序赋值）
+	// 列名需带表名，PostgreSQL 中未限定的列名与 excluded 中的列有歧义
+	attempt := &user.LoginAttempt{Key: key, Failures: 1, LastFailedAt: now, ExpiresAt: now.Add(ttl)}
+	err := s.data.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("CASE WHEN login_attempts.expires_at <= ? THEN 1 ELSE login_attempts.failures + 1 END", now)},
+			{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+			{Column: clause.Column{Name: "expires_at"}, Value: now.Add(ttl)},
+		},
+	}).Create(attempt).Error
+	if err != nil {
+		s.data.log.Error("Failed to record login failure", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	if err := s.data.DB(dbctx.WithPrimary(ctx)).Where("attempt_key = ?", key).First(attempt).Error; err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func (s *gormLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.data.DB(ctx).Where("attempt_key = ?", key).Delete(&user.LoginAttempt{}).Error
+}
+
+func (s *gormLoginAttemptStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.data.DB(ctx).Where("expires_at <= ?", time.Now()).Delete(&user.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
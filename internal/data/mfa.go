@@ -0,0 +1,109 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// mfaRepo 两步验证数据访问实现
+type mfaRepo struct {
+	data *Data
+}
+
+// NewMFARepo 创建MFARepo实例
+func NewMFARepo(data *Data) service.MFARepo {
+	return &mfaRepo{
+		data: data,
+	}
+}
+
+// SetTOTPSecret 保存待确认的 TOTP 共享密钥
+func (r *mfaRepo) SetTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	err := r.data.DB(ctx).Model(&user.User{ID: userID}).
+		Update("totp_secret", secret).Error
+	if err != nil {
+		r.data.log.Error("Failed to set totp secret", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	return nil
+}
+
+// EnableTOTP 开启两步验证并替换恢复码
+func (r *mfaRepo) EnableTOTP(ctx context.Context, userID uint, step int64, codeHashes []string) error {
+	err := r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{ID: userID}).Updates(map[string]any{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&user.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]user.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, user.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		r.data.log.Error("Failed to enable totp", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	r.data.log.Info("Two-factor authentication enabled", zap.Uint("user_id", userID))
+	return nil
+}
+
+// DisableTOTP 关闭两步验证并删除恢复码
+func (r *mfaRepo) DisableTOTP(ctx context.Context, userID uint) error {
+	err := r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{ID: userID}).Updates(map[string]any{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&user.RecoveryCode{}).Error
+	})
+	if err != nil {
+		r.data.log.Error("Failed to disable totp", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	r.data.log.Info("Two-factor authentication disabled", zap.Uint("user_id", userID))
+	return nil
+}
+
+// AdvanceTOTPStep 记录已使用的验证码时间步
+// 通过 totp_last_step < step 条件保证同一验证码只能被成功使用一次
+func (r *mfaRepo) AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := r.data.DB(ctx).Model(&user.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		r.data.log.Error("Failed to advance totp step", zap.Error(result.Error), zap.Uint("user_id", userID))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseRecoveryCode 将恢复码标记为已使用
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.data.DB(ctx).Model(&user.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to use recovery code", zap.Error(result.Error), zap.Uint("user_id", userID))
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		r.data.log.Warn("Recovery code used", zap.Uint("user_id", userID))
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/helloworld"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/migrate"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationFS SQL 迁移文件，按数据库驱动分目录保存
+//
+//go:embed migrations
+var migrationFS embed.FS
+
+// goMigrations 使用 Go 代码实现的迁移（如数据迁移），版本号与 SQL 迁移统一编排
+var goMigrations []*migrate.Migration
+
+// baselineVersion 基线迁移的版本
+const baselineVersion = 1
+
+// NewMigrator 创建数据库迁移器
+func NewMigrator(db *gorm.DB, cfg *config.AppConfig) (*migrate.Migrator, error) {
+	migrations, err := migrate.Load(migrationFS, path.Join("migrations", db.Dialector.Name()))
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, append(migrations, goMigrations...), &migrate.Config{
+		LockTimeout: time.Duration(cfg.Database.Migration.LockTimeout) * time.Second,
+		Adopt:       adoptLegacySchema,
+	})
+}
+
+// CreateMigration 在 dir 下每个数据库驱动的目录中创建下一个版本的迁移文件，返回创建的文件路径
+func CreateMigration(dir, name string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	// 版本号在所有驱动和 Go 迁移之间统一递增
+	var (
+		drivers []string
+		latest  uint64
+	)
+	for _, mig := range goMigrations {
+		latest = max(latest, mig.Version)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		migrations, err := migrate.Load(os.DirFS(dir), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, mig := range migrations {
+			latest = max(latest, mig.Version)
+		}
+		drivers = append(drivers, entry.Name())
+	}
+	if len(drivers) == 0 {
+		return nil, fmt.Errorf("no driver directories found in %s", dir)
+	}
+
+	var files []string
+	for _, driver := range drivers {
+		created, err := migrate.Create(filepath.Join(dir, driver), latest+1, name)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, created...)
+	}
+	return files, nil
+}
+
+// migrateDB 执行未执行的迁移，关闭自动迁移时只检查表结构是否为最新版本
+func migrateDB(db *gorm.DB, cfg *config.AppConfig, logger *util.Logger) error {
+	migrator, err := NewMigrator(db, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if cfg.Database.Migration.DisableAuto {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("database schema is out of date: %d pending migrations", len(pending))
+		}
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx, 0)
+	for _, mig := range applied {
+		logger.Info("Applied database migration", zap.Uint64("version", mig.Version), zap.String("name", mig.Name))
+	}
+	return err
+}
+
+// adoptLegacySchema 接管启用版本化迁移之前由 AutoMigrate 创建的数据库，
+// 补齐旧版本缺少的列和索引后将基线迁移标记为已执行
+func adoptLegacySchema(db *gorm.DB) (uint64, error) {
+	if !db.Migrator().HasTable(&user.User{}) {
+		return 0, nil
+	}
+
+	// 旧版本的用户名和邮箱唯一索引不包含 deleted_id，迁移后需要重建
+	rebuildUserIndexes := !db.Migrator().HasColumn(&user.User{}, "deleted_id")
+
+	if err := db.AutoMigrate(
+		&helloworld.HelloWorld{},
+		&user.User{},
+		&user.RefreshToken{},
+		&user.RevokedToken{},
+		&user.Role{},
+		&user.Permission{},
+		&user.APIKey{},
+		&user.RecoveryCode{},
+		&user.LoginAttempt{},
+		&user.PasswordResetToken{},
+		&user.EmailVerificationToken{},
+		&user.Session{},
+		&user.OAuthClient{},
+		&user.OAuthAuthorizationCode{},
+		&user.Identity{},
+	); err != nil {
+		return 0, err
+	}
+	if rebuildUserIndexes {
+		for _, name := range []string{"idx_users_username", "idx_users_email"} {
+			if err := rebuildIndex(db, &user.User{}, name); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return baselineVersion, nil
+}
+
+// rebuildIndex 按模型中的定义重建索引
+func rebuildIndex(db *gorm.DB, model any, name string) error {
+	if db.Migrator().HasIndex(model, name) {
+		if err := db.Migrator().DropIndex(model, name); err != nil {
+			return err
+		}
+	}
+	return db.Migrator().CreateIndex(model, name)
+}
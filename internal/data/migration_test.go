@@ -1,12 +1,16 @@
 package data
 
 import (
+	"context"
 	"os"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/HoronLee/GinHub/internal/config"
 	"github.com/HoronLee/GinHub/internal/model/user"
 	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/migrate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,7 +24,7 @@ func TestUserModelMigration(t *testing.T) {
 	// 创建测试日志器
 	logger := util.NewLogger(cfg)
 
-	// 初始化数据库（包含 AutoMigrate）
+	// 初始化数据库（包含数据库迁移）
 	db, err := NewDB(cfg, logger)
 	assert.NoError(t, err, "Database initialization should succeed")
 	assert.NotNil(t, db, "Database instance should not be nil")
@@ -45,7 +49,7 @@ func TestUserModelMigration(t *testing.T) {
 	hasColumn = db.Migrator().HasColumn(&user.User{}, "updated_at")
 	assert.True(t, hasColumn, "users table should have updated_at column")
 
-	// 验证索引是否存在（GORM 自动创建的索引名为 idx_users_username）
+	// 验证索引是否存在（基线迁移中的索引名与 GORM 生成的一致）
 	hasIndex := db.Migrator().HasIndex(&user.User{}, "idx_users_username")
 	assert.True(t, hasIndex, "users table should have unique index on username")
 
@@ -125,3 +129,128 @@ func TestDatabaseMigrationWithExistingData(t *testing.T) {
 	sqlDB2, _ := db2.DB()
 	sqlDB2.Close()
 }
+
+func TestUserSoftDeleteMigration(t *testing.T) {
+	tempFile := t.TempDir() + "/test_soft_delete.db"
+
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = tempFile
+	cfg.Server.Mode = "debug"
+
+	logger := util.NewLogger(cfg)
+
+	// 模拟旧版本的表结构：用户名和邮箱上是单列唯一索引，且没有迁移记录
+	db1, err := NewDB(cfg, logger)
+	assert.NoError(t, err)
+	assert.NoError(t, db1.Exec("DROP TABLE schema_migrations").Error)
+	assert.NoError(t, db1.Exec("DROP INDEX idx_users_username").Error)
+	assert.NoError(t, db1.Exec("DROP INDEX idx_users_email").Error)
+	assert.NoError(t, db1.Exec("ALTER TABLE users DROP COLUMN deleted_id").Error)
+	assert.NoError(t, db1.Exec("CREATE UNIQUE INDEX idx_users_username ON users(username)").Error)
+	assert.NoError(t, db1.Exec("CREATE UNIQUE INDEX idx_users_email ON users(email)").Error)
+	assert.NoError(t, db1.Exec("INSERT INTO users (username, password, created_at, updated_at) VALUES ('legacy', 'x', ?, ?)", time.Now(), time.Now()).Error)
+	sqlDB1, _ := db1.DB()
+	sqlDB1.Close()
+
+	// 重新初始化后唯一索引包含 deleted_id
+	db2, err := NewDB(cfg, logger)
+	assert.NoError(t, err)
+	defer func() {
+		sqlDB2, _ := db2.DB()
+		sqlDB2.Close()
+	}()
+
+	var legacy user.User
+	assert.NoError(t, db2.Where("username = ?", "legacy").First(&legacy).Error)
+	assert.NoError(t, db2.Model(&legacy).Updates(map[string]any{"deleted_at": time.Now(), "deleted_id": legacy.ID}).Error)
+
+	// 已删除用户的用户名可以重新注册，但未删除的用户名仍然唯一
+	assert.NoError(t, db2.Create(&user.User{Username: "legacy", Password: "y"}).Error)
+	assert.Error(t, db2.Create(&user.User{Username: "legacy", Password: "z"}).Error)
+
+	var count int64
+	assert.NoError(t, db2.Model(&user.User{}).Where("username = ?", "legacy").Count(&count).Error)
+	assert.EqualValues(t, 1, count, "soft-deleted users should be excluded from queries")
+}
+
+func TestVersionedMigrations(t *testing.T) {
+	tempFile := t.TempDir() + "/test_versioned.db"
+
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = tempFile
+	cfg.Server.Mode = "debug"
+
+	logger := util.NewLogger(cfg)
+
+	db1, err := NewDB(cfg, logger)
+	assert.NoError(t, err)
+
+	migrator, err := NewMigrator(db1, cfg)
+	assert.NoError(t, err)
+	statuses, err := migrator.Status(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, statuses)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt, "migration %d should be applied", s.Version)
+		assert.False(t, s.ChecksumMismatch)
+	}
+
+	// 重启时没有需要执行的迁移
+	applied, err := migrator.Up(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// 回滚后关闭自动迁移启动失败，重新执行迁移后恢复
+	_, err = migrator.Down(context.Background(), len(statuses))
+	assert.NoError(t, err)
+	assert.False(t, db1.Migrator().HasTable(&user.User{}))
+	sqlDB1, _ := db1.DB()
+	sqlDB1.Close()
+
+	cfg.Database.Migration.DisableAuto = true
+	_, err = NewDB(cfg, logger)
+	assert.ErrorContains(t, err, "pending migrations")
+
+	cfg.Database.Migration.DisableAuto = false
+	db2, err := NewDB(cfg, logger)
+	assert.NoError(t, err)
+	assert.True(t, db2.Migrator().HasTable(&user.User{}))
+	sqlDB2, _ := db2.DB()
+	sqlDB2.Close()
+}
+
+func TestMigrationsMatchAcrossDrivers(t *testing.T) {
+	var versions [][]string
+	for _, driver := range []string{"sqlite", "mysql", "postgres"} {
+		migrations, err := migrate.Load(migrationFS, path.Join("migrations", driver))
+		assert.NoError(t, err)
+
+		var names []string
+		for _, mig := range migrations {
+			names = append(names, mig.String())
+		}
+		versions = append(versions, names)
+	}
+	for _, v := range versions[1:] {
+		assert.Equal(t, versions[0], v, "each driver should have the same migrations")
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(dir+"/sqlite", 0o755))
+	assert.NoError(t, os.MkdirAll(dir+"/mysql", 0o755))
+	assert.NoError(t, os.WriteFile(dir+"/sqlite/000003_existing.up.sql", []byte("SELECT 1;\n"), 0o644))
+
+	// 版本号取所有驱动中最大的版本号加一
+	files, err := CreateMigration(dir, "add_notes")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		dir + "/mysql/000004_add_notes.up.sql",
+		dir + "/mysql/000004_add_notes.down.sql",
+		dir + "/sqlite/000004_add_notes.up.sql",
+		dir + "/sqlite/000004_add_notes.down.sql",
+	}, files)
+}
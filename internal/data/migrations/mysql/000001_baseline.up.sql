@@ -0,0 +1,224 @@
+-- 基线表结构，与版本化迁移之前 AutoMigrate 创建的表结构一致
+
+CREATE TABLE `hello_worlds` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `message` text NOT NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `users` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `username` varchar(50) NOT NULL,
+    `password` varchar(255) NOT NULL,
+    `email` varchar(255),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `deleted_id` bigint unsigned NOT NULL DEFAULT 0,
+    `email_verified_at` datetime(3) NULL,
+    `display_name` varchar(64),
+    `avatar_url` varchar(512),
+    `locale` varchar(35),
+    `timezone` varchar(64),
+    `bio` varchar(500),
+    `totp_secret` varchar(64),
+    `totp_enabled` boolean NOT NULL DEFAULT false,
+    `totp_last_step` bigint NOT NULL DEFAULT 0,
+    `failed_login_count` bigint NOT NULL DEFAULT 0,
+    `locked_until` datetime(3) NULL,
+    `status` varchar(16) NOT NULL DEFAULT 'active',
+    `password_reset_required` boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_users_username` (`username`,`deleted_id`),
+    UNIQUE INDEX `idx_users_email` (`email`,`deleted_id`),
+    INDEX `idx_users_deleted_at` (`deleted_at`),
+    INDEX `idx_users_status` (`status`)
+);
+
+CREATE TABLE `roles` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(50) NOT NULL,
+    `description` varchar(255),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_roles_name` (`name`)
+);
+
+CREATE TABLE `user_roles` (
+    `user_id` bigint unsigned,
+    `role_id` bigint unsigned,
+    PRIMARY KEY (`user_id`,`role_id`),
+    CONSTRAINT `fk_user_roles_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+);
+
+CREATE TABLE `refresh_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `family_id` varchar(64) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_refresh_tokens_user_id` (`user_id`),
+    INDEX `idx_refresh_tokens_family_id` (`family_id`),
+    UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`)
+);
+
+CREATE TABLE `revoked_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `jti` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_revoked_tokens_jti` (`jti`),
+    INDEX `idx_revoked_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `permissions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(100) NOT NULL,
+    `description` varchar(255),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_permissions_name` (`name`)
+);
+
+CREATE TABLE `role_permissions` (
+    `role_id` bigint unsigned,
+    `permission_id` bigint unsigned,
+    PRIMARY KEY (`role_id`,`permission_id`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`)
+);
+
+CREATE TABLE `api_keys` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `key_hash` varchar(64) NOT NULL,
+    `scopes` longtext,
+    `expires_at` datetime(3) NULL,
+    `last_used_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_api_keys_user_id` (`user_id`),
+    UNIQUE INDEX `idx_api_keys_prefix` (`prefix`),
+    UNIQUE INDEX `idx_api_keys_key_hash` (`key_hash`)
+);
+
+CREATE TABLE `recovery_codes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_recovery_codes_user_id` (`user_id`),
+    UNIQUE INDEX `idx_recovery_codes_code_hash` (`code_hash`)
+);
+
+CREATE TABLE `login_attempts` (
+    `attempt_key` varchar(191),
+    `failures` bigint NOT NULL DEFAULT 0,
+    `last_failed_at` datetime(3) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`attempt_key`),
+    INDEX `idx_login_attempts_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `password_reset_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_password_reset_tokens_user_id` (`user_id`),
+    UNIQUE INDEX `idx_password_reset_tokens_token_hash` (`token_hash`),
+    INDEX `idx_password_reset_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `email_verification_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `email` varchar(255) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_email_verification_tokens_user_id` (`user_id`),
+    UNIQUE INDEX `idx_email_verification_tokens_token_hash` (`token_hash`),
+    INDEX `idx_email_verification_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `sessions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `family_id` varchar(64) NOT NULL,
+    `user_agent` varchar(512),
+    `ip` varchar(64),
+    `created_at` datetime(3) NULL,
+    `last_seen_at` datetime(3) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `revoked_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_sessions_user_id` (`user_id`),
+    UNIQUE INDEX `idx_sessions_family_id` (`family_id`),
+    INDEX `idx_sessions_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `o_auth_clients` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `client_id` varchar(64) NOT NULL,
+    `secret_hash` varchar(64),
+    `name` varchar(100) NOT NULL,
+    `redirect_uris` longtext,
+    `grant_types` longtext,
+    `scopes` longtext,
+    `created_by` bigint unsigned,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_o_auth_clients_client_id` (`client_id`)
+);
+
+CREATE TABLE `o_auth_authorization_codes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `code_hash` varchar(64) NOT NULL,
+    `client_id` varchar(64) NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `redirect_uri` varchar(2048) NOT NULL,
+    `scope` varchar(1024),
+    `nonce` varchar(255),
+    `code_challenge` varchar(128) NOT NULL,
+    `token_id` varchar(64),
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_o_auth_authorization_codes_code_hash` (`code_hash`),
+    INDEX `idx_o_auth_authorization_codes_client_id` (`client_id`),
+    INDEX `idx_o_auth_authorization_codes_user_id` (`user_id`)
+);
+
+CREATE TABLE `identities` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `provider` varchar(50) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255),
+    `created_at` datetime(3) NULL,
+    `last_login_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_identities_user_id` (`user_id`),
+    UNIQUE INDEX `idx_identities_provider_subject` (`provider`,`subject`)
+);
@@ -0,0 +1,210 @@
+-- 基线表结构，与版本化迁移之前 AutoMigrate 创建的表结构一致
+
+CREATE TABLE `hello_worlds` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `message` text NOT NULL,
+    `created_at` datetime
+);
+
+CREATE TABLE `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `username` varchar(50) NOT NULL,
+    `password` varchar(255) NOT NULL,
+    `email` varchar(255),
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `deleted_id` integer NOT NULL DEFAULT 0,
+    `email_verified_at` datetime,
+    `display_name` varchar(64),
+    `avatar_url` varchar(512),
+    `locale` varchar(35),
+    `timezone` varchar(64),
+    `bio` varchar(500),
+    `totp_secret` varchar(64),
+    `totp_enabled` numeric NOT NULL DEFAULT false,
+    `totp_last_step` integer NOT NULL DEFAULT 0,
+    `failed_login_count` integer NOT NULL DEFAULT 0,
+    `locked_until` datetime,
+    `status` varchar(16) NOT NULL DEFAULT 'active',
+    `password_reset_required` numeric NOT NULL DEFAULT false
+);
+CREATE INDEX `idx_users_status` ON `users`(`status`);
+CREATE INDEX `idx_users_deleted_at` ON `users`(`deleted_at`);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`,`deleted_id`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`,`deleted_id`);
+
+CREATE TABLE `roles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` varchar(50) NOT NULL,
+    `description` varchar(255),
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_roles_name` ON `roles`(`name`);
+
+CREATE TABLE `user_roles` (
+    `user_id` integer,
+    `role_id` integer,
+    PRIMARY KEY (`user_id`,`role_id`),
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+    CONSTRAINT `fk_user_roles_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE `refresh_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `family_id` varchar(64) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `revoked_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_refresh_tokens_token_hash` ON `refresh_tokens`(`token_hash`);
+CREATE INDEX `idx_refresh_tokens_family_id` ON `refresh_tokens`(`family_id`);
+CREATE INDEX `idx_refresh_tokens_user_id` ON `refresh_tokens`(`user_id`);
+
+CREATE TABLE `revoked_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `jti` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime
+);
+CREATE INDEX `idx_revoked_tokens_expires_at` ON `revoked_tokens`(`expires_at`);
+CREATE UNIQUE INDEX `idx_revoked_tokens_jti` ON `revoked_tokens`(`jti`);
+
+CREATE TABLE `permissions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` varchar(100) NOT NULL,
+    `description` varchar(255),
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_permissions_name` ON `permissions`(`name`);
+
+CREATE TABLE `role_permissions` (
+    `role_id` integer,
+    `permission_id` integer,
+    PRIMARY KEY (`role_id`,`permission_id`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`)
+);
+
+CREATE TABLE `api_keys` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `key_hash` varchar(64) NOT NULL,
+    `scopes` text,
+    `expires_at` datetime,
+    `last_used_at` datetime,
+    `revoked_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_api_keys_key_hash` ON `api_keys`(`key_hash`);
+CREATE UNIQUE INDEX `idx_api_keys_prefix` ON `api_keys`(`prefix`);
+CREATE INDEX `idx_api_keys_user_id` ON `api_keys`(`user_id`);
+
+CREATE TABLE `recovery_codes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_recovery_codes_code_hash` ON `recovery_codes`(`code_hash`);
+CREATE INDEX `idx_recovery_codes_user_id` ON `recovery_codes`(`user_id`);
+
+CREATE TABLE `login_attempts` (
+    `attempt_key` varchar(191),
+    `failures` integer NOT NULL DEFAULT 0,
+    `last_failed_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    PRIMARY KEY (`attempt_key`)
+);
+CREATE INDEX `idx_login_attempts_expires_at` ON `login_attempts`(`expires_at`);
+
+CREATE TABLE `password_reset_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE INDEX `idx_password_reset_tokens_expires_at` ON `password_reset_tokens`(`expires_at`);
+CREATE UNIQUE INDEX `idx_password_reset_tokens_token_hash` ON `password_reset_tokens`(`token_hash`);
+CREATE INDEX `idx_password_reset_tokens_user_id` ON `password_reset_tokens`(`user_id`);
+
+CREATE TABLE `email_verification_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `email` varchar(255) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE INDEX `idx_email_verification_tokens_expires_at` ON `email_verification_tokens`(`expires_at`);
+CREATE UNIQUE INDEX `idx_email_verification_tokens_token_hash` ON `email_verification_tokens`(`token_hash`);
+CREATE INDEX `idx_email_verification_tokens_user_id` ON `email_verification_tokens`(`user_id`);
+
+CREATE TABLE `sessions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `family_id` varchar(64) NOT NULL,
+    `user_agent` varchar(512),
+    `ip` varchar(64),
+    `created_at` datetime,
+    `last_seen_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `revoked_at` datetime
+);
+CREATE INDEX `idx_sessions_expires_at` ON `sessions`(`expires_at`);
+CREATE UNIQUE INDEX `idx_sessions_family_id` ON `sessions`(`family_id`);
+CREATE INDEX `idx_sessions_user_id` ON `sessions`(`user_id`);
+
+CREATE TABLE `o_auth_clients` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `client_id` varchar(64) NOT NULL,
+    `secret_hash` varchar(64),
+    `name` varchar(100) NOT NULL,
+    `redirect_uris` text,
+    `grant_types` text,
+    `scopes` text,
+    `created_by` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_o_auth_clients_client_id` ON `o_auth_clients`(`client_id`);
+
+CREATE TABLE `o_auth_authorization_codes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `code_hash` varchar(64) NOT NULL,
+    `client_id` varchar(64) NOT NULL,
+    `user_id` integer NOT NULL,
+    `redirect_uri` varchar(2048) NOT NULL,
+    `scope` varchar(1024),
+    `nonce` varchar(255),
+    `code_challenge` varchar(128) NOT NULL,
+    `token_id` varchar(64),
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE INDEX `idx_o_auth_authorization_codes_user_id` ON `o_auth_authorization_codes`(`user_id`);
+CREATE INDEX `idx_o_auth_authorization_codes_client_id` ON `o_auth_authorization_codes`(`client_id`);
+CREATE UNIQUE INDEX `idx_o_auth_authorization_codes_code_hash` ON `o_auth_authorization_codes`(`code_hash`);
+
+CREATE TABLE `identities` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `provider` varchar(50) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255),
+    `created_at` datetime,
+    `last_login_at` datetime
+);
+CREATE UNIQUE INDEX `idx_identities_provider_subject` ON `identities`(`provider`,`subject`);
+CREATE INDEX `idx_identities_user_id` ON `identities`(`user_id`);
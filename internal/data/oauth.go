@@ -0,0 +1,112 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// oauthRepo OAuth2 客户端和授权码数据访问实现
+type oauthRepo struct {
+	data *Data
+}
+
+// NewOAuthRepo 创建OAuthRepo实例
+func NewOAuthRepo(data *Data) service.OAuthRepo {
+	return &oauthRepo{
+		data: data,
+	}
+}
+
+// CreateClient 创建 OAuth2 客户端
+func (r *oauthRepo) CreateClient(ctx context.Context, c *user.OAuthClient) error {
+	err := r.data.DB(ctx).Create(c).Error
+	if err != nil {
+		r.data.log.Error("Failed to create oauth client", zap.Error(err), zap.String("name", c.Name))
+		return err
+	}
+	r.data.log.Info("OAuth client created", zap.String("client_id", c.ClientID), zap.String("name", c.Name))
+	return nil
+}
+
+// GetClientByClientID 根据客户端标识查询 OAuth2 客户端
+func (r *oauthRepo) GetClientByClientID(ctx context.Context, clientID string) (*user.OAuthClient, error) {
+	var c user.OAuthClient
+	err := r.data.DB(ctx).Where("client_id = ?", clientID).First(&c).Error
+	if err != nil {
+		r.data.log.Debug("OAuth client not found", zap.String("client_id", clientID), zap.Error(err))
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListClients 查询全部 OAuth2 客户端
+func (r *oauthRepo) ListClients(ctx context.Context) ([]user.OAuthClient, error) {
+	var clients []user.OAuthClient
+	if err := r.data.DB(ctx).Order("id").Find(&clients).Error; err != nil {
+		r.data.log.Error("Failed to list oauth clients", zap.Error(err))
+		return nil, err
+	}
+	return clients, nil
+}
+
+// DeleteClient 删除 OAuth2 客户端及其未使用的授权码
+func (r *oauthRepo) DeleteClient(ctx context.Context, id uint) error {
+	var c user.OAuthClient
+	err := r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&c, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", c.ClientID).Delete(&user.OAuthAuthorizationCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&c).Error
+	})
+	if err != nil {
+		r.data.log.Debug("Failed to delete oauth client", zap.Uint("id", id), zap.Error(err))
+		return err
+	}
+	r.data.log.Info("OAuth client deleted", zap.String("client_id", c.ClientID))
+	return nil
+}
+
+// CreateAuthorizationCode 创建授权码
+func (r *oauthRepo) CreateAuthorizationCode(ctx context.Context, code *user.OAuthAuthorizationCode) error {
+	err := r.data.DB(ctx).Create(code).Error
+	if err != nil {
+		r.data.log.Error("Failed to create authorization code", zap.Error(err), zap.String("client_id", code.ClientID))
+		return err
+	}
+	return nil
+}
+
+// ConsumeAuthorizationCode 将授权码标记为已使用，授权码已被使用过时返回 false
+func (r *oauthRepo) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*user.OAuthAuthorizationCode, bool, error) {
+	var code user.OAuthAuthorizationCode
+	// 授权码签发后立即被兑换，从主库读取以免副本复制延迟
+	db := r.data.DB(dbctx.WithPrimary(ctx))
+	if err := db.Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+		return nil, false, err
+	}
+
+	result := db.Model(&user.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to consume authorization code", zap.Error(result.Error), zap.Uint("id", code.ID))
+		return nil, false, result.Error
+	}
+	return &code, result.RowsAffected == 1, nil
+}
+
+// SetAuthorizationCodeToken 记录用授权码签发的访问令牌
+func (r *oauthRepo) SetAuthorizationCodeToken(ctx context.Context, id uint, tokenID string) error {
+	return r.data.DB(ctx).Model(&user.OAuthAuthorizationCode{}).
+		Where("id = ?", id).
+		Update("token_id", tokenID).Error
+}
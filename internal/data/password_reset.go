@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// passwordResetRepo 密码重置令牌数据访问实现
+type passwordResetRepo struct {
+	data *Data
+}
+
+// NewPasswordResetRepo 创建PasswordResetRepo实例
+// 注意：返回的是 service.PasswordResetRepo 接口类型
+func NewPasswordResetRepo(data *Data) service.PasswordResetRepo {
+	return &passwordResetRepo{
+		data: data,
+	}
+}
+
+// CreateResetToken 创建密码重置令牌记录
+func (r *passwordResetRepo) CreateResetToken(ctx context.Context, t *user.PasswordResetToken) error {
+	r.data.log.Debug("Creating password reset token", zap.Uint("user_id", t.UserID))
+	err := r.data.DB(ctx).Create(t).Error
+	if err != nil {
+		r.data.log.Error("Failed to create password reset token", zap.Error(err), zap.Uint("user_id", t.UserID))
+		return err
+	}
+	return nil
+}
+
+// ConsumeResetToken 将未使用且未过期的重置令牌标记为已使用
+// 通过 used_at IS NULL 条件保证同一令牌只能被成功使用一次
+func (r *passwordResetRepo) ConsumeResetToken(ctx context.Context, tokenHash string) (*user.PasswordResetToken, error) {
+	var t user.PasswordResetToken
+	err := r.data.DB(ctx).Where("token_hash = ?", tokenHash).First(&t).Error
+	if err != nil {
+		r.data.log.Debug("Password reset token not found", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	result := r.data.DB(ctx).Model(&user.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", t.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		r.data.log.Error("Failed to consume password reset token", zap.Error(result.Error), zap.Uint("id", t.ID))
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	t.UsedAt = &now
+	return &t, nil
+}
+
+// DeleteUserResetTokens 删除用户的全部重置令牌
+func (r *passwordResetRepo) DeleteUserResetTokens(ctx context.Context, userID uint) error {
+	err := r.data.DB(ctx).Where("user_id = ?", userID).Delete(&user.PasswordResetToken{}).Error
+	if err != nil {
+		r.data.log.Error("Failed to delete password reset tokens", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	return nil
+}
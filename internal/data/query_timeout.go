@@ -0,0 +1,66 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryTimeoutKey 保存单条 SQL 超时状态的 gorm 实例变量名
+const queryTimeoutKey = "ginhub:query_timeout"
+
+// queryTimeoutState 执行前的 Context 和超时 Context 的取消函数
+type queryTimeoutState struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout 注册 gorm 回调，为每条 SQL 的 Context 加上执行超时，timeout 为 0 时不注册
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	before := func(tx *gorm.DB) {
+		parent := tx.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(queryTimeoutKey, &queryTimeoutState{parent: parent, cancel: cancel})
+	}
+	after := func(cancel bool) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(queryTimeoutKey)
+			if !ok {
+				return
+			}
+			state := v.(*queryTimeoutState)
+			if cancel {
+				state.cancel()
+			}
+			// 链式调用可能复用同一个 Statement 执行下一条 SQL，恢复原来的 Context
+			tx.Statement.Context = state.parent
+		}
+	}
+
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Create().After("*").Register("ginhub:query_timeout_after", after(true)),
+		callback.Query().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Query().After("*").Register("ginhub:query_timeout_after", after(true)),
+		callback.Update().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Update().After("*").Register("ginhub:query_timeout_after", after(true)),
+		callback.Delete().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Delete().After("*").Register("ginhub:query_timeout_after", after(true)),
+		callback.Raw().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Raw().After("*").Register("ginhub:query_timeout_after", after(true)),
+		// Row、Rows 和 Scan 在回调结束后才读取结果集，不能提前取消，超时后由 Context 自行释放
+		callback.Row().Before("*").Register("ginhub:query_timeout_before", before),
+		callback.Row().After("*").Register("ginhub:query_timeout_after", after(false)),
+	)
+}
@@ -0,0 +1,267 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	// replicaPolicyRoundRobin 依次轮询健康的副本
+	replicaPolicyRoundRobin = "round_robin"
+	// replicaPolicyLeastLatency 选择最近一次健康检查延迟最低的副本
+	replicaPolicyLeastLatency = "least_latency"
+
+	defaultReplicaCheckInterval = 10 * time.Second
+)
+
+// replica 只读副本连接及其最近一次健康检查结果
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+	latency atomic.Int64
+}
+
+// replicaSet 只读副本集合，作为 gorm 插件注册到主库连接上。
+// 读取路由到健康的副本，写入、事务和加锁读取在主库执行，没有健康的副本时读取也回到主库
+type replicaSet struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	policy   string
+	interval time.Duration
+	next     atomic.Uint64
+	log      *util.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Name 实现 gorm.Plugin 接口
+func (s *replicaSet) Name() string {
+	return "ginhub:replicas"
+}
+
+// Initialize 实现 gorm.Plugin 接口，注册读写路由回调
+func (s *replicaSet) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Query().Before("*").Register("ginhub:replica_route", s.route),
+		callback.Row().Before("*").Register("ginhub:replica_route", s.route),
+		callback.Create().Before("*").Register("ginhub:replica_primary", s.usePrimary),
+		callback.Create().After("*").Register("ginhub:replica_written", markWritten),
+		callback.Update().Before("*").Register("ginhub:replica_primary", s.usePrimary),
+		callback.Update().After("*").Register("ginhub:replica_written", markWritten),
+		callback.Delete().Before("*").Register("ginhub:replica_primary", s.usePrimary),
+		callback.Delete().After("*").Register("ginhub:replica_written", markWritten),
+		callback.Raw().Before("*").Register("ginhub:replica_primary", s.usePrimary),
+		callback.Raw().After("*").Register("ginhub:replica_written", markWritten),
+	)
+}
+
+// setupReplicas 连接配置的只读副本并启用读写分离。副本不可达时不影响启动，由健康检查移出轮询
+func setupReplicas(db *gorm.DB, cfg *config.AppConfig, logger *util.Logger) error {
+	rc := cfg.Database.Replicas
+	if len(rc.Sources) == 0 {
+		return nil
+	}
+
+	s := &replicaSet{
+		primary:  db.ConnPool,
+		policy:   orDefault(rc.Policy, replicaPolicyRoundRobin),
+		interval: orDefault(time.Duration(rc.HealthCheckInterval)*time.Second, defaultReplicaCheckInterval),
+		log:      logger,
+		stop:     make(chan struct{}),
+	}
+	if s.policy != replicaPolicyRoundRobin && s.policy != replicaPolicyLeastLatency {
+		return fmt.Errorf("unsupported replica policy: %s", s.policy)
+	}
+
+	var driverName string
+	switch cfg.Database.Driver {
+	case "mysql":
+		driverName = mysql.DefaultDriverName
+	case "postgres":
+		driverName = "pgx"
+	case "sqlite":
+		driverName = sqlite.DriverName
+	}
+	for i, source := range rc.Sources {
+		// sql.Open 只校验连接字符串，不会连接数据库
+		sqlDB, err := sql.Open(driverName, source)
+		if err != nil {
+			s.close()
+			return fmt.Errorf("failed to open replica %d: %w", i, err)
+		}
+		applyPool(sqlDB, cfg)
+		r := &replica{db: sqlDB}
+		r.healthy.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+
+	// 启动前先检查一次，不可达的副本从一开始就不参与轮询
+	s.check()
+	s.wg.Add(1)
+	go s.run()
+
+	if err := db.Use(s); err != nil {
+		s.close()
+		return err
+	}
+	logger.Info("Database replicas enabled", zap.Int("replicas", len(s.replicas)), zap.String("policy", s.policy))
+	return nil
+}
+
+// route 无需读主库时把查询切换到副本
+func (s *replicaSet) route(tx *gorm.DB) {
+	stmt := tx.Statement
+	// 事务内的查询必须与写入使用同一个连接
+	if _, ok := stmt.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	// 链式调用复用 Statement 时，上一次查询可能已切换到副本
+	s.usePrimary(tx)
+
+	if dbctx.UsePrimary(stmt.Context) {
+		return
+	}
+	if stmt.SQL.Len() > 0 {
+		// 原生 SQL 只有 SELECT 且不加锁时才读副本
+		rawSQL := strings.ToUpper(strings.TrimSpace(stmt.SQL.String()))
+		if !strings.HasPrefix(rawSQL, "SELECT") || strings.HasSuffix(rawSQL, "FOR UPDATE") || strings.HasSuffix(rawSQL, "FOR SHARE") {
+			return
+		}
+	} else if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+
+	if r := s.pick(); r != nil {
+		stmt.ConnPool = r.db
+	}
+}
+
+// usePrimary 把已切换到副本的 Statement 切回主库
+func (s *replicaSet) usePrimary(tx *gorm.DB) {
+	if db, ok := tx.Statement.ConnPool.(*sql.DB); ok && db != s.primary {
+		for _, r := range s.replicas {
+			if r.db == db {
+				tx.Statement.ConnPool = s.primary
+				return
+			}
+		}
+	}
+}
+
+// markWritten 写入成功后标记上下文，后续读取从主库执行
+func markWritten(tx *gorm.DB) {
+	if tx.Error == nil {
+		dbctx.MarkWritten(tx.Statement.Context)
+	}
+}
+
+// pick 按策略选择一个健康的副本，没有健康的副本时返回 nil
+func (s *replicaSet) pick() *replica {
+	var picked *replica
+	switch s.policy {
+	case replicaPolicyLeastLatency:
+		for _, r := range s.replicas {
+			if r.healthy.Load() && (picked == nil || r.latency.Load() < picked.latency.Load()) {
+				picked = r
+			}
+		}
+	default:
+		n := uint64(len(s.replicas))
+		start := s.next.Add(1)
+		for i := range n {
+			if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+				picked = r
+				break
+			}
+		}
+	}
+	return picked
+}
+
+// run 定期检查副本健康状态
+func (s *replicaSet) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.check()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// check Ping 每个副本，失败的副本移出轮询，恢复的副本重新加入
+func (s *replicaSet) check() {
+	for i, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+		start := time.Now()
+		err := r.db.PingContext(ctx)
+		cancel()
+
+		if err != nil {
+			if r.healthy.Swap(false) {
+				s.log.Warn("Database replica unhealthy, removed from rotation", zap.Int("replica", i), zap.Error(err))
+			}
+			continue
+		}
+		r.latency.Store(int64(time.Since(start)))
+		if !r.healthy.Swap(true) {
+			s.log.Info("Database replica recovered, added back to rotation", zap.Int("replica", i))
+		}
+	}
+}
+
+// health 返回各副本最近一次健康检查的结果和连接池统计
+func (s *replicaSet) health() []ReplicaHealth {
+	replicas := make([]ReplicaHealth, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		replicas = append(replicas, ReplicaHealth{
+			Healthy: r.healthy.Load(),
+			Latency: time.Duration(r.latency.Load()),
+			Stats:   r.db.Stats(),
+		})
+	}
+	return replicas
+}
+
+// close 停止健康检查并关闭副本连接
+func (s *replicaSet) close() error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+		close(s.stop)
+	}
+	s.wg.Wait()
+
+	var errs []error
+	for _, r := range s.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// replicasOf 返回注册在连接上的只读副本集合，未启用读写分离时返回 nil
+func replicasOf(db *gorm.DB) *replicaSet {
+	s, _ := db.Config.Plugins[(&replicaSet{}).Name()].(*replicaSet)
+	return s
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// newReplicaDB 创建一个 SQLite 数据库作为副本，写入只存在于该副本的用户后关闭
+func newReplicaDB(t *testing.T, username string) string {
+	cfg := testDatabases(t)["sqlite"]
+	db, err := NewDB(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&user.User{Username: username, Password: "x"}).Error)
+	require.NoError(t, closeDB(db))
+	return cfg.Database.Source
+}
+
+// newPrimaryDB 创建启用了只读副本的主库连接
+func newPrimaryDB(t *testing.T, policy string, replicas ...string) *gorm.DB {
+	cfg := testDatabases(t)["sqlite"]
+	cfg.Database.Replicas.Sources = replicas
+	cfg.Database.Replicas.Policy = policy
+	db, err := NewDB(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+	t.Cleanup(func() { closeDB(db) })
+	require.NoError(t, db.Create(&user.User{Username: "primary", Password: "x"}).Error)
+	return db
+}
+
+// readFrom 返回本次读取命中的数据库中的用户名
+func readFrom(t *testing.T, db *gorm.DB) string {
+	var users []user.User
+	require.NoError(t, db.Order("id").Find(&users).Error)
+	require.Len(t, users, 1)
+	return users[0].Username
+}
+
+func TestReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+	db := newPrimaryDB(t, "", newReplicaDB(t, "replica1"), newReplicaDB(t, "replica2"))
+
+	// 读取在副本间轮询
+	first := readFrom(t, db.WithContext(ctx))
+	second := readFrom(t, db.WithContext(ctx))
+	assert.ElementsMatch(t, []string{"replica1", "replica2"}, []string{first, second})
+
+	// 原生 SELECT 读副本，加锁读取和写入在主库
+	var username string
+	require.NoError(t, db.Raw("SELECT username FROM users").Scan(&username).Error)
+	assert.NotEqual(t, "primary", username)
+	var locked []user.User
+	require.NoError(t, db.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&locked).Error)
+	assert.Equal(t, "primary", locked[0].Username)
+
+	// 事务内的读取在主库
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		assert.Equal(t, "primary", readFrom(t, tx))
+		return nil
+	}))
+
+	// 强制读主库
+	assert.Equal(t, "primary", readFrom(t, db.WithContext(dbctx.WithPrimary(ctx))))
+}
+
+func TestReplicaReadYourWrites(t *testing.T) {
+	db := newPrimaryDB(t, "", newReplicaDB(t, "replica"))
+	ctx := dbctx.WithReadYourWrites(context.Background())
+
+	// 写入前读副本，写入后同一上下文读主库
+	assert.Equal(t, "replica", readFrom(t, db.WithContext(ctx)))
+	require.NoError(t, db.WithContext(ctx).Model(&user.User{}).Where("username = ?", "primary").Update("email", "primary@example.com").Error)
+	assert.Equal(t, "primary", readFrom(t, db.WithContext(ctx)))
+
+	// 其他上下文不受影响
+	assert.Equal(t, "replica", readFrom(t, db.WithContext(context.Background())))
+}
+
+func TestUnhealthyReplica(t *testing.T) {
+	missing := t.TempDir() + "/missing/replica.db"
+
+	// 不可达的副本不影响启动，也不参与读取
+	db := newPrimaryDB(t, "least_latency", missing, newReplicaDB(t, "replica"))
+	for range 3 {
+		assert.Equal(t, "replica", readFrom(t, db))
+	}
+	health, err := (&Data{db: db}).Health(context.Background())
+	require.NoError(t, err)
+	require.Len(t, health.Replicas, 2)
+	assert.False(t, health.Replicas[0].Healthy)
+	assert.True(t, health.Replicas[1].Healthy)
+
+	// 没有健康的副本时读主库
+	db = newPrimaryDB(t, "", missing)
+	assert.Equal(t, "primary", readFrom(t, db))
+}
+
+func TestReplicaPolicy(t *testing.T) {
+	cfg := testDatabases(t)["sqlite"]
+	cfg.Database.Replicas.Sources = []string{newReplicaDB(t, "replica")}
+	cfg.Database.Replicas.Policy = "random"
+	_, err := NewDB(cfg, util.NewLogger(cfg))
+	assert.ErrorContains(t, err, "unsupported replica policy")
+
+	s := &replicaSet{policy: replicaPolicyLeastLatency}
+	for _, latency := range []int64{30, 10, 20} {
+		r := &replica{}
+		r.healthy.Store(true)
+		r.latency.Store(latency)
+		s.replicas = append(s.replicas, r)
+	}
+	assert.Same(t, s.replicas[1], s.pick())
+	s.replicas[1].healthy.Store(false)
+	assert.Same(t, s.replicas[2], s.pick())
+}
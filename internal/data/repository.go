@@ -0,0 +1,489 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/HoronLee/GinHub/internal/util/paging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 列表查询条件支持的运算符
+const (
+	OpEq   = "="
+	OpNe   = "<>"
+	OpGt   = ">"
+	OpGte  = ">="
+	OpLt   = "<"
+	OpLte  = "<="
+	OpIn   = "IN"
+	OpLike = "LIKE"
+)
+
+// Filter 列表查询条件，Field 为模型的字段名或列名，OpIn 的 Value 为切片
+type Filter struct {
+	Field string
+	Op    string
+	Value any
+}
+
+// ListSpec 列表查询条件、排序和范围
+type ListSpec struct {
+	Filters  []Filter
+	Where    []clause.Expression // Filters 无法表达的其他条件，例如 OR 组合
+	Sort     []paging.Sort
+	Preload  []string
+	Limit    int  // 为 0 时不限制数量
+	Offset   int  // 跳过的记录数
+	Unscoped bool // 包含已软删除的记录
+}
+
+// Repository 通用的单表仓储，提供按主键的增删改查、条件列表、分页和批量操作，
+// 通过 Data.DB 执行，自动使用上下文中的事务
+type Repository[T any, ID comparable] struct {
+	data *Data
+	name string
+}
+
+// NewRepository 创建 T 的通用仓储，ID 为主键类型
+func NewRepository[T any, ID comparable](data *Data) *Repository[T, ID] {
+	return &Repository[T, ID]{
+		data: data,
+		name: reflect.TypeFor[T]().Name(),
+	}
+}
+
+// Get 根据主键查询记录，不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T, ID]) Get(ctx context.Context, id ID) (*T, error) {
+	pk, err := r.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+	var entity T
+	if err := r.data.DB(ctx).Where(clause.Eq{Column: pk, Value: id}).First(&entity).Error; err != nil {
+		r.data.log.Debug("Record not found", zap.String("model", r.name), zap.Any("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// GetMany 根据主键批量查询记录，不存在的主键被忽略，结果按主键排序
+func (r *Repository[T, ID]) GetMany(ctx context.Context, ids []ID) ([]T, error) {
+	pk, err := r.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+	entities := []T{}
+	if len(ids) == 0 {
+		return entities, nil
+	}
+	err = r.data.DB(ctx).Where(clause.IN{Column: pk, Values: toAnySlice(ids)}).Order(clause.OrderByColumn{Column: pk}).Find(&entities).Error
+	if err != nil {
+		r.data.log.Error("Failed to get records", zap.String("model", r.name), zap.Error(err))
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Exists 判断主键对应的记录是否存在
+func (r *Repository[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
+	pk, err := r.primaryKey()
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	if err := r.data.DB(ctx).Model(new(T)).Where(clause.Eq{Column: pk, Value: id}).Limit(1).Count(&count).Error; err != nil {
+		r.data.log.Error("Failed to check record", zap.String("model", r.name), zap.Any("id", id), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// List 按条件查询记录列表
+func (r *Repository[T, ID]) List(ctx context.Context, spec ListSpec) ([]T, error) {
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if query, err = r.order(query, spec.Sort, false); err != nil {
+		return nil, err
+	}
+	if spec.Limit > 0 {
+		query = query.Limit(spec.Limit)
+	}
+	if spec.Offset > 0 {
+		query = query.Offset(spec.Offset)
+	}
+
+	entities := []T{}
+	if err := query.Find(&entities).Error; err != nil {
+		r.data.log.Error("Failed to list records", zap.String("model", r.name), zap.Error(err))
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Count 统计符合条件的记录数，忽略 spec 中的排序和范围
+func (r *Repository[T, ID]) Count(ctx context.Context, spec ListSpec) (int64, error) {
+	query, err := r.query(ctx, ListSpec{Filters: spec.Filters, Where: spec.Where, Unscoped: spec.Unscoped})
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.data.log.Error("Failed to count records", zap.String("model", r.name), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// Paginate 按条件分页查询，排序使用 params.Sort，为空时使用 spec.Sort，相同值按主键排序保证顺序稳定。
+// 偏移分页同时返回总数；游标分页的排序字段不能为 NULL
+func (r *Repository[T, ID]) Paginate(ctx context.Context, spec ListSpec, params paging.Params) (*paging.Page[T], error) {
+	sorts := params.Sort
+	if len(sorts) == 0 {
+		sorts = spec.Sort
+	}
+	size := params.Limit()
+	page := &paging.Page[T]{Size: size}
+
+	// 查询条件被 Count 和 Find 各使用一次，分别构建避免共用 Statement
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Page > 0 {
+		total, err := r.Count(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		page.Total, page.Page = &total, params.Page
+		query = query.Limit(size).Offset(params.Offset())
+	} else {
+		if params.Cursor != nil {
+			after, err := r.after(sorts, params.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(after)
+		}
+		// 多查询一条用于判断是否存在下一页
+		query = query.Limit(size + 1)
+	}
+	if query, err = r.order(query, sorts, true); err != nil {
+		return nil, err
+	}
+
+	if err := query.Find(&page.Items).Error; err != nil {
+		r.data.log.Error("Failed to paginate records", zap.String("model", r.name), zap.Error(err))
+		return nil, err
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if params.Page == 0 && len(page.Items) > size {
+		page.Items = page.Items[:size]
+		if page.NextCursor, err = r.cursor(ctx, sorts, &page.Items[size-1]); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// Create 创建记录
+func (r *Repository[T, ID]) Create(ctx context.Context, entity *T) error {
+	r.data.log.Debug("Creating record", zap.String("model", r.name))
+	if err := r.data.DB(ctx).Create(entity).Error; err != nil {
+		r.data.log.Error("Failed to create record", zap.String("model", r.name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CreateBatch 批量创建记录，每次最多插入 batchSize 条，batchSize 为 0 时一次插入全部
+func (r *Repository[T, ID]) CreateBatch(ctx context.Context, entities []T, batchSize int) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	r.data.log.Debug("Creating records", zap.String("model", r.name), zap.Int("count", len(entities)))
+	if batchSize <= 0 {
+		batchSize = len(entities)
+	}
+	if err := r.data.DB(ctx).CreateInBatches(&entities, batchSize).Error; err != nil {
+		r.data.log.Error("Failed to create records", zap.String("model", r.name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update 按主键更新记录的全部字段，包括零值，不更新关联，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T, ID]) Update(ctx context.Context, entity *T) error {
+	r.data.log.Debug("Updating record", zap.String("model", r.name))
+	result := r.data.DB(ctx).Model(entity).Select("*").Omit(clause.Associations).Updates(entity)
+	if result.Error != nil {
+		r.data.log.Error("Failed to update record", zap.String("model", r.name), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateFields 按主键更新指定字段，fields 的键为字段名或列名，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T, ID]) UpdateFields(ctx context.Context, id ID, fields map[string]any) error {
+	n, err := r.UpdateFieldsBatch(ctx, []ID{id}, fields)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateFieldsBatch 按主键批量更新指定字段，返回更新的记录数
+func (r *Repository[T, ID]) UpdateFieldsBatch(ctx context.Context, ids []ID, fields map[string]any) (int64, error) {
+	pk, err := r.primaryKey()
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 || len(fields) == 0 {
+		return 0, nil
+	}
+	r.data.log.Debug("Updating record fields", zap.String("model", r.name), zap.Int("count", len(ids)))
+	result := r.data.DB(ctx).Model(new(T)).Where(clause.IN{Column: pk, Values: toAnySlice(ids)}).Updates(fields)
+	if result.Error != nil {
+		r.data.log.Error("Failed to update record fields", zap.String("model", r.name), zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// Delete 按主键删除记录，模型有 DeletedAt 字段时为软删除，返回记录是否存在
+func (r *Repository[T, ID]) Delete(ctx context.Context, id ID) (bool, error) {
+	n, err := r.DeleteBatch(ctx, []ID{id})
+	return n > 0, err
+}
+
+// DeleteBatch 按主键批量删除记录，返回删除的记录数
+func (r *Repository[T, ID]) DeleteBatch(ctx context.Context, ids []ID) (int64, error) {
+	pk, err := r.primaryKey()
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	r.data.log.Debug("Deleting records", zap.String("model", r.name), zap.Int("count", len(ids)))
+	result := r.data.DB(ctx).Where(clause.IN{Column: pk, Values: toAnySlice(ids)}).Delete(new(T))
+	if result.Error != nil {
+		r.data.log.Error("Failed to delete records", zap.String("model", r.name), zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// schema 解析 T 的表结构，gorm 会缓存解析结果
+func (r *Repository[T, ID]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.data.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// primaryKey 返回 T 的主键列
+func (r *Repository[T, ID]) primaryKey() (clause.Column, error) {
+	sch, err := r.schema()
+	if err != nil {
+		return clause.Column{}, err
+	}
+	if sch.PrioritizedPrimaryField == nil {
+		return clause.Column{}, fmt.Errorf("model %s has no primary key", r.name)
+	}
+	return clause.Column{Table: clause.CurrentTable, Name: sch.PrioritizedPrimaryField.DBName}, nil
+}
+
+// field 根据字段名或列名查找 T 的数据库字段，未知字段返回错误，避免拼接任意列名
+func (r *Repository[T, ID]) field(name string) (*schema.Field, error) {
+	sch, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	f := sch.LookUpField(name)
+	if f == nil || f.DBName == "" {
+		return nil, fmt.Errorf("unknown field %q of %s", name, r.name)
+	}
+	return f, nil
+}
+
+// query 构建带查询条件的查询
+func (r *Repository[T, ID]) query(ctx context.Context, spec ListSpec) (*gorm.DB, error) {
+	query := r.data.DB(ctx).Model(new(T))
+	if spec.Unscoped {
+		query = query.Unscoped()
+	}
+	for _, filter := range spec.Filters {
+		f, err := r.field(filter.Field)
+		if err != nil {
+			return nil, err
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: f.DBName}
+		var expr clause.Expression
+		switch filter.Op {
+		case OpEq:
+			expr = clause.Eq{Column: column, Value: filter.Value}
+		case OpNe:
+			expr = clause.Neq{Column: column, Value: filter.Value}
+		case OpGt:
+			expr = clause.Gt{Column: column, Value: filter.Value}
+		case OpGte:
+			expr = clause.Gte{Column: column, Value: filter.Value}
+		case OpLt:
+			expr = clause.Lt{Column: column, Value: filter.Value}
+		case OpLte:
+			expr = clause.Lte{Column: column, Value: filter.Value}
+		case OpIn:
+			values := reflect.ValueOf(filter.Value)
+			if values.Kind() != reflect.Slice {
+				return nil, fmt.Errorf("value of %s filter on %q must be a slice", OpIn, filter.Field)
+			}
+			in := clause.IN{Column: column}
+			for i := range values.Len() {
+				in.Values = append(in.Values, values.Index(i).Interface())
+			}
+			expr = in
+		case OpLike:
+			expr = clause.Like{Column: column, Value: filter.Value}
+		default:
+			return nil, fmt.Errorf("unsupported filter operator %q", filter.Op)
+		}
+		query = query.Where(expr)
+	}
+	for _, expr := range spec.Where {
+		query = query.Where(expr)
+	}
+	for _, preload := range spec.Preload {
+		query = query.Preload(preload)
+	}
+	return query, nil
+}
+
+// sortFields 将排序字段转换为数据库字段，stable 为 true 时追加主键排序保证顺序稳定
+func (r *Repository[T, ID]) sortFields(sorts []paging.Sort, stable bool) ([]*schema.Field, []bool, error) {
+	fields := make([]*schema.Field, 0, len(sorts)+1)
+	desc := make([]bool, 0, len(sorts)+1)
+	for _, s := range sorts {
+		f, err := r.field(s.Field)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", paging.ErrInvalidSort, s.Field)
+		}
+		fields = append(fields, f)
+		desc = append(desc, s.Desc)
+	}
+
+	if stable {
+		sch, err := r.schema()
+		if err != nil {
+			return nil, nil, err
+		}
+		pk := sch.PrioritizedPrimaryField
+		if pk == nil {
+			return nil, nil, fmt.Errorf("model %s has no primary key", r.name)
+		}
+		if !slices.Contains(fields, pk) {
+			// 主键与最后一个排序字段同向，未指定排序时按主键升序
+			fields = append(fields, pk)
+			desc = append(desc, len(desc) > 0 && desc[len(desc)-1])
+		}
+	}
+	return fields, desc, nil
+}
+
+// order 为查询添加排序
+func (r *Repository[T, ID]) order(query *gorm.DB, sorts []paging.Sort, stable bool) (*gorm.DB, error) {
+	fields, desc, err := r.sortFields(sorts, stable)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range fields {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Desc: desc[i]})
+	}
+	return query, nil
+}
+
+// after 根据游标生成查询排在游标位置之后记录的条件：
+// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z)，降序字段使用 <
+func (r *Repository[T, ID]) after(sorts []paging.Sort, cursor *paging.Cursor) (clause.Expression, error) {
+	if cursor.Sort != paging.FormatSort(sorts) {
+		return nil, paging.ErrInvalidCursor
+	}
+	fields, desc, err := r.sortFields(sorts, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(cursor.Values) != len(fields) {
+		return nil, paging.ErrInvalidCursor
+	}
+
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		v := reflect.New(f.FieldType)
+		if err := json.Unmarshal(cursor.Values[i], v.Interface()); err != nil {
+			return nil, paging.ErrInvalidCursor
+		}
+		values[i] = v.Elem().Interface()
+	}
+
+	ors := make([]clause.Expression, 0, len(fields))
+	for i, f := range fields {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := range i {
+			ands = append(ands, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: fields[j].DBName}, Value: values[j]})
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: f.DBName}
+		if desc[i] {
+			ands = append(ands, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: column, Value: values[i]})
+		}
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...), nil
+}
+
+// cursor 生成指向 entity 的游标
+func (r *Repository[T, ID]) cursor(ctx context.Context, sorts []paging.Sort, entity *T) (string, error) {
+	fields, _, err := r.sortFields(sorts, true)
+	if err != nil {
+		return "", err
+	}
+	cursor := &paging.Cursor{Sort: paging.FormatSort(sorts)}
+	rv := reflect.ValueOf(entity).Elem()
+	for _, f := range fields {
+		v, _ := f.ValueOf(ctx, rv)
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, raw)
+	}
+	return cursor.Encode(), nil
+}
+
+// toAnySlice 将主键切片转换为 clause.IN 使用的 []any
+func toAnySlice[ID any](ids []ID) []any {
+	values := make([]any, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
@@ -0,0 +1,208 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/helloworld"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/paging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func newRepositoryTestData(t *testing.T) *Data {
+	cfg := testDatabases(t)["sqlite"]
+	logger := util.NewLogger(cfg)
+	db, err := NewDB(cfg, logger)
+	require.NoError(t, err)
+	d, cleanup, err := NewData(db, logger)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return d
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	repo := NewRepository[user.User, uint](newRepositoryTestData(t))
+	ctx := context.Background()
+
+	u := &user.User{Username: "alice", Password: "x", DisplayName: "Alice"}
+	require.NoError(t, repo.Create(ctx, u))
+	got, err := repo.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Username)
+	_, err = repo.Get(ctx, u.ID+1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 全量更新包括零值，部分更新只修改指定字段
+	got.DisplayName = ""
+	got.Bio = "hello"
+	require.NoError(t, repo.Update(ctx, got))
+	require.NoError(t, repo.UpdateFields(ctx, u.ID, map[string]any{"locale": "zh-CN"}))
+	got, err = repo.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.DisplayName)
+	assert.Equal(t, "hello", got.Bio)
+	assert.Equal(t, "zh-CN", got.Locale)
+	assert.ErrorIs(t, repo.UpdateFields(ctx, u.ID+1, map[string]any{"locale": "en"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, &user.User{ID: u.ID + 1, Username: "nobody"}), gorm.ErrRecordNotFound)
+
+	exists, err := repo.Exists(ctx, u.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// 软删除后查询不到，Unscoped 时仍能列出
+	deleted, err := repo.Delete(ctx, u.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.Delete(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	exists, err = repo.Exists(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	users, err := repo.List(ctx, ListSpec{Filters: []Filter{{Field: "Username", Op: OpEq, Value: "alice"}}, Unscoped: true})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestRepositoryBatch(t *testing.T) {
+	repo := NewRepository[helloworld.HelloWorld, uint](newRepositoryTestData(t))
+	ctx := context.Background()
+
+	hws := []helloworld.HelloWorld{{Message: "a"}, {Message: "b"}, {Message: "c"}, {Message: "d"}, {Message: "e"}}
+	require.NoError(t, repo.CreateBatch(ctx, hws, 2))
+	ids := make([]uint, len(hws))
+	for i, hw := range hws {
+		require.NotZero(t, hw.ID)
+		ids[i] = hw.ID
+	}
+
+	got, err := repo.GetMany(ctx, []uint{ids[3], ids[1], 999})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "b", got[0].Message)
+	assert.Equal(t, "d", got[1].Message)
+
+	n, err := repo.UpdateFieldsBatch(ctx, ids[:2], map[string]any{"message": "updated"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	count, err := repo.Count(ctx, ListSpec{Filters: []Filter{{Field: "message", Op: OpEq, Value: "updated"}}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	n, err = repo.DeleteBatch(ctx, ids[:3])
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	count, err = repo.Count(ctx, ListSpec{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRepositoryList(t *testing.T) {
+	repo := NewRepository[helloworld.HelloWorld, uint](newRepositoryTestData(t))
+	ctx := context.Background()
+	for _, msg := range []string{"apple", "banana", "cherry", "apricot"} {
+		require.NoError(t, repo.Create(ctx, &helloworld.HelloWorld{Message: msg}))
+	}
+	messages := func(hws []helloworld.HelloWorld) []string {
+		var msgs []string
+		for _, hw := range hws {
+			msgs = append(msgs, hw.Message)
+		}
+		return msgs
+	}
+
+	hws, err := repo.List(ctx, ListSpec{
+		Filters: []Filter{{Field: "Message", Op: OpLike, Value: "ap%"}},
+		Sort:    []paging.Sort{{Field: "message", Desc: true}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apricot", "apple"}, messages(hws))
+
+	hws, err = repo.List(ctx, ListSpec{
+		Filters: []Filter{{Field: "message", Op: OpIn, Value: []string{"banana", "cherry", "durian"}}, {Field: "id", Op: OpGt, Value: 0}},
+		Sort:    []paging.Sort{{Field: "id"}},
+		Limit:   1,
+		Offset:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cherry"}, messages(hws))
+
+	// Where 与 Filters 同时生效
+	message := clause.Column{Table: clause.CurrentTable, Name: "message"}
+	where := ListSpec{
+		Filters: []Filter{{Field: "id", Op: OpGt, Value: 1}},
+		Where:   []clause.Expression{clause.Or(clause.Eq{Column: message, Value: "apple"}, clause.Eq{Column: message, Value: "cherry"})},
+	}
+	hws, err = repo.List(ctx, where)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cherry"}, messages(hws))
+	count, err := repo.Count(ctx, where)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// 只能使用模型中的字段，避免拼接任意列名
+	_, err = repo.List(ctx, ListSpec{Filters: []Filter{{Field: "1=1 OR message", Op: OpEq, Value: "x"}}})
+	assert.ErrorContains(t, err, "unknown field")
+	_, err = repo.List(ctx, ListSpec{Sort: []paging.Sort{{Field: "password"}}})
+	assert.ErrorIs(t, err, paging.ErrInvalidSort)
+	_, err = repo.List(ctx, ListSpec{Filters: []Filter{{Field: "message", Op: "BETWEEN", Value: "x"}}})
+	assert.ErrorContains(t, err, "unsupported filter operator")
+}
+
+func TestRepositoryPaginate(t *testing.T) {
+	repo := NewRepository[helloworld.HelloWorld, uint](newRepositoryTestData(t))
+	ctx := context.Background()
+
+	// 每两条记录的创建时间相同，游标分页需要按主键区分
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var hws []helloworld.HelloWorld
+	for i := range 7 {
+		hws = append(hws, helloworld.HelloWorld{Message: string(rune('a' + i)), CreatedAt: base.Add(time.Duration(i/2) * time.Hour)})
+	}
+	require.NoError(t, repo.CreateBatch(ctx, hws, 0))
+	sorts := []paging.Sort{{Field: "created_at", Desc: true}}
+
+	// 偏移分页返回总数
+	page, err := repo.Paginate(ctx, ListSpec{}, paging.Params{Page: 2, Size: 3, Sort: sorts})
+	require.NoError(t, err)
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(7), *page.Total)
+	assert.Equal(t, 2, page.Page)
+	assert.Empty(t, page.NextCursor)
+	var msgs []string
+	for _, hw := range page.Items {
+		msgs = append(msgs, hw.Message)
+	}
+	assert.Equal(t, []string{"d", "c", "b"}, msgs)
+
+	// 游标分页逐页读取，不重复也不遗漏
+	msgs = nil
+	params := paging.Params{Size: 3, Sort: sorts}
+	for {
+		page, err := repo.Paginate(ctx, ListSpec{}, params)
+		require.NoError(t, err)
+		assert.Nil(t, page.Total)
+		for _, hw := range page.Items {
+			msgs = append(msgs, hw.Message)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor, err = paging.DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"g", "f", "e", "d", "c", "b", "a"}, msgs)
+
+	// 游标与排序方式不匹配
+	params.Sort = []paging.Sort{{Field: "message"}}
+	_, err = repo.Paginate(ctx, ListSpec{}, params)
+	assert.ErrorIs(t, err, paging.ErrInvalidCursor)
+	_, err = repo.Paginate(ctx, ListSpec{}, paging.Params{Cursor: &paging.Cursor{}})
+	assert.ErrorIs(t, err, paging.ErrInvalidCursor)
+}
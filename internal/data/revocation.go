@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRevocationGCInterval 未配置时吊销记录的清理间隔
+const defaultRevocationGCInterval = 10 * time.Minute
+
+// NewRevocationStore 根据配置创建令牌吊销列表存储，并在后台定期清理过期记录
+func NewRevocationStore(cfg *config.AppConfig, data *Data) (service.RevocationStore, func(), error) {
+	var store service.RevocationStore
+	switch cfg.Auth.Revocation.Store {
+	case "memory":
+		store = newMemoryRevocationStore()
+	case "database", "":
+		store = &gormRevocationStore{data: data}
+	default:
+		return nil, nil, fmt.Errorf("unsupported revocation store: %s", cfg.Auth.Revocation.Store)
+	}
+
+	interval := time.Duration(cfg.Auth.Revocation.GCInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultRevocationGCInterval
+	}
+
+	cleanup := startExpiryGC(data, "revoked tokens", interval, store.DeleteExpired)
+	return store, cleanup, nil
+}
+
+// startExpiryGC 在后台按 interval 定期调用 deleteExpired 清理过期记录，返回停止清理的函数
+func startExpiryGC(data *Data, name string, interval time.Duration, deleteExpired func(context.Context) (int64, error)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := deleteExpired(ctx)
+				if err != nil {
+					data.log.Error("Failed to delete expired "+name, zap.Error(err))
+					continue
+				}
+				if n > 0 {
+					data.log.Debug("Expired "+name+" deleted", zap.Int64("count", n))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// memoryRevocationStore 基于内存的吊销列表，仅适用于单实例部署
+type memoryRevocationStore struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{entries: make(map[string]time.Time)}
+}
+
+func (s *memoryRevocationStore) Revoke(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[jti] = expiresAt
+	return nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	expiresAt, ok := s.entries[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (s *memoryRevocationStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for jti, expiresAt := range s.entries {
+		if !now.Before(expiresAt) {
+			delete(s.entries, jti)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// gormRevocationStore 基于数据库的吊销列表，支持多实例部署
+type gormRevocationStore struct {
+	data *Data
+}
+
+func (s *gormRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.data.log.Debug("Revoking token", zap.String("jti", jti))
+	err := s.data.DB(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&user.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		s.data.log.Error("Failed to revoke token", zap.Error(err), zap.String("jti", jti))
+		return err
+	}
+	return nil
+}
+
+func (s *gormRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var rt user.RevokedToken
+	// 吊销需要立即生效，从主库读取
+	err := s.data.DB(dbctx.WithPrimary(ctx)).Select("id").Where("jti = ? AND expires_at > ?", jti, time.Now()).First(&rt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *gormRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.data.DB(ctx).Where("expires_at <= ?", time.Now()).Delete(&user.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
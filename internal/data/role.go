@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// roleRepo 角色数据访问实现
+type roleRepo struct {
+	data *Data
+}
+
+// NewRoleRepo 创建RoleRepo实例
+func NewRoleRepo(data *Data) service.RoleRepo {
+	return &roleRepo{
+		data: data,
+	}
+}
+
+// ListRoles 查询所有角色及其权限
+func (r *roleRepo) ListRoles(ctx context.Context) ([]user.Role, error) {
+	var roles []user.Role
+	err := r.data.DB(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	if err != nil {
+		r.data.log.Error("Failed to list roles", zap.Error(err))
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRoleByName 根据名称查询角色
+func (r *roleRepo) GetRoleByName(ctx context.Context, name string) (*user.Role, error) {
+	var role user.Role
+	err := r.data.DB(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		r.data.log.Debug("Role not found", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CreateRole 创建角色，不存在的权限会一并创建
+func (r *roleRepo) CreateRole(ctx context.Context, role *user.Role, permissions []string) error {
+	r.data.log.Debug("Creating role", zap.String("name", role.Name), zap.Strings("permissions", permissions))
+	err := r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, name := range permissions {
+			perm := user.Permission{Name: name}
+			if err := tx.Where(user.Permission{Name: name}).FirstOrCreate(&perm).Error; err != nil {
+				return err
+			}
+			role.Permissions = append(role.Permissions, perm)
+		}
+		return tx.Create(role).Error
+	})
+	if err != nil {
+		r.data.log.Error("Failed to create role", zap.Error(err), zap.String("name", role.Name))
+		return err
+	}
+	r.data.log.Info("Role created successfully", zap.String("name", role.Name), zap.Uint("id", role.ID))
+	return nil
+}
+
+// GetUserRoles 查询用户的角色及其权限
+func (r *roleRepo) GetUserRoles(ctx context.Context, userID uint) ([]user.Role, error) {
+	var roles []user.Role
+	err := r.data.DB(ctx).Model(&user.User{ID: userID}).Preload("Permissions").Association("Roles").Find(&roles)
+	if err != nil {
+		r.data.log.Error("Failed to get user roles", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignRole 为用户分配角色，重复分配不会报错
+func (r *roleRepo) AssignRole(ctx context.Context, userID uint, roleName string) error {
+	r.data.log.Debug("Assigning role", zap.Uint("user_id", userID), zap.String("role", roleName))
+	role, err := r.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	err = r.data.DB(ctx).Model(&user.User{ID: userID}).Association("Roles").Append(role)
+	if err != nil {
+		r.data.log.Error("Failed to assign role", zap.Error(err), zap.Uint("user_id", userID), zap.String("role", roleName))
+		return err
+	}
+	r.data.log.Info("Role assigned successfully", zap.Uint("user_id", userID), zap.String("role", roleName))
+	return nil
+}
+
+// RemoveRole 移除用户的角色
+func (r *roleRepo) RemoveRole(ctx context.Context, userID uint, roleName string) error {
+	r.data.log.Debug("Removing role", zap.Uint("user_id", userID), zap.String("role", roleName))
+	role, err := r.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	err = r.data.DB(ctx).Model(&user.User{ID: userID}).Association("Roles").Delete(role)
+	if err != nil {
+		r.data.log.Error("Failed to remove role", zap.Error(err), zap.Uint("user_id", userID), zap.String("role", roleName))
+		return err
+	}
+	r.data.log.Info("Role removed successfully", zap.Uint("user_id", userID), zap.String("role", roleName))
+	return nil
+}
+
+// CountUsersWithRole 统计拥有指定角色的有效用户数，已软删除和已禁用的用户不计入
+func (r *roleRepo) CountUsersWithRole(ctx context.Context, roleName string) (int64, error) {
+	var count int64
+	err := r.data.DB(ctx).Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Joins("JOIN users ON users.id = user_roles.user_id").
+		Where("roles.name = ? AND users.deleted_at IS NULL AND users.status <> ?", roleName, user.StatusDisabled).
+		Count(&count).Error
+	return count, err
+}
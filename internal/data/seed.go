@@ -0,0 +1,65 @@
+package data
+
+import (
+	"errors"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"gorm.io/gorm"
+)
+
+// builtinPermissions 内置权限
+var builtinPermissions = []user.Permission{
+	{Name: user.PermissionAll, Description: "全部权限"},
+	{Name: user.PermissionUserRead, Description: "查看任意用户"},
+	{Name: user.PermissionUserUpdate, Description: "修改任意用户"},
+	{Name: user.PermissionUserDelete, Description: "删除任意用户"},
+	{Name: user.PermissionRoleManage, Description: "管理角色及角色分配"},
+	{Name: user.PermissionOAuthManage, Description: "管理 OAuth2 客户端"},
+}
+
+// seedRBAC 初始化内置角色和权限，可重复执行
+func seedRBAC(db *gorm.DB, cfg *config.AppConfig) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 1. 内置权限
+		permissions := make(map[string]*user.Permission, len(builtinPermissions))
+		for _, p := range builtinPermissions {
+			perm := p
+			if err := tx.Where(user.Permission{Name: perm.Name}).Attrs(user.Permission{Description: perm.Description}).FirstOrCreate(&perm).Error; err != nil {
+				return err
+			}
+			permissions[perm.Name] = &perm
+		}
+
+		// 2. 内置角色，管理员拥有通配权限
+		admin := user.Role{Name: user.RoleAdmin}
+		if err := tx.Where(user.Role{Name: user.RoleAdmin}).Attrs(user.Role{Description: "系统管理员"}).FirstOrCreate(&admin).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&admin).Association("Permissions").Append(permissions[user.PermissionAll]); err != nil {
+			return err
+		}
+
+		defaultRole := user.Role{Name: user.RoleUser}
+		if err := tx.Where(user.Role{Name: user.RoleUser}).Attrs(user.Role{Description: "普通用户"}).FirstOrCreate(&defaultRole).Error; err != nil {
+			return err
+		}
+
+		// 3. 为配置中指定的已有用户授予管理员角色
+		for _, username := range cfg.Auth.RBAC.Admins {
+			var u user.User
+			err := tx.Where("username = ?", username).First(&u).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&u).Association("Roles").Append(&admin); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
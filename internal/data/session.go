@@ -0,0 +1,76 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"go.uber.org/zap"
+)
+
+// sessionRepo 登录会话数据访问实现
+type sessionRepo struct {
+	data *Data
+}
+
+// NewSessionRepo 创建SessionRepo实例
+func NewSessionRepo(data *Data) service.SessionRepo {
+	return &sessionRepo{
+		data: data,
+	}
+}
+
+// CreateSession 创建登录会话
+func (r *sessionRepo) CreateSession(ctx context.Context, s *user.Session) error {
+	err := r.data.DB(ctx).Create(s).Error
+	if err != nil {
+		r.data.log.Error("Failed to create session", zap.Error(err), zap.Uint("user_id", s.UserID))
+		return err
+	}
+	return nil
+}
+
+// ListActiveSessions 查询用户未吊销且未过期的会话，按最近活跃时间倒序排列
+func (r *sessionRepo) ListActiveSessions(ctx context.Context, userID uint) ([]user.Session, error) {
+	var sessions []user.Session
+	err := r.data.DB(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").Order("id DESC").
+		Find(&sessions).Error
+	if err != nil {
+		r.data.log.Error("Failed to list sessions", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RefreshSession 刷新令牌轮换后更新会话的活跃时间和过期时间
+func (r *sessionRepo) RefreshSession(ctx context.Context, familyID string, lastSeenAt, expiresAt time.Time) error {
+	return r.data.DB(ctx).Model(&user.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]any{"last_seen_at": lastSeenAt, "expires_at": expiresAt}).Error
+}
+
+// TouchSession 更新会话的最近活跃时间，已在 before 之后更新过时不写入
+func (r *sessionRepo) TouchSession(ctx context.Context, familyID string, at, before time.Time) error {
+	return r.data.DB(ctx).Model(&user.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL AND last_seen_at < ?", familyID, before).
+		Update("last_seen_at", at).Error
+}
+
+// RevokeSessions 吊销指定令牌族的会话
+func (r *sessionRepo) RevokeSessions(ctx context.Context, familyIDs []string) error {
+	if len(familyIDs) == 0 {
+		return nil
+	}
+	err := r.data.DB(ctx).Model(&user.Session{}).
+		Where("family_id IN ? AND revoked_at IS NULL", familyIDs).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.data.log.Error("Failed to revoke sessions", zap.Error(err), zap.Int("count", len(familyIDs)))
+		return err
+	}
+	r.data.log.Info("Sessions revoked", zap.Int("count", len(familyIDs)))
+	return nil
+}
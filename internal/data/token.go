@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	"go.uber.org/zap"
+)
+
+// refreshTokenRepo 刷新令牌数据访问实现
+type refreshTokenRepo struct {
+	data *Data
+}
+
+// NewRefreshTokenRepo 创建RefreshTokenRepo实例
+func NewRefreshTokenRepo(data *Data) service.RefreshTokenRepo {
+	return &refreshTokenRepo{
+		data: data,
+	}
+}
+
+// CreateRefreshToken 创建刷新令牌记录
+func (r *refreshTokenRepo) CreateRefreshToken(ctx context.Context, rt *user.RefreshToken) error {
+	r.data.log.Debug("Creating refresh token", zap.Uint("user_id", rt.UserID), zap.String("family_id", rt.FamilyID))
+	err := r.data.DB(ctx).Create(rt).Error
+	if err != nil {
+		r.data.log.Error("Failed to create refresh token", zap.Error(err), zap.Uint("user_id", rt.UserID))
+		return err
+	}
+	return nil
+}
+
+// GetRefreshTokenByHash 根据令牌摘要查询刷新令牌
+func (r *refreshTokenRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*user.RefreshToken, error) {
+	var rt user.RefreshToken
+	// 刷新令牌签发后可能立即被使用，从主库读取以免副本复制延迟
+	err := r.data.DB(dbctx.WithPrimary(ctx)).Where("token_hash = ?", tokenHash).First(&rt).Error
+	if err != nil {
+		r.data.log.Debug("Refresh token not found", zap.Error(err))
+		return nil, err
+	}
+	return &rt, nil
+}
+
+// MarkRefreshTokenUsed 将刷新令牌标记为已使用
+// 通过 used_at IS NULL 条件保证同一令牌只能被成功标记一次
+func (r *refreshTokenRepo) MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error) {
+	result := r.data.DB(ctx).Model(&user.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to mark refresh token used", zap.Error(result.Error), zap.Uint("id", id))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeRefreshTokenFamily 吊销令牌族内的所有刷新令牌
+func (r *refreshTokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	err := r.data.DB(ctx).Model(&user.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.data.log.Error("Failed to revoke refresh token family", zap.Error(err), zap.String("family_id", familyID))
+		return err
+	}
+	r.data.log.Warn("Refresh token family revoked", zap.String("family_id", familyID))
+	return nil
+}
+
+// RevokeUserRefreshTokens 吊销用户的所有刷新令牌
+func (r *refreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID uint) error {
+	err := r.data.DB(ctx).Model(&user.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.data.log.Error("Failed to revoke user refresh tokens", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	r.data.log.Info("User refresh tokens revoked", zap.Uint("user_id", userID))
+	return nil
+}
+
+// RevokeOtherRefreshTokens 吊销用户除指定令牌族以外的所有刷新令牌
+func (r *refreshTokenRepo) RevokeOtherRefreshTokens(ctx context.Context, userID uint, keepFamilyID string) error {
+	err := r.data.DB(ctx).Model(&user.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.data.log.Error("Failed to revoke other refresh tokens", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+	r.data.log.Info("Other refresh tokens revoked", zap.Uint("user_id", userID), zap.String("kept_family_id", keepFamilyID))
+	return nil
+}
@@ -0,0 +1,91 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/service"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// txKey 是一个未导出的类型，用作在上下文中存储事务的键
+type txKey struct{}
+
+// DB 返回绑定上下文的数据库连接，上下文中有 WithinTx 开启的事务时返回该事务
+func (d *Data) DB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return d.db.WithContext(ctx)
+}
+
+// transactor 基于 gorm 的事务管理器，事务保存在上下文中，仓储通过 Data.DB 自动使用
+type transactor struct {
+	data       *Data
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewTransactor 创建Transactor实例
+func NewTransactor(cfg *config.AppConfig, data *Data) service.Transactor {
+	return &transactor{
+		data:       data,
+		maxRetries: cfg.Database.Transaction.MaxRetries,
+		retryDelay: time.Duration(cfg.Database.Transaction.RetryDelay) * time.Millisecond,
+	}
+}
+
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	run := func() error {
+		// 上下文中已有事务时 gorm 会创建保存点
+		return t.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	}
+
+	// 死锁和序列化冲突会使整个事务失效，只能由最外层事务重试
+	if _, nested := ctx.Value(txKey{}).(*gorm.DB); nested {
+		return run()
+	}
+
+	delay := t.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil || attempt >= t.maxRetries || !isRetryableTxError(err) {
+			return err
+		}
+
+		t.data.log.Warn("Transaction conflict, retrying", zap.Int("attempt", attempt+1), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableTxError 判断错误是否为重试整个事务即可解决的死锁或序列化冲突
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK
+		return mysqlErr.Number == 1213
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// serialization_failure、deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTransactorTestData(t *testing.T) (*Data, *transactor) {
+	cfg := testDatabases(t)["sqlite"]
+	cfg.Database.Transaction.MaxRetries = 2
+	logger := util.NewLogger(cfg)
+	db, err := NewDB(cfg, logger)
+	require.NoError(t, err)
+	d, cleanup, err := NewData(db, logger)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return d, NewTransactor(cfg, d).(*transactor)
+}
+
+func TestWithinTx(t *testing.T) {
+	d, tx := newTransactorTestData(t)
+	users := NewUserRepo(d)
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	// 仓储从上下文中取得事务，事务内能读到未提交的数据，出错时全部回滚
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, users.CreateUser(ctx, &user.User{Username: "alice", Password: "x"}))
+		_, err := users.GetUserByUsername(ctx, "alice")
+		require.NoError(t, err)
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	_, err = users.GetUserByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 嵌套事务使用保存点，内层出错只回滚内层的修改
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, users.CreateUser(ctx, &user.User{Username: "alice", Password: "x"}))
+		inner := tx.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, users.CreateUser(ctx, &user.User{Username: "bob", Password: "x"}))
+			return errRollback
+		})
+		assert.ErrorIs(t, inner, errRollback)
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = users.GetUserByUsername(ctx, "alice")
+	assert.NoError(t, err)
+	_, err = users.GetUserByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestWithinTxRetry(t *testing.T) {
+	_, tx := newTransactorTestData(t)
+	ctx := context.Background()
+	deadlock := &pgconn.PgError{Code: "40P01"}
+
+	// 死锁时重试整个事务
+	attempts := 0
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// 超过重试次数后返回错误
+	attempts = 0
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 3, attempts)
+
+	// 其他错误和嵌套事务不重试，由最外层事务重试
+	attempts = 0
+	inner := 0
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return tx.WithinTx(ctx, func(ctx context.Context) error {
+			if inner++; inner == 1 {
+				return deadlock
+			}
+			return errors.New("not retryable")
+		})
+	})
+	assert.EqualError(t, err, "not retryable")
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, inner)
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	assert.True(t, isRetryableTxError(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, isRetryableTxError(fmt.Errorf("commit: %w", &mysqldriver.MySQLError{Number: 1213})))
+	assert.False(t, isRetryableTxError(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, isRetryableTxError(gorm.ErrDuplicatedKey))
+}
@@ -2,31 +2,36 @@ package data
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/HoronLee/GinHub/internal/model/user"
 	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/paging"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // userRepo 用户数据访问实现
 type userRepo struct {
-	data *Data
+	data  *Data
+	users *Repository[user.User, uint]
 }
 
 // NewUserRepo 创建UserRepo实例
 // 注意：返回的是 service.UserRepo 接口类型
 func NewUserRepo(data *Data) service.UserRepo {
 	return &userRepo{
-		data: data,
+		data:  data,
+		users: NewRepository[user.User, uint](data),
 	}
 }
 
 // CreateUser 创建用户记录
 func (r *userRepo) CreateUser(ctx context.Context, u *user.User) error {
 	r.data.log.Debug("Creating user", zap.String("username", u.Username))
-	err := r.data.db.WithContext(ctx).Create(u).Error
-	if err != nil {
-		r.data.log.Error("Failed to create user", zap.Error(err), zap.String("username", u.Username))
+	if err := r.users.Create(ctx, u); err != nil {
 		return err
 	}
 	r.data.log.Info("User created successfully", zap.String("username", u.Username), zap.Uint("id", u.ID))
@@ -37,7 +42,7 @@ func (r *userRepo) CreateUser(ctx context.Context, u *user.User) error {
 func (r *userRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
 	r.data.log.Debug("Getting user by username", zap.String("username", username))
 	var u user.User
-	err := r.data.db.WithContext(ctx).Where("username = ?", username).First(&u).Error
+	err := r.data.DB(ctx).Where("username = ?", username).First(&u).Error
 	if err != nil {
 		r.data.log.Debug("User not found", zap.String("username", username), zap.Error(err))
 		return nil, err
@@ -46,27 +51,228 @@ func (r *userRepo) GetUserByUsername(ctx context.Context, username string) (*use
 	return &u, nil
 }
 
+// GetUserByEmail 根据邮箱查询用户
+func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
+	r.data.log.Debug("Getting user by email", zap.String("email", email))
+	var u user.User
+	err := r.data.DB(ctx).Where("email = ?", email).First(&u).Error
+	if err != nil {
+		r.data.log.Debug("User not found", zap.String("email", email), zap.Error(err))
+		return nil, err
+	}
+	r.data.log.Debug("User found", zap.String("email", email), zap.Uint("id", u.ID))
+	return &u, nil
+}
+
 // GetUserByID 根据用户ID查询用户
 func (r *userRepo) GetUserByID(ctx context.Context, id uint) (*user.User, error) {
 	r.data.log.Debug("Getting user by ID", zap.Uint("id", id))
-	var u user.User
-	err := r.data.db.WithContext(ctx).First(&u, id).Error
+	u, err := r.users.Get(ctx, id)
 	if err != nil {
-		r.data.log.Debug("User not found", zap.Uint("id", id), zap.Error(err))
 		return nil, err
 	}
 	r.data.log.Debug("User found", zap.Uint("id", id), zap.String("username", u.Username))
-	return &u, nil
+	return u, nil
 }
 
-// DeleteUser 删除用户
-func (r *userRepo) DeleteUser(ctx context.Context, id uint) error {
-	r.data.log.Debug("Deleting user", zap.Uint("id", id))
-	err := r.data.db.WithContext(ctx).Delete(&user.User{}, id).Error
+// ListUsers 按条件分页查询用户并预加载角色
+func (r *userRepo) ListUsers(ctx context.Context, opts service.UserListOptions, params paging.Params) (*paging.Page[user.User], error) {
+	r.data.log.Debug("Listing users", zap.String("sort", paging.FormatSort(params.Sort)), zap.Int("page", params.Page), zap.Int("size", params.Limit()))
+	spec := ListSpec{Preload: []string{"Roles"}}
+	if opts.UsernamePrefix != "" {
+		spec.Where = append(spec.Where, clause.Expr{
+			SQL:  "? LIKE ? ESCAPE '!'",
+			Vars: []any{clause.Column{Table: clause.CurrentTable, Name: "username"}, escapeLike(opts.UsernamePrefix) + "%"},
+		})
+	}
+	now := time.Now()
+	switch opts.Status {
+	case user.StatusDisabled:
+		spec.Filters = append(spec.Filters, Filter{Field: "Status", Op: OpEq, Value: user.StatusDisabled})
+	case user.StatusActive:
+		lockedUntil := clause.Column{Table: clause.CurrentTable, Name: "locked_until"}
+		spec.Filters = append(spec.Filters, Filter{Field: "Status", Op: OpEq, Value: user.StatusActive})
+		spec.Where = append(spec.Where, clause.Or(clause.Eq{Column: lockedUntil, Value: nil}, clause.Lte{Column: lockedUntil, Value: now}))
+	case "locked":
+		spec.Filters = append(spec.Filters, Filter{Field: "LockedUntil", Op: OpGt, Value: now})
+	case "deleted":
+		spec.Unscoped = true
+		spec.Filters = append(spec.Filters, Filter{Field: "DeletedAt", Op: OpNe, Value: nil})
+	}
+	if opts.CreatedAfter != nil {
+		spec.Filters = append(spec.Filters, Filter{Field: "CreatedAt", Op: OpGte, Value: *opts.CreatedAfter})
+	}
+	if opts.CreatedBefore != nil {
+		spec.Filters = append(spec.Filters, Filter{Field: "CreatedAt", Op: OpLt, Value: *opts.CreatedBefore})
+	}
+	return r.users.Paginate(ctx, spec, params)
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+// 使用 ! 作为转义字符，反斜杠在 MySQL 字符串字面量中有特殊含义
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// UpdatePassword 更新用户密码哈希
+func (r *userRepo) UpdatePassword(ctx context.Context, id uint, hashedPassword string) error {
+	r.data.log.Debug("Updating user password", zap.Uint("id", id))
+	err := r.data.DB(ctx).Model(&user.User{}).Where("id = ?", id).Updates(map[string]any{
+		"password":                hashedPassword,
+		"password_reset_required": false,
+	}).Error
+	if err != nil {
+		r.data.log.Error("Failed to update user password", zap.Error(err), zap.Uint("id", id))
+		return err
+	}
+	r.data.log.Info("User password updated successfully", zap.Uint("id", id))
+	return nil
+}
+
+// UpdateUser 部分更新用户字段
+func (r *userRepo) UpdateUser(ctx context.Context, id uint, fields map[string]any) error {
+	r.data.log.Debug("Updating user", zap.Uint("id", id), zap.Int("fields", len(fields)))
+	if err := r.users.UpdateFields(ctx, id, fields); err != nil {
+		return err
+	}
+	r.data.log.Info("User updated successfully", zap.Uint("id", id))
+	return nil
+}
+
+// MarkEmailVerified 将用户邮箱标记为已验证
+// 通过 email 条件保证用户更换邮箱后旧的验证令牌不会生效
+func (r *userRepo) MarkEmailVerified(ctx context.Context, id uint, email string) error {
+	r.data.log.Debug("Marking user email verified", zap.Uint("id", id))
+	result := r.data.DB(ctx).Model(&user.User{}).
+		Where("id = ? AND email = ?", id, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		r.data.log.Error("Failed to mark user email verified", zap.Error(result.Error), zap.Uint("id", id))
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return gorm.ErrRecordNotFound
+	}
+	r.data.log.Info("User email verified", zap.Uint("id", id))
+	return nil
+}
+
+// UpdateLoginState 更新连续登录失败次数和锁定时间
+func (r *userRepo) UpdateLoginState(ctx context.Context, id uint, failedCount int, lockedUntil *time.Time) error {
+	r.data.log.Debug("Updating user login state", zap.Uint("id", id), zap.Int("failed_login_count", failedCount))
+	err := r.data.DB(ctx).Model(&user.User{}).Where("id = ?", id).Updates(map[string]any{
+		"failed_login_count": failedCount,
+		"locked_until":       lockedUntil,
+	}).Error
 	if err != nil {
-		r.data.log.Error("Failed to delete user", zap.Error(err), zap.Uint("id", id))
+		r.data.log.Error("Failed to update user login state", zap.Error(err), zap.Uint("id", id))
 		return err
 	}
+	if lockedUntil != nil {
+		r.data.log.Warn("User account locked", zap.Uint("id", id), zap.Time("locked_until", *lockedUntil))
+	}
+	return nil
+}
+
+// DeleteUser 软删除用户
+// 同时将 deleted_id 设为用户ID，释放用户名和邮箱的唯一索引；角色关联保留以便恢复
+func (r *userRepo) DeleteUser(ctx context.Context, id uint) error {
+	r.data.log.Debug("Deleting user", zap.Uint("id", id))
+	result := r.data.DB(ctx).Model(&user.User{}).Where("id = ?", id).Updates(map[string]any{
+		"deleted_at": time.Now(),
+		"deleted_id": gorm.Expr("id"),
+	})
+	if result.Error != nil {
+		r.data.log.Error("Failed to delete user", zap.Error(result.Error), zap.Uint("id", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
 	r.data.log.Info("User deleted successfully", zap.Uint("id", id))
 	return nil
 }
+
+// GetDeletedUserByID 根据用户ID查询已软删除的用户
+func (r *userRepo) GetDeletedUserByID(ctx context.Context, id uint) (*user.User, error) {
+	var u user.User
+	err := r.data.DB(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&u, id).Error
+	if err != nil {
+		r.data.log.Debug("Deleted user not found", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &u, nil
+}
+
+// RestoreUser 恢复已软删除的用户
+func (r *userRepo) RestoreUser(ctx context.Context, id uint) error {
+	r.data.log.Debug("Restoring user", zap.Uint("id", id))
+	result := r.data.DB(ctx).Unscoped().Model(&user.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{"deleted_at": nil, "deleted_id": 0})
+	if result.Error != nil {
+		r.data.log.Error("Failed to restore user", zap.Error(result.Error), zap.Uint("id", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.data.log.Info("User restored successfully", zap.Uint("id", id))
+	return nil
+}
+
+// purgeBatchSize 每次清理的最大用户数，剩余的用户在下一次清理时处理
+const purgeBatchSize = 100
+
+// PurgeDeletedUsers 彻底删除在 before 之前软删除的用户
+func (r *userRepo) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var ids []uint
+	err := r.data.DB(ctx).Unscoped().Model(&user.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Limit(purgeBatchSize).Pluck("id", &ids).Error
+	if err != nil {
+		r.data.log.Error("Failed to find deleted users", zap.Error(err))
+		return 0, err
+	}
+
+	var n int64
+	for _, id := range ids {
+		if err := r.purgeUser(ctx, id); err != nil {
+			r.data.log.Error("Failed to purge user", zap.Error(err), zap.Uint("id", id))
+			return n, err
+		}
+		n++
+	}
+	if n > 0 {
+		r.data.log.Info("Deleted users purged", zap.Int64("count", n))
+	}
+	return n, nil
+}
+
+// purgeUser 在事务中彻底删除用户
+// 令牌、API Key、恢复码、会话和外部身份等属于用户的记录随用户一起删除；
+// 用户创建的 OAuth 客户端仍在使用，保留客户端并将创建者置为 0。
+// 登录失败计数以用户名为键且会自动过期，不做处理。新增引用用户的表时需要在此删除或匿名化相关记录
+func (r *userRepo) purgeUser(ctx context.Context, id uint) error {
+	return r.data.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{
+			&user.RefreshToken{},
+			&user.APIKey{},
+			&user.RecoveryCode{},
+			&user.PasswordResetToken{},
+			&user.EmailVerificationToken{},
+			&user.Session{},
+			&user.OAuthAuthorizationCode{},
+			&user.Identity{},
+		} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user.OAuthClient{}).Where("created_by = ?", id).Update("created_by", 0).Error; err != nil {
+			return err
+		}
+		// 同时删除用户与角色的关联记录
+		return tx.Unscoped().Select("Roles").Delete(&user.User{ID: id}).Error
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/HoronLee/GinHub/internal/config"
 	"github.com/HoronLee/GinHub/internal/data"
 	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/HoronLee/GinHub/internal/middleware"
 	"github.com/HoronLee/GinHub/internal/server"
 	"github.com/HoronLee/GinHub/internal/service"
 	util "github.com/HoronLee/GinHub/internal/util/log"
@@ -20,6 +21,9 @@ func InitServer(cfg *config.AppConfig) (*server.HTTPServer, func(), error) {
 		data.ProviderSet,
 		service.ProviderSet,
 		handler.ProviderSet,
+		middleware.ProviderSet,
+		wire.Bind(new(middleware.APIKeyValidator), new(*service.APIKeyService)),
+		wire.Bind(new(middleware.SessionTracker), new(*service.SessionService)),
 		server.ProviderSet,
 	)
 	return nil, nil, nil
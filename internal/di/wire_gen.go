@@ -10,6 +10,7 @@ import (
 	"github.com/HoronLee/GinHub/internal/config"
 	"github.com/HoronLee/GinHub/internal/data"
 	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/HoronLee/GinHub/internal/middleware"
 	"github.com/HoronLee/GinHub/internal/server"
 	"github.com/HoronLee/GinHub/internal/service"
 	"github.com/HoronLee/GinHub/internal/util/log"
@@ -32,11 +33,79 @@ func InitServer(cfg *config.AppConfig) (*server.HTTPServer, func(), error) {
 	helloWorldService := service.NewHelloWorldService(helloWorldRepo)
 	helloWorldHandler := handler.NewHelloWorldHandler(helloWorldService)
 	userRepo := data.NewUserRepo(dataData)
-	userService := service.NewUserService(userRepo)
+	roleRepo := data.NewRoleRepo(dataData)
+	apiKeyRepo := data.NewAPIKeyRepo(dataData)
+	transactor := data.NewTransactor(cfg, dataData)
+	passwordHasher, err := service.NewPasswordHasher(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	refreshTokenRepo := data.NewRefreshTokenRepo(dataData)
+	sessionRepo := data.NewSessionRepo(dataData)
+	revocationStore, cleanup2, err := data.NewRevocationStore(cfg, dataData)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	jwt, err := service.NewJWT(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	tokenService := service.NewTokenService(refreshTokenRepo, sessionRepo, userRepo, roleRepo, revocationStore, jwt)
+	mfaRepo := data.NewMFARepo(dataData)
+	loginAttemptStore, cleanup3, err := data.NewLoginAttemptStore(cfg, dataData)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	loginThrottle := service.NewLoginThrottle(cfg, loginAttemptStore, userRepo)
+	mfaService := service.NewMFAService(cfg, mfaRepo, userRepo, tokenService, revocationStore, loginThrottle, jwt)
+	emailVerificationRepo := data.NewEmailVerificationRepo(dataData)
+	mailer, err := service.NewMailer(cfg, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	emailVerificationService := service.NewEmailVerificationService(cfg, emailVerificationRepo, userRepo, loginAttemptStore, mailer)
+	userService := service.NewUserService(userRepo, roleRepo, apiKeyRepo, transactor, passwordHasher, tokenService, mfaService, loginThrottle, emailVerificationService)
 	userHandler := handler.NewUserHandler(userService)
-	handlers := handler.NewHandlers(helloWorldHandler, userHandler)
-	httpServer := server.NewHTTPServer(cfg, handlers, db, logger)
+	tokenHandler := handler.NewTokenHandler(tokenService)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	roleHandler := handler.NewRoleHandler(roleService)
+	apiKeyService := service.NewAPIKeyService(cfg, apiKeyRepo, userRepo, roleRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	passwordResetRepo := data.NewPasswordResetRepo(dataData)
+	passwordResetService := service.NewPasswordResetService(cfg, passwordResetRepo, userRepo, roleRepo, apiKeyRepo, passwordHasher, tokenService, loginThrottle, loginAttemptStore, mailer)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService)
+	sessionService := service.NewSessionService(cfg, sessionRepo, tokenService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	oAuthRepo := data.NewOAuthRepo(dataData)
+	oAuthService := service.NewOAuthService(cfg, oAuthRepo, userRepo, revocationStore, jwt)
+	oAuthHandler := handler.NewOAuthHandler(oAuthService)
+	identityRepo := data.NewIdentityRepo(dataData)
+	ssoService := service.NewSSOService(cfg, identityRepo, userRepo, roleRepo, transactor, tokenService, mfaService, emailVerificationService, revocationStore, jwt)
+	ssoHandler := handler.NewSSOHandler(ssoService)
+	handlers := handler.NewHandlers(helloWorldHandler, userHandler, tokenHandler, roleHandler, apiKeyHandler, mfaHandler, passwordResetHandler, emailVerificationHandler, sessionHandler, oAuthHandler, ssoHandler)
+	authenticator, err := middleware.NewAuthenticator(cfg, jwt, revocationStore, apiKeyService, sessionService)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	userPurger := service.NewUserPurger(cfg, userRepo)
+	httpServer := server.NewHTTPServer(cfg, handlers, db, logger, authenticator, userPurger)
 	return httpServer, func() {
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
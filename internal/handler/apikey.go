@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler API Key 处理器
+type APIKeyHandler struct {
+	svc *service.APIKeyService
+}
+
+// NewAPIKeyHandler 创建APIKeyHandler实例
+func NewAPIKeyHandler(svc *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		svc: svc,
+	}
+}
+
+// CreateAPIKey 创建 API Key 处理器
+// @Summary 创建 API Key
+// @Description 为当前用户创建 API Key，完整密钥只在本次响应中返回，请妥善保存；请求时通过 Authorization: ApiKey <key> 或 X-API-Key 请求头携带
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.CreateAPIKeyRequest true "创建 API Key 请求参数"
+// @Success 200 {object} response.Response{data=user.CreateAPIKeyResponse} "创建成功，返回完整密钥"
+// @Failure 400 {object} response.Response "请求参数错误或创建失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.CreateAPIKeyRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.CreateAPIKey(ctx.Request.Context(), claims, req)
+		if err != nil {
+			return res.Response{Msg: "Failed to create API key", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// ListAPIKeys 查询 API Key 列表处理器
+// @Summary 查询 API Key 列表
+// @Description 查询当前用户未吊销的 API Key，不包含密钥本身
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]user.APIKeyResponse} "查询成功"
+// @Failure 400 {object} response.Response "查询失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		keys, err := h.svc.ListAPIKeys(ctx.Request.Context(), claims.UserID)
+		if err != nil {
+			return res.Response{Msg: "Failed to list API keys", Err: err}
+		}
+
+		return res.Response{
+			Data: keys,
+			Msg:  "success",
+		}
+	})
+}
+
+// RevokeAPIKey 吊销 API Key 处理器
+// @Summary 吊销 API Key
+// @Description 吊销当前用户的指定 API Key，吊销后立即失效
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Success 200 {object} response.Response{data=map[string]string} "吊销成功"
+// @Failure 400 {object} response.Response "请求参数错误或吊销失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		id, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid API key ID", Err: err}
+		}
+
+		if err := h.svc.RevokeAPIKey(ctx.Request.Context(), claims.UserID, id); err != nil {
+			return res.Response{Msg: "Failed to revoke API key", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "API key revoked successfully"},
+			Msg:  "success",
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationHandler 邮箱验证处理器
+type EmailVerificationHandler struct {
+	svc *service.EmailVerificationService
+}
+
+// NewEmailVerificationHandler 创建EmailVerificationHandler实例
+func NewEmailVerificationHandler(svc *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		svc: svc,
+	}
+}
+
+// VerifyEmail 验证邮箱处理器
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌确认邮箱地址，令牌只能使用一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body user.VerifyEmailRequest true "验证邮箱请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "验证成功"
+// @Failure 400 {object} response.Response "请求参数错误或验证令牌无效"
+// @Router /user/verify-email [post]
+func (h *EmailVerificationHandler) VerifyEmail() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.VerifyEmailRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.Verify(ctx.Request.Context(), req.Token); err != nil {
+			return res.Response{Msg: "Failed to verify email", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Email verified successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// ResendVerification 重新发送验证邮件处理器
+// @Summary 重新发送验证邮件
+// @Description 向未验证的邮箱重新发送验证邮件，之前的验证令牌失效；无论邮箱是否存在都返回成功，同一账户和同一IP每小时的请求次数有限，超过后不再发送邮件
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body user.ResendVerificationRequest true "重新发送验证邮件请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "请求已受理"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /user/verify-email/resend [post]
+func (h *EmailVerificationHandler) ResendVerification() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.ResendVerificationRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.Resend(ctx.Request.Context(), req.Email); err != nil {
+			return res.Response{Msg: "Failed to resend verification email", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "If the email is registered and unverified, a verification email has been sent"},
+			Msg:  "success",
+		}
+	})
+}
@@ -3,18 +3,48 @@ package handler
 import "github.com/google/wire"
 
 // ProviderSet is handler providers.
-var ProviderSet = wire.NewSet(NewHandlers, NewHelloWorldHandler, NewUserHandler)
+var ProviderSet = wire.NewSet(NewHandlers, NewHelloWorldHandler, NewUserHandler, NewTokenHandler, NewRoleHandler, NewAPIKeyHandler, NewMFAHandler, NewPasswordResetHandler, NewEmailVerificationHandler, NewSessionHandler, NewOAuthHandler, NewSSOHandler)
 
 // Handlers 聚合各个模块的Handler
 type Handlers struct {
-	HelloWorldHandler *HelloWorldHandler
-	UserHandler       *UserHandler
+	HelloWorldHandler        *HelloWorldHandler
+	UserHandler              *UserHandler
+	TokenHandler             *TokenHandler
+	RoleHandler              *RoleHandler
+	APIKeyHandler            *APIKeyHandler
+	MFAHandler               *MFAHandler
+	PasswordResetHandler     *PasswordResetHandler
+	EmailVerificationHandler *EmailVerificationHandler
+	SessionHandler           *SessionHandler
+	OAuthHandler             *OAuthHandler
+	SSOHandler               *SSOHandler
 }
 
 // NewHandlers 创建Handlers实例
-func NewHandlers(hwHandler *HelloWorldHandler, userHandler *UserHandler) *Handlers {
+func NewHandlers(
+	hwHandler *HelloWorldHandler,
+	userHandler *UserHandler,
+	tokenHandler *TokenHandler,
+	roleHandler *RoleHandler,
+	apiKeyHandler *APIKeyHandler,
+	mfaHandler *MFAHandler,
+	passwordResetHandler *PasswordResetHandler,
+	emailVerificationHandler *EmailVerificationHandler,
+	sessionHandler *SessionHandler,
+	oauthHandler *OAuthHandler,
+	ssoHandler *SSOHandler,
+) *Handlers {
 	return &Handlers{
-		HelloWorldHandler: hwHandler,
-		UserHandler:       userHandler,
+		HelloWorldHandler:        hwHandler,
+		UserHandler:              userHandler,
+		TokenHandler:             tokenHandler,
+		RoleHandler:              roleHandler,
+		APIKeyHandler:            apiKeyHandler,
+		MFAHandler:               mfaHandler,
+		PasswordResetHandler:     passwordResetHandler,
+		EmailVerificationHandler: emailVerificationHandler,
+		SessionHandler:           sessionHandler,
+		OAuthHandler:             oauthHandler,
+		SSOHandler:               ssoHandler,
 	}
 }
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler 两步验证处理器
+type MFAHandler struct {
+	svc *service.MFAService
+}
+
+// NewMFAHandler 创建MFAHandler实例
+func NewMFAHandler(svc *service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		svc: svc,
+	}
+}
+
+// LoginMFA 两步验证登录处理器
+// @Summary 两步验证登录
+// @Description 使用登录接口返回的挑战令牌和身份验证器中的验证码（或恢复码）换取访问令牌和刷新令牌
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param request body user.MFALoginRequest true "两步验证登录请求参数"
+// @Success 200 {object} response.Response{data=user.LoginResponse} "登录成功，返回令牌对"
+// @Failure 400 {object} response.Response "请求参数错误、挑战令牌无效或验证码错误；失败次数过多时返回 Too many login attempts 并设置 Retry-After 响应头"
+// @Router /login/mfa [post]
+func (h *MFAHandler) LoginMFA() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.MFALoginRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.VerifyLogin(ctx.Request.Context(), req.MFAToken, req.Code)
+		if err != nil {
+			var throttled *service.ThrottledError
+			if errors.As(err, &throttled) {
+				ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+				return res.Response{Msg: "Too many login attempts", Err: err}
+			}
+			return res.Response{Msg: "Two-factor verification failed", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// SetupTOTP 绑定身份验证器处理器
+// @Summary 绑定身份验证器
+// @Description 生成新的 TOTP 共享密钥和 otpauth URI，需调用确认接口后才会开启两步验证
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=user.TOTPSetupResponse} "生成成功"
+// @Failure 400 {object} response.Response "已开启两步验证或生成失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/2fa/setup [post]
+func (h *MFAHandler) SetupTOTP() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		resp, err := h.svc.Setup(ctx.Request.Context(), claims.UserID)
+		if err != nil {
+			return res.Response{Msg: "Failed to set up two-factor authentication", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// ConfirmTOTP 确认绑定身份验证器处理器
+// @Summary 确认绑定身份验证器
+// @Description 提交身份验证器中的验证码确认绑定并开启两步验证，返回的恢复码只显示一次
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.TOTPCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=user.RecoveryCodesResponse} "开启成功，返回恢复码"
+// @Failure 400 {object} response.Response "请求参数错误或验证码错误"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/2fa/confirm [post]
+func (h *MFAHandler) ConfirmTOTP() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.TOTPCodeRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.Confirm(ctx.Request.Context(), claims.UserID, req.Code)
+		if err != nil {
+			return res.Response{Msg: "Failed to enable two-factor authentication", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// DisableTOTP 关闭两步验证处理器
+// @Summary 关闭两步验证
+// @Description 提交当前有效的验证码关闭两步验证，同时删除全部恢复码
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.TOTPCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=map[string]string} "关闭成功"
+// @Failure 400 {object} response.Response "请求参数错误或验证码错误"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/2fa/disable [post]
+func (h *MFAHandler) DisableTOTP() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.TOTPCodeRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.Disable(ctx.Request.Context(), claims.UserID, req.Code); err != nil {
+			return res.Response{Msg: "Failed to disable two-factor authentication", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Two-factor authentication disabled"},
+			Msg:  "success",
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	commonModel "github.com/HoronLee/GinHub/internal/model/common"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	errorUtil "github.com/HoronLee/GinHub/internal/util/err"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// consentTemplate 授权确认页面
+var consentTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Authorize {{.ClientName}} - GinHub</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f6f8fa; margin: 0; }
+main { max-width: 420px; margin: 10vh auto; background: #fff; border: 1px solid #d0d7de; border-radius: 8px; padding: 24px; }
+h1 { font-size: 20px; margin-top: 0; }
+ul { padding-left: 20px; }
+.actions { display: flex; gap: 12px; margin-top: 24px; }
+button { flex: 1; padding: 8px; border-radius: 6px; border: 1px solid #d0d7de; background: #f6f8fa; cursor: pointer; }
+button[value=approve] { background: #1f883d; border-color: #1f883d; color: #fff; }
+</style>
+</head>
+<body>
+<main>
+{{- if .Error}}
+<h1>Authorization failed</h1>
+<p>{{.Error}}</p>
+{{- else if .LoginRequired}}
+<h1>Sign in required</h1>
+<p>Sign in to GinHub, then return to {{.ClientName}} to continue.</p>
+{{- else}}
+<h1>Authorize {{.ClientName}}</h1>
+<p>{{.ClientName}} wants to access your GinHub account <strong>{{.Username}}</strong>:</p>
+<ul>
+{{- range .Scopes}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="consent" value="{{.ConsentToken}}">
+<div class="actions">
+<button type="submit" name="decision" value="deny">Deny</button>
+<button type="submit" name="decision" value="approve">Authorize</button>
+</div>
+</form>
+{{- end}}
+</main>
+</body>
+</html>
+`))
+
+// consentPage 授权确认页面数据
+type consentPage struct {
+	ClientName    string
+	Username      string
+	Scopes        []string
+	ConsentToken  string
+	LoginRequired bool
+	Error         string
+}
+
+// OAuthHandler OAuth2 授权服务器处理器
+// 协议端点遵循 RFC 6749 等规范的请求和响应格式，不使用统一响应包装，也不出现在 Swagger 文档中
+type OAuthHandler struct {
+	svc *service.OAuthService
+}
+
+// NewOAuthHandler 创建OAuthHandler实例
+func NewOAuthHandler(svc *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		svc: svc,
+	}
+}
+
+// Authorize 授权端点处理器
+// 校验授权请求后展示授权确认页面；用户未登录时跳转到配置的登录页面，或提示用户先登录
+func (h *OAuthHandler) Authorize() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req user.AuthorizeRequest
+		_ = ctx.ShouldBindQuery(&req)
+
+		consent, err := h.svc.ValidateAuthorize(ctx.Request.Context(), req)
+		if err != nil {
+			var oauthErr *service.OAuthError
+			if errors.As(err, &oauthErr) {
+				ctx.Redirect(http.StatusFound, h.svc.ErrorRedirect(req.RedirectURI, req.State, oauthErr))
+				return
+			}
+			renderAuthorizeError(ctx, err)
+			return
+		}
+
+		// 只接受登录会话签发的访问令牌，API Key 不能用于授权第三方应用
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok || claims.SessionID == "" {
+			if loginURL := h.svc.LoginRedirect(ctx.Request.URL.RequestURI()); loginURL != "" {
+				ctx.Redirect(http.StatusFound, loginURL)
+				return
+			}
+			renderConsentPage(ctx, http.StatusUnauthorized, consentPage{
+				ClientName:    consent.Client.Name,
+				LoginRequired: true,
+			})
+			return
+		}
+
+		token, err := h.svc.ConsentToken(consent, claims.UserID)
+		if err != nil {
+			renderAuthorizeError(ctx, err)
+			return
+		}
+		renderConsentPage(ctx, http.StatusOK, consentPage{
+			ClientName:   consent.Client.Name,
+			Username:     claims.Username,
+			Scopes:       consent.Scopes,
+			ConsentToken: token,
+		})
+	}
+}
+
+// Approve 授权确认处理器
+// 用户提交授权确认页面后重定向回客户端，同意时携带授权码，拒绝时携带 access_denied 错误
+func (h *OAuthHandler) Approve() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			renderConsentPage(ctx, http.StatusUnauthorized, consentPage{Error: "Your GinHub session has expired. Sign in and try again."})
+			return
+		}
+
+		redirect, err := h.svc.Approve(ctx.Request.Context(), claims, ctx.PostForm("consent"), ctx.PostForm("decision") == "approve")
+		if err != nil {
+			renderAuthorizeError(ctx, err)
+			return
+		}
+		ctx.Redirect(http.StatusSeeOther, redirect)
+	}
+}
+
+// Token 令牌端点处理器
+// 客户端凭证可以通过 HTTP Basic 认证（client_secret_basic）或表单参数（client_secret_post）提供
+func (h *OAuthHandler) Token() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req user.OAuthTokenRequest
+		_ = ctx.ShouldBind(&req)
+		basic := bindBasicAuth(ctx, &req.ClientID, &req.ClientSecret)
+
+		resp, err := h.svc.Exchange(ctx.Request.Context(), req)
+		ctx.Header("Cache-Control", "no-store")
+		ctx.Header("Pragma", "no-cache")
+		if err != nil {
+			writeOAuthError(ctx, err, basic)
+			return
+		}
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// Introspect 令牌自省端点处理器（RFC 7662）
+func (h *OAuthHandler) Introspect() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clientID, clientSecret := ctx.PostForm("client_id"), ctx.PostForm("client_secret")
+		basic := bindBasicAuth(ctx, &clientID, &clientSecret)
+
+		resp, err := h.svc.Introspect(ctx.Request.Context(), clientID, clientSecret, ctx.PostForm("token"))
+		if err != nil {
+			writeOAuthError(ctx, err, basic)
+			return
+		}
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// Revoke 令牌吊销端点处理器（RFC 7009）
+func (h *OAuthHandler) Revoke() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clientID, clientSecret := ctx.PostForm("client_id"), ctx.PostForm("client_secret")
+		basic := bindBasicAuth(ctx, &clientID, &clientSecret)
+
+		if err := h.svc.Revoke(ctx.Request.Context(), clientID, clientSecret, ctx.PostForm("token")); err != nil {
+			writeOAuthError(ctx, err, basic)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// UserInfo OpenID Connect UserInfo 端点处理器，访问令牌通过 Authorization: Bearer 请求头提供
+func (h *OAuthHandler) UserInfo() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, _ := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		resp, err := h.svc.UserInfo(ctx.Request.Context(), token)
+		if err != nil {
+			var oauthErr *service.OAuthError
+			if !errors.As(err, &oauthErr) {
+				writeOAuthError(ctx, err, false)
+				return
+			}
+			status := http.StatusUnauthorized
+			if oauthErr.Code == service.OAuthErrInsufficientScope {
+				status = http.StatusForbidden
+			}
+			ctx.Header("WWW-Authenticate",
+				`Bearer realm="ginhub", error="`+oauthErr.Code+`", error_description="`+oauthErr.Description+`"`)
+			ctx.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+			return
+		}
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// Discovery OpenID Connect 发现文档处理器
+func (h *OAuthHandler) Discovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, h.svc.Discovery())
+	}
+}
+
+// bindBasicAuth 使用 HTTP Basic 认证中的客户端凭证覆盖表单参数，返回请求是否使用了 Basic 认证
+// 按 RFC 6749 2.3.1，用户名和密码在 Basic 编码前经过了表单 URL 编码
+func bindBasicAuth(ctx *gin.Context, clientID, clientSecret *string) bool {
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return false
+	}
+	*clientID, *clientSecret = username, password
+	if v, err := url.QueryUnescape(username); err == nil {
+		*clientID = v
+	}
+	if v, err := url.QueryUnescape(password); err == nil {
+		*clientSecret = v
+	}
+	return true
+}
+
+// writeOAuthError 按 RFC 6749 5.2 返回错误，客户端认证失败时返回 401
+func writeOAuthError(ctx *gin.Context, err error, basic bool) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		errorUtil.HandleError(&commonModel.ServerError{Msg: "OAuth request failed", Err: err})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": "internal server error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == service.OAuthErrInvalidClient {
+		status = http.StatusUnauthorized
+		if basic {
+			ctx.Header("WWW-Authenticate", `Basic realm="ginhub"`)
+		}
+	}
+	ctx.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
+
+// renderAuthorizeError 展示不能重定向回客户端的授权错误
+func renderAuthorizeError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOAuthClient):
+		renderConsentPage(ctx, http.StatusBadRequest, consentPage{Error: "The application is not registered with GinHub."})
+	case errors.Is(err, service.ErrInvalidRedirectURI):
+		renderConsentPage(ctx, http.StatusBadRequest, consentPage{Error: "The redirect URI is missing or not registered for this application."})
+	case errors.Is(err, service.ErrInvalidConsent):
+		renderConsentPage(ctx, http.StatusBadRequest, consentPage{Error: "This authorization request has expired or was already answered. Return to the application and try again."})
+	default:
+		errorUtil.HandleError(&commonModel.ServerError{Msg: "OAuth authorization failed", Err: err})
+		renderConsentPage(ctx, http.StatusInternalServerError, consentPage{Error: "Something went wrong. Please try again later."})
+	}
+}
+
+// renderConsentPage 渲染授权确认页面，禁止缓存和被嵌入其他页面
+func renderConsentPage(ctx *gin.Context, status int, page consentPage) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Frame-Options", "DENY")
+	// 不限制 form-action：提交确认后需要重定向到客户端的回调地址
+	ctx.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(status)
+	if err := consentTemplate.Execute(ctx.Writer, page); err != nil {
+		errorUtil.HandleError(&commonModel.ServerError{Msg: "Failed to render consent page", Err: err})
+	}
+}
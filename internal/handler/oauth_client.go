@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOAuthClient 注册 OAuth2 客户端处理器
+// @Summary 注册 OAuth2 客户端
+// @Description 注册第三方应用，机密客户端的密钥只在本次响应中返回；公开客户端没有密钥，只能使用带 PKCE 的授权码模式。需要 oauth:manage 权限
+// @Tags OAuth2 客户端
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.CreateOAuthClientRequest true "注册客户端请求参数"
+// @Success 200 {object} response.Response{data=user.CreateOAuthClientResponse} "注册成功"
+// @Failure 400 {object} response.Response "请求参数错误或注册失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/oauth/clients [post]
+func (h *OAuthHandler) CreateOAuthClient() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.CreateOAuthClientRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.CreateClient(ctx.Request.Context(), claims.UserID, req)
+		if err != nil {
+			return res.Response{Msg: "Failed to create OAuth client", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// ListOAuthClients 查询 OAuth2 客户端列表处理器
+// @Summary 查询 OAuth2 客户端列表
+// @Description 查询所有已注册的第三方应用，不包含客户端密钥。需要 oauth:manage 权限
+// @Tags OAuth2 客户端
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]user.OAuthClientResponse} "查询成功"
+// @Failure 400 {object} response.Response "查询失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/oauth/clients [get]
+func (h *OAuthHandler) ListOAuthClients() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		clients, err := h.svc.ListClients(ctx.Request.Context())
+		if err != nil {
+			return res.Response{Msg: "Failed to list OAuth clients", Err: err}
+		}
+
+		return res.Response{
+			Data: clients,
+			Msg:  "success",
+		}
+	})
+}
+
+// DeleteOAuthClient 删除 OAuth2 客户端处理器
+// @Summary 删除 OAuth2 客户端
+// @Description 删除指定的第三方应用，已签发给该应用的访问令牌随即失效。需要 oauth:manage 权限
+// @Tags OAuth2 客户端
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "客户端ID"
+// @Success 200 {object} response.Response{data=map[string]string} "删除成功"
+// @Failure 400 {object} response.Response "请求参数错误或删除失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/oauth/clients/{id} [delete]
+func (h *OAuthHandler) DeleteOAuthClient() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid OAuth client ID", Err: err}
+		}
+
+		if err := h.svc.DeleteClient(ctx.Request.Context(), id); err != nil {
+			if errors.Is(err, service.ErrOAuthClientNotFound) {
+				return res.Response{Msg: "OAuth client not found", Err: err}
+			}
+			return res.Response{Msg: "Failed to delete OAuth client", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "OAuth client deleted successfully"},
+			Msg:  "success",
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordResetHandler 找回密码处理器
+type PasswordResetHandler struct {
+	svc *service.PasswordResetService
+}
+
+// NewPasswordResetHandler 创建PasswordResetHandler实例
+func NewPasswordResetHandler(svc *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		svc: svc,
+	}
+}
+
+// ForgotPassword 申请重置密码处理器
+// @Summary 申请重置密码
+// @Description 向用户邮箱发送一次性的密码重置令牌；无论用户是否存在都返回成功，同一账户和同一IP每小时的申请次数有限，超过后不再发送邮件
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body user.ForgotPasswordRequest true "申请重置密码请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "申请已受理"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /password/forgot [post]
+func (h *PasswordResetHandler) ForgotPassword() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.ForgotPasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.RequestReset(ctx.Request.Context(), req); err != nil {
+			return res.Response{Msg: "Failed to request password reset", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "If the account exists, a reset email has been sent"},
+			Msg:  "success",
+		}
+	})
+}
+
+// ResetPassword 重置密码处理器
+// @Summary 重置密码
+// @Description 使用邮件中的重置令牌设置新密码，令牌只能使用一次；重置后该用户的所有会话需要重新登录，全部 API Key 被吊销
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body user.ResetPasswordRequest true "重置密码请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "重置成功"
+// @Failure 400 {object} response.Response "请求参数错误或重置令牌无效"
+// @Router /password/reset [post]
+func (h *PasswordResetHandler) ResetPassword() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.ResetPasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.ResetPassword(ctx.Request.Context(), req); err != nil {
+			return res.Response{Msg: "Failed to reset password", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Password reset successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// ForceReset 要求用户重置密码处理器
+// @Summary 要求用户重置密码
+// @Description 吊销指定用户的所有会话并发送重置邮件，用户通过邮件设置新密码前不能登录，也不能使用 API Key。用户必须设置了邮箱，不能要求最后一个管理员重置密码，需要 user:update 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "操作成功"
+// @Failure 400 {object} response.Response "请求参数错误、用户未设置邮箱或操作失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/password-reset [post]
+func (h *PasswordResetHandler) ForceReset() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		if err := h.svc.ForceReset(ctx.Request.Context(), userID); err != nil {
+			if errors.Is(err, service.ErrNoEmail) {
+				return res.Response{Msg: "User has no email address to receive the reset link", Err: err}
+			}
+			return res.Response{Msg: "Failed to force password reset", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Password reset required"},
+			Msg:  "success",
+		}
+	})
+}
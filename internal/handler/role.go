@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色处理器
+type RoleHandler struct {
+	svc *service.RoleService
+}
+
+// NewRoleHandler 创建RoleHandler实例
+func NewRoleHandler(svc *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		svc: svc,
+	}
+}
+
+// ListRoles 查询角色列表处理器
+// @Summary 查询角色列表
+// @Description 查询所有角色及其权限，需要管理员角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]user.RoleResponse} "查询成功"
+// @Failure 400 {object} response.Response "查询失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		roles, err := h.svc.ListRoles(ctx.Request.Context())
+		if err != nil {
+			return res.Response{Msg: "Failed to list roles", Err: err}
+		}
+
+		return res.Response{
+			Data: roles,
+			Msg:  "success",
+		}
+	})
+}
+
+// CreateRole 创建角色处理器
+// @Summary 创建角色
+// @Description 创建新角色并为其指定权限，需要管理员角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.CreateRoleRequest true "创建角色请求参数"
+// @Success 200 {object} response.Response{data=user.RoleResponse} "创建成功"
+// @Failure 400 {object} response.Response "请求参数错误或创建失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.CreateRoleRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		role, err := h.svc.CreateRole(ctx.Request.Context(), req)
+		if err != nil {
+			return res.Response{Msg: "Failed to create role", Err: err}
+		}
+
+		return res.Response{
+			Data: role,
+			Msg:  "success",
+		}
+	})
+}
+
+// GetUserRoles 查询用户角色处理器
+// @Summary 查询用户角色
+// @Description 查询指定用户拥有的角色，需要管理员角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=[]user.RoleResponse} "查询成功"
+// @Failure 400 {object} response.Response "请求参数错误或查询失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		roles, err := h.svc.GetUserRoles(ctx.Request.Context(), userID)
+		if err != nil {
+			return res.Response{Msg: "Failed to get user roles", Err: err}
+		}
+
+		return res.Response{
+			Data: roles,
+			Msg:  "success",
+		}
+	})
+}
+
+// AssignRole 分配角色处理器
+// @Summary 分配角色
+// @Description 为指定用户分配角色，用户重新登录或刷新令牌后生效，需要管理员角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body user.AssignRoleRequest true "分配角色请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "分配成功"
+// @Failure 400 {object} response.Response "请求参数错误或分配失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/roles [post]
+func (h *RoleHandler) AssignRole() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		var req user.AssignRoleRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.AssignRole(ctx.Request.Context(), userID, req.Role); err != nil {
+			return res.Response{Msg: "Failed to assign role", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Role assigned successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// RemoveRole 移除角色处理器
+// @Summary 移除角色
+// @Description 移除指定用户的角色，不能移除最后一个管理员，需要管理员角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param role path string true "角色名称"
+// @Success 200 {object} response.Response{data=map[string]string} "移除成功"
+// @Failure 400 {object} response.Response "请求参数错误或移除失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *RoleHandler) RemoveRole() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		if err := h.svc.RemoveRole(ctx.Request.Context(), userID, ctx.Param("role")); err != nil {
+			return res.Response{Msg: "Failed to remove role", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Role removed successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// parseUintParam 解析无符号整数路径参数
+func parseUintParam(ctx *gin.Context, name string) (uint, error) {
+	v, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(v), nil
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// otherSessions 吊销会话接口中表示除当前会话以外所有会话的路径参数
+const otherSessions = "others"
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	svc *service.SessionService
+}
+
+// NewSessionHandler 创建SessionHandler实例
+func NewSessionHandler(svc *service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		svc: svc,
+	}
+}
+
+// ListSessions 查询登录会话列表处理器
+// @Summary 查询登录会话列表
+// @Description 查询当前用户未吊销且未过期的登录会话，current 标记发起请求的会话
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]user.SessionResponse} "查询成功"
+// @Failure 400 {object} response.Response "查询失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/sessions [get]
+func (h *SessionHandler) ListSessions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		sessions, err := h.svc.ListSessions(ctx.Request.Context(), claims)
+		if err != nil {
+			return res.Response{Msg: "Failed to list sessions", Err: err}
+		}
+
+		return res.Response{
+			Data: sessions,
+			Msg:  "success",
+		}
+	})
+}
+
+// RevokeSession 吊销登录会话处理器
+// @Summary 吊销登录会话
+// @Description 吊销当前用户的指定会话，id 为 others 时吊销除当前会话以外的所有会话；会话内的访问令牌和刷新令牌立即失效
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "会话 ID 或 others"
+// @Success 200 {object} response.Response{data=map[string]string} "吊销成功"
+// @Failure 400 {object} response.Response "请求参数错误或吊销失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		if ctx.Param("id") == otherSessions {
+			if err := h.svc.RevokeOtherSessions(ctx.Request.Context(), claims); err != nil {
+				return res.Response{Msg: "Failed to revoke sessions", Err: err}
+			}
+			return res.Response{
+				Data: gin.H{"message": "Other sessions revoked successfully"},
+				Msg:  "success",
+			}
+		}
+
+		id, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid session ID", Err: err}
+		}
+
+		if err := h.svc.RevokeSession(ctx.Request.Context(), claims, id); err != nil {
+			if errors.Is(err, service.ErrSessionNotFound) {
+				return res.Response{Msg: "Session not found", Err: err}
+			}
+			return res.Response{Msg: "Failed to revoke session", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Session revoked successfully"},
+			Msg:  "success",
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	commonModel "github.com/HoronLee/GinHub/internal/model/common"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	errorUtil "github.com/HoronLee/GinHub/internal/util/err"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ssoStateCookie 保存外部登录状态令牌的 Cookie 名称
+	ssoStateCookie = "ginhub_sso_state"
+	// ssoStateCookiePath 状态 Cookie 只在登录和回调地址中发送
+	ssoStateCookiePath = "/api/v1/auth"
+)
+
+// SSOHandler 外部身份登录处理器
+type SSOHandler struct {
+	svc *service.SSOService
+}
+
+// NewSSOHandler 创建SSOHandler实例
+func NewSSOHandler(svc *service.SSOService) *SSOHandler {
+	return &SSOHandler{
+		svc: svc,
+	}
+}
+
+// ListProviders 查询外部身份提供方处理器
+// @Summary 查询外部身份提供方
+// @Description 查询已配置的外部身份提供方标识，用于展示第三方登录入口
+// @Tags 外部登录
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]string} "查询成功"
+// @Router /auth/providers [get]
+func (h *SSOHandler) ListProviders() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		return res.Response{
+			Data: h.svc.Providers(),
+			Msg:  "success",
+		}
+	})
+}
+
+// Login 外部登录处理器
+// @Summary 外部登录
+// @Description 重定向到外部身份提供方登录，登录流程的状态保存在 HttpOnly Cookie 中，回调时校验
+// @Tags 外部登录
+// @Produce json
+// @Param provider path string true "身份提供方标识"
+// @Success 302 "重定向到身份提供方的授权地址"
+// @Failure 400 {object} response.Response "身份提供方不存在或暂时不可用"
+// @Router /auth/{provider}/login [get]
+func (h *SSOHandler) Login() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authURL, stateToken, err := h.svc.StartLogin(ctx.Param("provider"))
+		if err != nil {
+			msg := "Identity provider unavailable"
+			if errors.Is(err, service.ErrSSOProviderNotFound) {
+				msg = "Identity provider not found"
+			}
+			ctx.JSON(http.StatusBadRequest, commonModel.Fail[string](
+				errorUtil.HandleError(&commonModel.ServerError{Msg: msg, Err: err}),
+			))
+			return
+		}
+
+		// 身份提供方重定向回来属于跨站顶级导航，SameSite=Lax 的 Cookie 仍会发送
+		ctx.SetSameSite(http.SameSiteLaxMode)
+		ctx.SetCookie(ssoStateCookie, stateToken, int(h.svc.StateExpires().Seconds()), ssoStateCookiePath, "", ctx.Request.TLS != nil, true)
+		ctx.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// Callback 外部登录回调处理器
+// @Summary 外部登录回调
+// @Description 身份提供方登录完成后的回调地址，校验 state 和 ID Token 后签发访问令牌和刷新令牌；外部身份首次登录且提供方开启了自动注册时创建本地账户。开启了两步验证的用户返回 mfa_required 和挑战令牌，需调用 /login/mfa 完成登录
+// @Tags 外部登录
+// @Produce json
+// @Param provider path string true "身份提供方标识"
+// @Param code query string true "授权码"
+// @Param state query string true "登录状态"
+// @Success 200 {object} response.Response{data=user.LoginResponse} "登录成功，返回访问令牌和刷新令牌"
+// @Failure 400 {object} response.Response "登录状态无效或已过期、身份提供方返回错误、外部身份未关联账户、邮箱已被使用、账户被禁用或需要重置密码"
+// @Router /auth/{provider}/callback [get]
+func (h *SSOHandler) Callback() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		stateToken, _ := ctx.Cookie(ssoStateCookie)
+		// 状态令牌只能使用一次，无论登录是否成功都清除
+		ctx.SetSameSite(http.SameSiteLaxMode)
+		ctx.SetCookie(ssoStateCookie, "", -1, ssoStateCookiePath, "", ctx.Request.TLS != nil, true)
+
+		if e := ctx.Query("error"); e != "" {
+			return res.Response{Msg: "External login failed", Err: errors.New(e + ": " + ctx.Query("error_description"))}
+		}
+
+		resp, err := h.svc.Callback(ctx.Request.Context(), ctx.Param("provider"), stateToken, ctx.Query("state"), ctx.Query("code"))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrSSOProviderNotFound):
+				return res.Response{Msg: "Identity provider not found", Err: err}
+			case errors.Is(err, service.ErrInvalidSSOState):
+				return res.Response{Msg: "Invalid or expired login state", Err: err}
+			case errors.Is(err, service.ErrSSOAccountNotLinked):
+				return res.Response{Msg: "No account linked to this identity", Err: err}
+			case errors.Is(err, service.ErrEmailTaken):
+				return res.Response{Msg: "Email already registered", Err: err}
+			case errors.Is(err, service.ErrUserDisabled):
+				return res.Response{Msg: "Account disabled", Err: err}
+			case errors.Is(err, service.ErrPasswordResetRequired):
+				return res.Response{Msg: "Password reset required", Err: err}
+			}
+			return res.Response{Msg: "External login failed", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
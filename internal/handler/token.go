@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	res "github.com/HoronLee/GinHub/internal/response"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler 令牌处理器
+type TokenHandler struct {
+	svc *service.TokenService
+}
+
+// NewTokenHandler 创建TokenHandler实例
+func NewTokenHandler(svc *service.TokenService) *TokenHandler {
+	return &TokenHandler{
+		svc: svc,
+	}
+}
+
+// RefreshToken 刷新令牌处理器
+// @Summary 刷新访问令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效；重复使用已失效的刷新令牌会吊销该登录会话的全部令牌
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body user.RefreshTokenRequest true "刷新令牌请求参数"
+// @Success 200 {object} response.Response{data=user.LoginResponse} "刷新成功，返回新的令牌对"
+// @Failure 400 {object} response.Response "请求参数错误或刷新令牌无效"
+// @Router /token/refresh [post]
+func (h *TokenHandler) RefreshToken() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.RefreshTokenRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.Refresh(ctx.Request.Context(), req.RefreshToken)
+		if err != nil {
+			return res.Response{Msg: "Token refresh failed", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// Logout 注销处理器
+// @Summary 注销登录
+// @Description 吊销当前访问令牌以及同一登录会话的刷新令牌
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=map[string]string} "注销成功"
+// @Failure 400 {object} response.Response "注销失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Router /logout [post]
+func (h *TokenHandler) Logout() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		if err := h.svc.Logout(ctx.Request.Context(), claims); err != nil {
+			return res.Response{Msg: "Logout failed", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Logged out successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// JWKS 公钥集合处理器
+// 返回用于验证 GinHub 访问令牌的公钥集合（RFC 7517），使用 HS256 时为空集合
+// 该路由不在 /api 前缀下，因此不出现在 Swagger 文档中
+func (h *TokenHandler) JWKS() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// JWKS 需要保持标准格式，不使用统一响应包装
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, h.svc.JWKS())
+	}
+}
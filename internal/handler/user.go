@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/HoronLee/GinHub/internal/model/user"
 	res "github.com/HoronLee/GinHub/internal/response"
 	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/HoronLee/GinHub/internal/util/paging"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,7 +27,7 @@ func NewUserHandler(svc *service.UserService) *UserHandler {
 
 // Register 用户注册处理器
 // @Summary 用户注册
-// @Description 创建新用户账户
+// @Description 创建新用户账户；填写了邮箱时发送验证邮件
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -49,13 +55,13 @@ func (h *UserHandler) Register() gin.HandlerFunc {
 
 // Login 用户登录处理器
 // @Summary 用户登录
-// @Description 用户身份验证并获取访问令牌
+// @Description 使用用户名或邮箱登录并获取访问令牌；开启了两步验证的用户返回 mfa_required 和挑战令牌，需调用 /login/mfa 完成登录
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Param request body user.LoginRequest true "登录请求参数"
-// @Success 200 {object} response.Response{data=user.LoginResponse} "登录成功，返回JWT令牌"
-// @Failure 400 {object} response.Response "请求参数错误或登录失败"
+// @Success 200 {object} response.Response{data=user.LoginResponse} "登录成功，返回访问令牌和刷新令牌"
+// @Failure 400 {object} response.Response "请求参数错误或登录失败；失败次数过多时返回 Too many login attempts 并设置 Retry-After 响应头；账户被禁用或要求重置密码时分别返回 Account disabled 和 Password reset required"
 // @Router /user/login [post]
 func (h *UserHandler) Login() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
@@ -64,13 +70,158 @@ func (h *UserHandler) Login() gin.HandlerFunc {
 			return res.Response{Msg: "Invalid request body", Err: err}
 		}
 
-		token, err := h.svc.Login(ctx.Request.Context(), req)
+		resp, err := h.svc.Login(ctx.Request.Context(), req)
 		if err != nil {
+			var throttled *service.ThrottledError
+			if errors.As(err, &throttled) {
+				ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+				return res.Response{Msg: "Too many login attempts", Err: err}
+			}
+			switch {
+			case errors.Is(err, service.ErrUserDisabled):
+				return res.Response{Msg: "Account disabled", Err: err}
+			case errors.Is(err, service.ErrPasswordResetRequired):
+				return res.Response{Msg: "Password reset required", Err: err}
+			}
 			return res.Response{Msg: "Login failed", Err: err}
 		}
 
 		return res.Response{
-			Data: user.LoginResponse{Token: token},
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// ChangePassword 修改密码处理器
+// @Summary 修改密码
+// @Description 验证当前密码后设置新密码，其他会话的刷新令牌将被吊销，当前会话保持登录；revoke_api_keys 为 true 时同时吊销全部 API Key
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.ChangePasswordRequest true "修改密码请求参数"
+// @Success 200 {object} response.Response{data=map[string]string} "修改成功"
+// @Failure 400 {object} response.Response "请求参数错误或当前密码错误"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/password [put]
+func (h *UserHandler) ChangePassword() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.ChangePasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		if err := h.svc.ChangePassword(ctx.Request.Context(), claims, req); err != nil {
+			return res.Response{Msg: "Failed to change password", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "Password changed successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// GetMe 获取当前用户信息处理器
+// @Summary 获取当前用户信息
+// @Description 获取当前登录用户的账户信息、个人资料和角色
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=user.UserResponse} "获取成功"
+// @Failure 400 {object} response.Response "获取失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Router /user/me [get]
+func (h *UserHandler) GetMe() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		resp, err := h.svc.GetProfile(ctx.Request.Context(), claims.UserID)
+		if err != nil {
+			return res.Response{Msg: "Failed to get user", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// UpdateMe 修改个人资料处理器
+// @Summary 修改个人资料
+// @Description 修改当前登录用户的显示名称、头像、语言、时区和个人简介，未提供的字段保持不变
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.UpdateProfileRequest true "修改个人资料请求参数"
+// @Success 200 {object} response.Response{data=user.UserResponse} "修改成功，返回最新的用户信息"
+// @Failure 400 {object} response.Response "请求参数错误或修改失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
+// @Router /user/me [patch]
+func (h *UserHandler) UpdateMe() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
+		}
+
+		var req user.UpdateProfileRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return res.Response{Msg: "Invalid request body", Err: err}
+		}
+
+		resp, err := h.svc.UpdateProfile(ctx.Request.Context(), claims.UserID, req)
+		if err != nil {
+			return res.Response{Msg: "Failed to update profile", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// GetUser 获取用户公开资料处理器
+// @Summary 获取用户公开资料
+// @Description 获取指定用户的公开资料，不包含邮箱等隐私信息
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=user.PublicProfileResponse} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误或用户不存在"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		resp, err := h.svc.GetPublicProfile(ctx.Request.Context(), userID)
+		if err != nil {
+			return res.Response{Msg: "Failed to get user", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
 			Msg:  "success",
 		}
 	})
@@ -78,7 +229,7 @@ func (h *UserHandler) Login() gin.HandlerFunc {
 
 // DeleteUser 删除用户处理器
 // @Summary 删除用户
-// @Description 删除当前登录的用户账户
+// @Description 删除当前登录的用户账户，保留期内可以联系管理员恢复。最后一个管理员不能删除自己的账户
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -86,22 +237,186 @@ func (h *UserHandler) Login() gin.HandlerFunc {
 // @Success 200 {object} response.Response{data=map[string]string} "删除成功"
 // @Failure 400 {object} response.Response "用户未认证或删除失败"
 // @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "不允许使用 API Key"
 // @Router /user/delete [delete]
 func (h *UserHandler) DeleteUser() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 从JWT中间件获取用户ID（当前登录用户）
-		userIDValue, exists := ctx.Get("user_id")
-		if !exists {
-			return res.Response{Msg: "User not authenticated", Err: nil}
+		// 从JWT中间件写入的上下文获取当前登录用户
+		claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+		if !ok {
+			return res.Response{Msg: "User not authenticated", Err: errors.New("claims not found in context")}
 		}
+		userID := claims.UserID
 
-		userID, ok := userIDValue.(uint)
-		if !ok {
-			return res.Response{Msg: "Invalid user ID format", Err: nil}
+		// 管理员删除其他用户见 AdminDeleteUser
+		if err := h.svc.DeleteUser(ctx.Request.Context(), userID); err != nil {
+			return res.Response{Msg: "Failed to delete user", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "User deleted successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// UnlockUser 解除用户锁定处理器
+// @Summary 解除用户锁定
+// @Description 清除指定用户的连续登录失败次数并解除账户锁定，需要 user:update 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "解除成功"
+// @Failure 400 {object} response.Response "请求参数错误或解除失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		if err := h.svc.UnlockUser(ctx.Request.Context(), userID); err != nil {
+			return res.Response{Msg: "Failed to unlock user", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "User unlocked successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// userListOptions 用户列表的分页默认值和允许排序的字段
+var userListOptions = paging.Options{
+	DefaultSort: "id",
+	Sortable:    []string{"id", "username", "created_at", "updated_at"},
+}
+
+// ListUsers 用户列表处理器
+// @Summary 查询用户列表
+// @Description 按用户名前缀、注册时间和状态筛选用户，支持按白名单字段排序；提供 page 时使用偏移分页并返回总数，否则使用游标分页。需要 user:read 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query user.ListUsersRequest false "筛选条件"
+// @Param page query int false "页码，从1开始，最大1000000；提供时使用偏移分页并返回总数"
+// @Param size query int false "每页数量，默认20，最大100"
+// @Param sort query string false "排序字段：id、username、created_at 或 updated_at，前缀 - 表示降序，多个字段用逗号分隔，默认 id"
+// @Param cursor query string false "游标，上一页返回的 next_cursor，不能与 page 同时使用"
+// @Success 200 {object} response.Response{data=paging.Page[user.AdminUserResponse]} "查询成功"
+// @Failure 400 {object} response.Response "请求参数错误、分页参数无效或游标无效"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users [get]
+func (h *UserHandler) ListUsers() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var req user.ListUsersRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			return res.Response{Msg: "Invalid query parameters", Err: err}
+		}
+		params, err := paging.Bind(ctx, userListOptions)
+		if err != nil {
+			return res.Response{Msg: "Invalid pagination parameters", Err: err}
+		}
+
+		resp, err := h.svc.ListUsers(ctx.Request.Context(), req, params)
+		if err != nil {
+			if errors.Is(err, paging.ErrInvalidCursor) {
+				return res.Response{Msg: "Invalid cursor", Err: err}
+			}
+			return res.Response{Msg: "Failed to list users", Err: err}
+		}
+
+		return res.Response{
+			Data: resp,
+			Msg:  "success",
+		}
+	})
+}
+
+// DisableUser 禁用用户处理器
+// @Summary 禁用用户
+// @Description 禁用指定用户并吊销其所有会话和刷新令牌，被禁用的用户不能登录，其 API Key 也随之失效。不能禁用自己和最后一个管理员，需要 user:update 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "禁用成功"
+// @Failure 400 {object} response.Response "请求参数错误或禁用失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser() gin.HandlerFunc {
+	return h.setUserDisabled(true)
+}
+
+// EnableUser 启用用户处理器
+// @Summary 启用用户
+// @Description 重新启用被禁用的用户，需要 user:update 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "启用成功"
+// @Failure 400 {object} response.Response "请求参数错误或启用失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/enable [post]
+func (h *UserHandler) EnableUser() gin.HandlerFunc {
+	return h.setUserDisabled(false)
+}
+
+// setUserDisabled 禁用或启用路径参数 id 指定的用户
+func (h *UserHandler) setUserDisabled(disabled bool) gin.HandlerFunc {
+	action, done := "enable", "enabled"
+	if disabled {
+		action, done = "disable", "disabled"
+	}
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseAdminTargetUser(ctx)
+		if err != nil {
+			return res.Response{Msg: "Cannot " + action + " this user", Err: err}
+		}
+
+		if err := h.svc.SetUserDisabled(ctx.Request.Context(), userID, disabled); err != nil {
+			return res.Response{Msg: "Failed to " + action + " user", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "User " + done + " successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// AdminDeleteUser 管理员删除用户处理器
+// @Summary 删除指定用户
+// @Description 删除指定用户并吊销其所有会话和刷新令牌，保留期内可以恢复。不能删除自己（请使用 DELETE /user）和最后一个管理员，需要 user:delete 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "删除成功"
+// @Failure 400 {object} response.Response "请求参数错误或删除失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) AdminDeleteUser() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseAdminTargetUser(ctx)
+		if err != nil {
+			return res.Response{Msg: "Cannot delete this user", Err: err}
 		}
 
-		// 也可以从URL参数获取要删除的用户ID（如果需要管理员删除其他用户）
-		// 这里简化为删除当前登录用户
 		if err := h.svc.DeleteUser(ctx.Request.Context(), userID); err != nil {
 			return res.Response{Msg: "Failed to delete user", Err: err}
 		}
@@ -112,3 +427,56 @@ func (h *UserHandler) DeleteUser() gin.HandlerFunc {
 		}
 	})
 }
+
+// RestoreUser 恢复用户处理器
+// @Summary 恢复已删除的用户
+// @Description 恢复保留期内被删除的用户；用户名或邮箱已被其他用户使用时不能恢复。需要 user:delete 权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]string} "恢复成功"
+// @Failure 400 {object} response.Response "请求参数错误或恢复失败"
+// @Failure 401 {object} response.Response "用户未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userID, err := parseUintParam(ctx, "id")
+		if err != nil {
+			return res.Response{Msg: "Invalid user ID", Err: err}
+		}
+
+		if err := h.svc.RestoreUser(ctx.Request.Context(), userID); err != nil {
+			switch {
+			case errors.Is(err, service.ErrUsernameTaken):
+				return res.Response{Msg: "Username already in use", Err: err}
+			case errors.Is(err, service.ErrEmailTaken):
+				return res.Response{Msg: "Email already in use", Err: err}
+			}
+			return res.Response{Msg: "Failed to restore user", Err: err}
+		}
+
+		return res.Response{
+			Data: gin.H{"message": "User restored successfully"},
+			Msg:  "success",
+		}
+	})
+}
+
+// parseAdminTargetUser 解析管理员操作的目标用户ID，目标不能是当前登录用户
+func parseAdminTargetUser(ctx *gin.Context) (uint, error) {
+	userID, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return 0, err
+	}
+	claims, ok := jwtUtil.FromContext[user.Claims](ctx.Request.Context())
+	if !ok {
+		return 0, errors.New("claims not found in context")
+	}
+	if claims.UserID == userID {
+		return 0, errors.New("cannot perform this action on your own account")
+	}
+	return userID, nil
+}
@@ -1,62 +1,279 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/HoronLee/GinHub/internal/config"
 	commonModel "github.com/HoronLee/GinHub/internal/model/common"
 	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
 	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware JWT 认证中间件
-func JWTAuthMiddleware() gin.HandlerFunc {
+// APIKeyValidator 验证 API Key 并返回对应用户的 claims
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*user.Claims, error)
+}
+
+// SessionTracker 记录登录会话的最近活跃时间
+type SessionTracker interface {
+	Touch(ctx context.Context, claims *user.Claims)
+}
+
+// Authenticator 请求认证器
+// 依次使用配置的提取器读取 API Key 或 JWT 访问令牌，验证通过后通过 jwtUtil.NewContext 将 claims 存入请求上下文
+type Authenticator struct {
+	jwtService  *jwtUtil.JWT[user.Claims]
+	revocations service.RevocationStore
+	extractors  []TokenExtractor
+
+	apiKeys          APIKeyValidator
+	apiKeyExtractors []TokenExtractor
+
+	sessions SessionTracker
+}
+
+// NewAuthenticator 创建Authenticator实例（通过Wire注入）
+func NewAuthenticator(
+	cfg *config.AppConfig,
+	jwtService *jwtUtil.JWT[user.Claims],
+	revocations service.RevocationStore,
+	apiKeys APIKeyValidator,
+	sessions SessionTracker,
+) (*Authenticator, error) {
+	lookup := cfg.Auth.TokenLookup
+
+	var extractors []TokenExtractor
+	if lookup.Header != "" {
+		extractors = append(extractors, NewHeaderExtractor(lookup.Header, lookup.Scheme))
+	}
+	if lookup.Cookie != "" {
+		extractors = append(extractors, NewCookieExtractor(lookup.Cookie))
+	}
+	if lookup.Query != "" {
+		extractors = append(extractors, NewQueryExtractor(lookup.Query, lookup.QueryUpgradeOnly))
+	}
+	if len(extractors) == 0 {
+		return nil, errors.New("at least one token lookup source must be configured")
+	}
+
+	// API Key 可以通过专用请求头或 Authorization: <Scheme> <key> 传递
+	var apiKeyExtractors []TokenExtractor
+	if cfg.Auth.APIKey.Header != "" {
+		apiKeyExtractors = append(apiKeyExtractors, NewHeaderExtractor(cfg.Auth.APIKey.Header, ""))
+	}
+	if cfg.Auth.APIKey.Scheme != "" {
+		apiKeyExtractors = append(apiKeyExtractors, &HeaderExtractor{
+			Header:           "Authorization",
+			Scheme:           cfg.Auth.APIKey.Scheme,
+			SkipOtherSchemes: true,
+		})
+	}
+
+	auth := NewAuthenticatorWithExtractors(jwtService, revocations, extractors...)
+	return auth.WithAPIKeys(apiKeys, apiKeyExtractors...).WithSessions(sessions), nil
+}
+
+// NewAuthenticatorWithExtractors 使用指定的提取器创建Authenticator实例
+// revocations 为 nil 时不检查令牌吊销列表
+func NewAuthenticatorWithExtractors(
+	jwtService *jwtUtil.JWT[user.Claims],
+	revocations service.RevocationStore,
+	extractors ...TokenExtractor,
+) *Authenticator {
+	return &Authenticator{
+		jwtService:  jwtService,
+		revocations: revocations,
+		extractors:  extractors,
+	}
+}
+
+// WithAPIKeys 启用 API Key 认证，API Key 优先于 JWT 访问令牌
+func (a *Authenticator) WithAPIKeys(validator APIKeyValidator, extractors ...TokenExtractor) *Authenticator {
+	a.apiKeys = validator
+	a.apiKeyExtractors = extractors
+	return a
+}
+
+// WithSessions 启用会话活跃时间记录，JWT 认证通过后调用 tracker.Touch
+func (a *Authenticator) WithSessions(tracker SessionTracker) *Authenticator {
+	a.sessions = tracker
+	return a
+}
+
+// JWTAuthMiddleware JWT 认证中间件，只从 Authorization: Bearer 请求头读取令牌
+// revocations 为 nil 时不检查令牌吊销列表
+func JWTAuthMiddleware(jwtService *jwtUtil.JWT[user.Claims], revocations service.RevocationStore) gin.HandlerFunc {
+	return NewAuthenticatorWithExtractors(jwtService, revocations,
+		NewHeaderExtractor("Authorization", "Bearer")).Required()
+}
+
+// Required 返回必须认证的中间件，请求中没有有效凭证时返回 401
+func (a *Authenticator) Required() gin.HandlerFunc {
+	return a.handler(true)
+}
+
+// Optional 返回可选认证的中间件
+// 请求中没有凭证时以匿名身份放行，上下文中不包含用户信息；携带了无效凭证时仍返回 401
+func (a *Authenticator) Optional() gin.HandlerFunc {
+	return a.handler(false)
+}
+
+// handler 创建认证中间件
+func (a *Authenticator) handler(required bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从 Authorization Header 提取 Token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		// 优先使用 API Key
+		if a.apiKeys != nil {
+			key, err := extractCredential(c.Request, a.apiKeyExtractors)
+			if err != nil {
+				abortFormatInvalid(c)
+				return
+			}
+			if key != "" {
+				a.authenticateAPIKey(c, key)
+				return
+			}
+		}
+
+		token, err := extractCredential(c.Request, a.extractors)
+		if err != nil {
+			abortFormatInvalid(c)
+			return
+		}
+		if token == "" {
+			if !required {
+				c.Next()
+				return
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="ginhub"`)
 			c.AbortWithStatusJSON(http.StatusUnauthorized,
 				commonModel.Fail[string]("Token not found"))
 			return
 		}
 
-		// 验证 Token 格式（Bearer <token>）
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				commonModel.Fail[string]("Token format invalid"))
-			return
+		a.authenticate(c, token)
+	}
+}
+
+// extractCredential 依次尝试各个提取器，返回第一个找到的凭证
+func extractCredential(r *http.Request, extractors []TokenExtractor) (string, error) {
+	for _, extractor := range extractors {
+		credential, err := extractor.Extract(r)
+		if err != nil {
+			return "", err
+		}
+		if credential != "" {
+			return credential, nil
 		}
+	}
+	return "", nil
+}
 
-		tokenString := parts[1]
-		if tokenString == "" {
+// abortFormatInvalid 凭证格式错误时中止请求
+func abortFormatInvalid(c *gin.Context) {
+	c.Header("WWW-Authenticate", bearerChallenge("invalid_request", "Token format invalid"))
+	c.AbortWithStatusJSON(http.StatusUnauthorized,
+		commonModel.Fail[string]("Token format invalid"))
+}
+
+// authenticate 验证令牌并将 claims 存入请求上下文
+func (a *Authenticator) authenticate(c *gin.Context, token string) {
+	// 解析和验证 Token
+	claims, err := a.jwtService.ParseToken(token)
+	if err != nil {
+		msg := tokenErrorMessage(err)
+		c.Header("WWW-Authenticate", bearerChallenge("invalid_token", msg))
+		c.AbortWithStatusJSON(http.StatusUnauthorized,
+			commonModel.Fail[string](msg))
+		return
+	}
+
+	// 检查 Token 或其所在的会话是否已被吊销
+	if a.revocations != nil {
+		revoked, err := a.isRevoked(c.Request.Context(), claims)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError,
+				commonModel.Fail[string]("Failed to verify token"))
+			return
+		}
+		if revoked {
+			c.Header("WWW-Authenticate", bearerChallenge("invalid_token", "Token revoked"))
 			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				commonModel.Fail[string]("Token not found"))
+				commonModel.Fail[string]("Token revoked"))
 			return
 		}
+	}
 
-		// 解析和验证 Token
-		jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
-			SecretKey: string(config.JWT_SECRET),
-		})
+	if a.sessions != nil {
+		a.sessions.Touch(c.Request.Context(), claims)
+	}
+	setClaims(c, claims)
+}
 
-		claims, err := jwtService.ParseToken(tokenString)
-		if err != nil {
+// isRevoked 判断令牌的 jti 或会话标识是否在吊销列表中
+func (a *Authenticator) isRevoked(ctx context.Context, claims *user.Claims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := a.revocations.IsRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if claims.SessionID != "" {
+		return a.revocations.IsRevoked(ctx, service.SessionRevocationKey(claims.SessionID))
+	}
+	return false, nil
+}
+
+// authenticateAPIKey 验证 API Key 并将对应用户的 claims 存入请求上下文
+func (a *Authenticator) authenticateAPIKey(c *gin.Context, key string) {
+	claims, err := a.apiKeys.ValidateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				commonModel.Fail[string]("Token invalid or expired"))
+				commonModel.Fail[string]("API key invalid"))
 			return
 		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError,
+			commonModel.Fail[string]("Failed to verify API key"))
+		return
+	}
+
+	setClaims(c, claims)
+}
 
-		// 将 UserID 存入 Context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+// setClaims 将 claims 存入请求上下文，service 层通过 jwtUtil.FromContext 读取
+func setClaims(c *gin.Context, claims *user.Claims) {
+	ctx := jwtUtil.NewContext(c.Request.Context(), claims)
+	c.Request = c.Request.WithContext(ctx)
 
-		// 也可以使用 jwt 包提供的上下文存储方式
-		// ctx := jwtUtil.NewContext(c.Request.Context(), claims)
-		// c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
 
-		c.Next()
+// tokenErrorMessage 将令牌解析错误转换为返回给客户端的错误信息
+func tokenErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, jwtUtil.ErrTokenMalformed):
+		return "Token malformed"
+	case errors.Is(err, jwtUtil.ErrTokenSignatureInvalid):
+		return "Token signature invalid"
+	case errors.Is(err, jwtUtil.ErrTokenExpired):
+		return "Token expired"
+	case errors.Is(err, jwtUtil.ErrTokenNotValidYet):
+		return "Token not yet valid"
+	case errors.Is(err, jwtUtil.ErrTokenInvalidAudience):
+		return "Token audience invalid"
+	case errors.Is(err, jwtUtil.ErrTokenInvalidIssuer):
+		return "Token issuer invalid"
+	default:
+		return "Token invalid"
 	}
 }
+
+// bearerChallenge 生成 RFC 6750 格式的 WWW-Authenticate 响应头
+func bearerChallenge(code, description string) string {
+	return fmt.Sprintf(`Bearer realm="ginhub", error="%s", error_description="%s"`, code, description)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/HoronLee/GinHub/internal/config"
 	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
 	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -83,7 +85,7 @@ func TestJWTAuthMiddleware(t *testing.T) {
 			name:           "Invalid token",
 			authHeader:     "Bearer invalid.token.here",
 			expectedStatus: http.StatusUnauthorized,
-			expectedMsg:    "Token invalid or expired",
+			expectedMsg:    "Token malformed",
 		},
 	}
 
@@ -91,15 +93,12 @@ func TestJWTAuthMiddleware(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// 创建测试路由
 			router := gin.New()
-			router.Use(JWTAuthMiddleware())
+			router.Use(JWTAuthMiddleware(jwtService, nil))
 			router.GET("/protected", func(c *gin.Context) {
-				userID, exists := c.Get("user_id")
-				assert.True(t, exists)
-				assert.Equal(t, uint(1), userID)
-
-				username, exists := c.Get("username")
-				assert.True(t, exists)
-				assert.Equal(t, "testuser", username)
+				claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+				assert.True(t, ok)
+				assert.Equal(t, uint(1), claims.UserID)
+				assert.Equal(t, "testuser", claims.Username)
 
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
@@ -120,6 +119,189 @@ func TestJWTAuthMiddleware(t *testing.T) {
 	}
 }
 
+func TestJWTAuthMiddlewareTokenErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
+		SecretKey: "test-secret-key",
+		Issuer:    "ginhub",
+		Audience:  "ginhub-api",
+	})
+
+	newToken := func(signer *jwtUtil.JWT[user.Claims], mutate func(*jwt.RegisteredClaims)) string {
+		registered := jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    "ginhub",
+			Audience:  []string{"ginhub-api"},
+		}
+		if mutate != nil {
+			mutate(&registered)
+		}
+		token, err := signer.GenerateToken(&user.Claims{UserID: 1, Username: "testuser", RegisteredClaims: registered})
+		assert.NoError(t, err)
+		return token
+	}
+	otherSigner := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{SecretKey: "other-secret-key"})
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedMsg string
+	}{
+		{"malformed", "invalid.token.here", "Token malformed"},
+		{"bad signature", newToken(otherSigner, nil), "Token signature invalid"},
+		{"expired", newToken(jwtService, func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		}), "Token expired"},
+		{"not yet valid", newToken(jwtService, func(c *jwt.RegisteredClaims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		}), "Token not yet valid"},
+		{"wrong audience", newToken(jwtService, func(c *jwt.RegisteredClaims) {
+			c.Audience = []string{"other-api"}
+		}), "Token audience invalid"},
+		{"wrong issuer", newToken(jwtService, func(c *jwt.RegisteredClaims) {
+			c.Issuer = "someone-else"
+		}), "Token issuer invalid"},
+	}
+
+	router := gin.New()
+	router.Use(JWTAuthMiddleware(jwtService, nil))
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedMsg)
+			assert.Equal(t,
+				`Bearer realm="ginhub", error="invalid_token", error_description="`+tt.expectedMsg+`"`,
+				w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+// fakeRevocationStore 测试用的吊销列表
+type fakeRevocationStore map[string]bool
+
+func (s fakeRevocationStore) Revoke(_ context.Context, jti string, _ time.Time) error {
+	s[jti] = true
+	return nil
+}
+
+func (s fakeRevocationStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return s[jti], nil
+}
+
+func (s fakeRevocationStore) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestJWTAuthMiddlewareRevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.JWT_SECRET = []byte("test-secret-key")
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
+		SecretKey: string(config.JWT_SECRET),
+	})
+
+	newToken := func(jti string) string {
+		token, err := jwtService.GenerateToken(&user.Claims{
+			UserID:   1,
+			Username: "testuser",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				ID:        jti,
+			},
+		})
+		assert.NoError(t, err)
+		return token
+	}
+
+	store := fakeRevocationStore{}
+	assert.NoError(t, store.Revoke(context.Background(), "revoked-jti", time.Now().Add(time.Hour)))
+
+	router := gin.New()
+	router.Use(JWTAuthMiddleware(jwtService, store))
+	router.GET("/protected", func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "active-jti", claims.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	for jti, expectedStatus := range map[string]int{
+		"active-jti":  http.StatusOK,
+		"revoked-jti": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+newToken(jti))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expectedStatus, w.Code, jti)
+	}
+}
+
+// fakeSessionTracker 记录被更新活跃时间的会话
+type fakeSessionTracker []string
+
+func (t *fakeSessionTracker) Touch(_ context.Context, claims *user.Claims) {
+	*t = append(*t, claims.SessionID)
+}
+
+func TestJWTAuthMiddlewareRevokedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.JWT_SECRET = []byte("test-secret-key")
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
+		SecretKey: string(config.JWT_SECRET),
+	})
+
+	newToken := func(sid string) string {
+		token, err := jwtService.GenerateToken(&user.Claims{
+			UserID:    1,
+			Username:  "testuser",
+			SessionID: sid,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				ID:        "jti-" + sid,
+			},
+		})
+		assert.NoError(t, err)
+		return token
+	}
+
+	store := fakeRevocationStore{}
+	assert.NoError(t, store.Revoke(context.Background(), service.SessionRevocationKey("revoked-sid"), time.Now().Add(time.Hour)))
+	tracker := &fakeSessionTracker{}
+
+	auth := NewAuthenticatorWithExtractors(jwtService, store, NewHeaderExtractor("Authorization", "Bearer")).WithSessions(tracker)
+	router := gin.New()
+	router.Use(auth.Required())
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	for sid, expectedStatus := range map[string]int{
+		"active-sid":  http.StatusOK,
+		"revoked-sid": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+newToken(sid))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expectedStatus, w.Code, sid)
+	}
+
+	// 只有认证通过的会话会更新活跃时间
+	assert.Equal(t, []string{"active-sid"}, []string(*tracker))
+}
+
 // Feature: user-auth, Property 8: Authentication middleware token validation
 // Validates: Requirements 3.1, 4.1, 4.5
 func TestProperty_MiddlewareTokenValidation(t *testing.T) {
@@ -166,17 +348,15 @@ func TestProperty_MiddlewareTokenValidation(t *testing.T) {
 
 			// Create test router with middleware
 			router := gin.New()
-			router.Use(JWTAuthMiddleware())
+			router.Use(JWTAuthMiddleware(jwtService, nil))
 
 			contextUserID := uint(0)
 			contextUsername := ""
 			router.GET("/protected", func(c *gin.Context) {
 				// Extract user ID from context
-				if id, exists := c.Get("user_id"); exists {
-					contextUserID = id.(uint)
-				}
-				if name, exists := c.Get("username"); exists {
-					contextUsername = name.(string)
+				if claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context()); ok {
+					contextUserID = claims.UserID
+					contextUsername = claims.Username
 				}
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
@@ -201,7 +381,7 @@ func TestProperty_MiddlewareTokenValidation(t *testing.T) {
 		func(invalidHeader string) bool {
 			// Create test router with middleware
 			router := gin.New()
-			router.Use(JWTAuthMiddleware())
+			router.Use(JWTAuthMiddleware(jwtService, nil))
 			router.GET("/protected", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
@@ -232,7 +412,7 @@ func TestProperty_MiddlewareTokenValidation(t *testing.T) {
 
 			// Create test router with middleware
 			router := gin.New()
-			router.Use(JWTAuthMiddleware())
+			router.Use(JWTAuthMiddleware(jwtService, nil))
 			router.GET("/protected", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
@@ -254,3 +434,194 @@ func TestProperty_MiddlewareTokenValidation(t *testing.T) {
 	// Run all properties
 	properties.TestingRun(t)
 }
+
+func TestAuthenticatorExtractors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.AppConfig{}
+	cfg.Auth.TokenLookup.Header = "Authorization"
+	cfg.Auth.TokenLookup.Scheme = "Bearer"
+	cfg.Auth.TokenLookup.Cookie = "access_token"
+	cfg.Auth.TokenLookup.Query = "access_token"
+	cfg.Auth.TokenLookup.QueryUpgradeOnly = true
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{SecretKey: "test-secret-key"})
+	auth, err := NewAuthenticator(cfg, jwtService, nil, nil, nil)
+	assert.NoError(t, err)
+
+	token, err := jwtService.GenerateToken(&user.Claims{
+		UserID:   1,
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/protected", auth.Required(), func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, uint(1), claims.UserID)
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	tests := []struct {
+		name           string
+		setup          func(req *http.Request)
+		expectedStatus int
+	}{
+		{"header", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}, http.StatusOK},
+		{"cookie", func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: token})
+		}, http.StatusOK},
+		{"query on websocket upgrade", func(req *http.Request) {
+			req.URL.RawQuery = "access_token=" + token
+			req.Header.Set("Connection", "keep-alive, Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+		}, http.StatusOK},
+		{"query on plain request", func(req *http.Request) {
+			req.URL.RawQuery = "access_token=" + token
+		}, http.StatusUnauthorized},
+		{"malformed header takes precedence", func(req *http.Request) {
+			req.Header.Set("Authorization", "Basic abc")
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: token})
+		}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// 未配置任何令牌来源时无法创建认证器
+	_, err = NewAuthenticator(&config.AppConfig{}, jwtService, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestAuthenticatorOptional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{SecretKey: "test-secret-key"})
+	auth := NewAuthenticatorWithExtractors(jwtService, nil, NewHeaderExtractor("Authorization", "Bearer"))
+
+	token, err := jwtService.GenerateToken(&user.Claims{
+		UserID:   1,
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/feed", auth.Optional(), func(c *gin.Context) {
+		if claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context()); ok {
+			c.String(http.StatusOK, claims.Username)
+			return
+		}
+		c.String(http.StatusOK, "anonymous")
+	})
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"anonymous", "", http.StatusOK, "anonymous"},
+		{"authenticated", "Bearer " + token, http.StatusOK, "testuser"},
+		{"invalid token", "Bearer invalid.token.here", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// fakeAPIKeyValidator 测试用的 API Key 验证器
+type fakeAPIKeyValidator map[string]*user.Claims
+
+func (v fakeAPIKeyValidator) ValidateAPIKey(_ context.Context, key string) (*user.Claims, error) {
+	claims, ok := v[key]
+	if !ok {
+		return nil, service.ErrInvalidAPIKey
+	}
+	return claims, nil
+}
+
+func TestAuthenticatorAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.AppConfig{}
+	cfg.Auth.TokenLookup.Header = "Authorization"
+	cfg.Auth.TokenLookup.Scheme = "Bearer"
+	cfg.Auth.APIKey.Header = "X-API-Key"
+	cfg.Auth.APIKey.Scheme = "ApiKey"
+
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{SecretKey: "test-secret-key"})
+	validator := fakeAPIKeyValidator{"ghk_valid": {UserID: 2, Username: "ci-bot"}}
+	auth, err := NewAuthenticator(cfg, jwtService, nil, validator, nil)
+	assert.NoError(t, err)
+
+	token, err := jwtService.GenerateToken(&user.Claims{
+		UserID:   1,
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/protected", auth.Required(), func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		assert.True(t, ok)
+		c.String(http.StatusOK, claims.Username)
+	})
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"X-API-Key header", "X-API-Key", "ghk_valid", http.StatusOK, "ci-bot"},
+		{"Authorization ApiKey", "Authorization", "ApiKey ghk_valid", http.StatusOK, "ci-bot"},
+		{"Authorization Bearer", "Authorization", "Bearer " + token, http.StatusOK, "testuser"},
+		{"invalid key", "X-API-Key", "ghk_invalid", http.StatusUnauthorized, ""},
+		{"unknown scheme", "Authorization", "Basic abc", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo 将客户端 IP 和 User-Agent 存入请求上下文，service 层通过 request.FromContext 读取
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := request.NewContext(c.Request.Context(), &request.ClientInfo{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
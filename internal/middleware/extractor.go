@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrTokenFormatInvalid 请求中携带了令牌但格式不正确
+var ErrTokenFormatInvalid = errors.New("token format invalid")
+
+// TokenExtractor 从请求中提取访问令牌
+// 请求中没有令牌时返回空字符串和 nil；携带了令牌但格式不正确时返回 ErrTokenFormatInvalid
+type TokenExtractor interface {
+	Extract(r *http.Request) (string, error)
+}
+
+// HeaderExtractor 从请求头提取令牌，例如 Authorization: Bearer <token>
+type HeaderExtractor struct {
+	Header string
+	// Scheme 令牌前的认证方案，为空时整个请求头的值即为令牌
+	Scheme string
+	// SkipOtherSchemes 为 true 时请求头使用其他认证方案视为没有令牌，用于多种凭证共用 Authorization 请求头
+	SkipOtherSchemes bool
+}
+
+// NewHeaderExtractor 创建HeaderExtractor实例
+func NewHeaderExtractor(header, scheme string) *HeaderExtractor {
+	return &HeaderExtractor{
+		Header: header,
+		Scheme: scheme,
+	}
+}
+
+// Extract 从请求头提取令牌
+func (e *HeaderExtractor) Extract(r *http.Request) (string, error) {
+	value := r.Header.Get(e.Header)
+	if value == "" {
+		return "", nil
+	}
+	if e.Scheme == "" {
+		return value, nil
+	}
+
+	// 验证 Token 格式（<Scheme> <token>）
+	parts := strings.SplitN(value, " ", 2)
+	if len(parts) != 2 || parts[0] != e.Scheme {
+		if e.SkipOtherSchemes {
+			return "", nil
+		}
+		return "", ErrTokenFormatInvalid
+	}
+	return parts[1], nil
+}
+
+// CookieExtractor 从 Cookie 提取令牌
+type CookieExtractor struct {
+	Name string
+}
+
+// NewCookieExtractor 创建CookieExtractor实例
+func NewCookieExtractor(name string) *CookieExtractor {
+	return &CookieExtractor{
+		Name: name,
+	}
+}
+
+// Extract 从 Cookie 提取令牌
+func (e *CookieExtractor) Extract(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(e.Name)
+	if err != nil {
+		return "", nil
+	}
+	return cookie.Value, nil
+}
+
+// QueryExtractor 从查询参数提取令牌
+// 浏览器发起 WebSocket 连接时无法设置请求头，只能通过查询参数传递令牌
+type QueryExtractor struct {
+	Param string
+	// UpgradeOnly 为 true 时只在协议升级请求中读取查询参数，避免令牌出现在普通请求的访问日志中
+	UpgradeOnly bool
+}
+
+// NewQueryExtractor 创建QueryExtractor实例
+func NewQueryExtractor(param string, upgradeOnly bool) *QueryExtractor {
+	return &QueryExtractor{
+		Param:       param,
+		UpgradeOnly: upgradeOnly,
+	}
+}
+
+// Extract 从查询参数提取令牌
+func (e *QueryExtractor) Extract(r *http.Request) (string, error) {
+	if e.UpgradeOnly && !isUpgradeRequest(r) {
+		return "", nil
+	}
+	return r.URL.Query().Get(e.Param), nil
+}
+
+// isUpgradeRequest 判断请求是否为协议升级请求
+func isUpgradeRequest(r *http.Request) bool {
+	for _, v := range strings.Split(r.Header.Get("Connection"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "upgrade") {
+			return r.Header.Get("Upgrade") != ""
+		}
+	}
+	return false
+}
@@ -0,0 +1,6 @@
+package middleware
+
+import "github.com/google/wire"
+
+// ProviderSet is middleware providers.
+var ProviderSet = wire.NewSet(NewAuthenticator)
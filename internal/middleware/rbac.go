@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+
+	commonModel "github.com/HoronLee/GinHub/internal/model/common"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 角色校验中间件，拥有任一指定角色即可通过
+// 必须在 JWT 认证中间件之后使用
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized,
+				commonModel.Fail[string]("User not authenticated"))
+			return
+		}
+
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden,
+			commonModel.Fail[string]("Insufficient role"))
+	}
+}
+
+// RejectAPIKey 拒绝通过 API Key 认证的请求，用于管理 API Key、账户和会话等只允许用户本人操作的接口，
+// 避免泄露的密钥（即使只有部分权限）被用来创建新密钥或接管账户
+// 必须在认证中间件之后使用
+func RejectAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized,
+				commonModel.Fail[string]("User not authenticated"))
+			return
+		}
+		if claims.IsAPIKey() {
+			c.AbortWithStatusJSON(http.StatusForbidden,
+				commonModel.Fail[string]("API key not allowed"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission 权限校验中间件，必须拥有全部指定权限才能通过
+// 必须在 JWT 认证中间件之后使用
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := jwtUtil.FromContext[user.Claims](c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized,
+				commonModel.Fail[string]("User not authenticated"))
+			return
+		}
+
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden,
+					commonModel.Fail[string]("Insufficient permission"))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// withClaims 测试用中间件，模拟 JWT 认证后写入 claims
+func withClaims(claims *user.Claims) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims != nil {
+			c.Request = c.Request.WithContext(jwtUtil.NewContext(c.Request.Context(), claims))
+		}
+		c.Next()
+	}
+}
+
+func TestRequireRoleAndPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &user.Claims{UserID: 1, Roles: []string{user.RoleAdmin}, Permissions: []string{user.PermissionAll}}
+	editor := &user.Claims{UserID: 2, Roles: []string{"editor"}, Permissions: []string{"user:*"}}
+	member := &user.Claims{UserID: 3, Roles: []string{user.RoleUser}}
+	apiKey := &user.Claims{UserID: 1, Roles: []string{user.RoleAdmin}, Permissions: []string{user.PermissionAll}, AuthMethod: user.AuthMethodAPIKey}
+
+	tests := []struct {
+		name           string
+		claims         *user.Claims
+		middleware     gin.HandlerFunc
+		expectedStatus int
+	}{
+		{"Unauthenticated role check", nil, RequireRole(user.RoleAdmin), http.StatusUnauthorized},
+		{"Admin role", admin, RequireRole(user.RoleAdmin), http.StatusOK},
+		{"Any of roles", editor, RequireRole(user.RoleAdmin, "editor"), http.StatusOK},
+		{"Missing role", member, RequireRole(user.RoleAdmin), http.StatusForbidden},
+		{"Unauthenticated permission check", nil, RequirePermission(user.PermissionUserDelete), http.StatusUnauthorized},
+		{"Wildcard permission", admin, RequirePermission(user.PermissionUserDelete, user.PermissionRoleManage), http.StatusOK},
+		{"Resource wildcard permission", editor, RequirePermission(user.PermissionUserDelete), http.StatusOK},
+		{"Resource wildcard does not cross resources", editor, RequirePermission(user.PermissionRoleManage), http.StatusForbidden},
+		{"Missing permission", member, RequirePermission(user.PermissionUserDelete), http.StatusForbidden},
+		{"Unauthenticated API key check", nil, RejectAPIKey(), http.StatusUnauthorized},
+		{"Login token allowed", admin, RejectAPIKey(), http.StatusOK},
+		{"API key rejected", apiKey, RejectAPIKey(), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(withClaims(tt.claims), tt.middleware)
+			router.GET("/admin", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
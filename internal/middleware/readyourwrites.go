@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/HoronLee/GinHub/internal/util/dbctx"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadYourWrites 请求中写入数据库后，同一请求的后续读取从主库执行，避免从只读副本读到复制延迟前的旧数据
+func ReadYourWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(dbctx.WithReadYourWrites(c.Request.Context()))
+
+		c.Next()
+	}
+}
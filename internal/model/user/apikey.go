@@ -0,0 +1,29 @@
+package user
+
+import "time"
+
+// APIKeyPrefix API Key 的固定前缀，便于在日志和代码仓库中识别泄露的密钥
+const APIKeyPrefix = "ghk"
+
+// APIKey 个人 API Key 模型
+// 完整密钥形如 ghk_<prefix>_<secret>，只在创建时返回一次，数据库中只保存其摘要
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive 判断 API Key 在指定时间是否可用
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -1,10 +1,47 @@
 package user
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthMethodAPIKey 通过 API Key 认证
+const AuthMethodAPIKey = "api_key"
 
 // Claims JWT Claims 结构体
+// 令牌唯一标识 jti 存放在 RegisteredClaims.ID 中，用于吊销单个令牌
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID      uint     `json:"user_id"`
+	Username    string   `json:"username"`
+	SessionID   string   `json:"sid,omitempty"`   // 登录会话标识，与刷新令牌的 FamilyID 一致
+	Roles       []string `json:"roles,omitempty"` // 用户角色
+	Permissions []string `json:"perms,omitempty"` // 角色展开后的权限
+	AuthMethod  string   `json:"-"`               // 认证方式，通过 API Key 认证时为 AuthMethodAPIKey，不写入令牌
 	jwt.RegisteredClaims
 }
+
+// IsAPIKey 判断是否通过 API Key 认证
+func (c *Claims) IsAPIKey() bool {
+	return c.AuthMethod == AuthMethodAPIKey
+}
+
+// HasRole 判断是否拥有指定角色
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasPermission 判断是否拥有指定权限
+// 支持通配权限 "*" 以及资源级通配，例如 "user:*" 匹配 "user:delete"
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+		if resource, ok := strings.CutSuffix(p, ":*"); ok && strings.HasPrefix(permission, resource+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,21 +1,426 @@
 package user
 
+import "time"
+
 // RegisterRequest 注册请求
 // swagger:model RegisterRequest
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50" example:"john_doe" description:"用户名，长度3-50字符"`
+	Username string `json:"username" binding:"required,min=3,max=50,excludes=@" example:"john_doe" description:"用户名，长度3-50字符，不能包含@"`
 	Password string `json:"password" binding:"required,min=6" example:"password123" description:"密码，最少6个字符"`
+	Email    string `json:"email" binding:"omitempty,email,max=255" example:"john@example.com" description:"邮箱，用于登录和找回密码，可选"`
 }
 
 // LoginRequest 登录请求
 // swagger:model LoginRequest
 type LoginRequest struct {
-	Username string `json:"username" binding:"required" example:"john_doe" description:"用户名"`
+	Username string `json:"username" binding:"required" example:"john_doe" description:"用户名或邮箱"`
 	Password string `json:"password" binding:"required" example:"password123" description:"密码"`
 }
 
 // LoginResponse 登录响应
 // swagger:model LoginResponse
+// 开启了两步验证的用户只返回 MFARequired 和 MFAToken，需要调用 /login/mfa 换取令牌
 type LoginResponse struct {
-	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..." description:"JWT访问令牌"`
+	Token        string `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..." description:"JWT访问令牌"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"x5fQ3mWb7Dq0s2Hk..." description:"刷新令牌，仅可使用一次"`
+	ExpiresIn    int    `json:"expires_in,omitempty" example:"86400" description:"访问令牌有效期，单位为秒"`
+	TokenType    string `json:"token_type,omitempty" example:"Bearer" description:"令牌类型"`
+	MFARequired  bool   `json:"mfa_required,omitempty" example:"false" description:"是否需要两步验证"`
+	MFAToken     string `json:"mfa_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..." description:"两步验证挑战令牌，短期有效"`
+}
+
+// MFALoginRequest 两步验证登录请求
+// swagger:model MFALoginRequest
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..." description:"登录接口返回的两步验证挑战令牌"`
+	Code     string `json:"code" binding:"required" example:"123456" description:"身份验证器中的验证码或恢复码"`
+}
+
+// TOTPCodeRequest 两步验证验证码请求
+// swagger:model TOTPCodeRequest
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456" description:"身份验证器中的验证码"`
+}
+
+// TOTPSetupResponse 两步验证绑定响应
+// swagger:model TOTPSetupResponse
+type TOTPSetupResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP" description:"Base32 编码的共享密钥，用于手动输入"`
+	URI    string `json:"uri" example:"otpauth://totp/GinHub:john_doe?secret=JBSWY3DPEHPK3PXP&issuer=GinHub" description:"otpauth URI，用于生成二维码"`
+}
+
+// RecoveryCodesResponse 恢复码响应
+// swagger:model RecoveryCodesResponse
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"3f9a1-c7e2b" description:"恢复码，仅显示一次，每个只能使用一次"`
+}
+
+// UserResponse 当前用户信息响应
+// swagger:model UserResponse
+type UserResponse struct {
+	ID            uint      `json:"id" example:"1" description:"用户ID"`
+	Username      string    `json:"username" example:"john_doe" description:"用户名"`
+	Email         string    `json:"email,omitempty" example:"john@example.com" description:"邮箱"`
+	EmailVerified bool      `json:"email_verified" example:"true" description:"邮箱是否已验证"`
+	DisplayName   string    `json:"display_name" example:"John Doe" description:"显示名称"`
+	AvatarURL     string    `json:"avatar_url" example:"https://example.com/avatar.png" description:"头像地址"`
+	Locale        string    `json:"locale" example:"zh-CN" description:"语言，BCP 47 语言标签"`
+	Timezone      string    `json:"timezone" example:"Asia/Shanghai" description:"时区，IANA 时区名称"`
+	Bio           string    `json:"bio" example:"Gopher" description:"个人简介"`
+	Roles         []string  `json:"roles" example:"user" description:"用户角色"`
+	TOTPEnabled   bool      `json:"totp_enabled" example:"false" description:"是否开启两步验证"`
+	CreatedAt     time.Time `json:"created_at" description:"注册时间"`
+	UpdatedAt     time.Time `json:"updated_at" description:"更新时间"`
+}
+
+// NewUserResponse 将用户模型转换为当前用户信息响应
+func NewUserResponse(u *User, roles []string) UserResponse {
+	if roles == nil {
+		roles = []string{}
+	}
+	resp := UserResponse{
+		ID:            u.ID,
+		Username:      u.Username,
+		EmailVerified: u.EmailVerified(),
+		DisplayName:   u.DisplayName,
+		AvatarURL:     u.AvatarURL,
+		Locale:        u.Locale,
+		Timezone:      u.Timezone,
+		Bio:           u.Bio,
+		Roles:         roles,
+		TOTPEnabled:   u.TOTPEnabled,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+	}
+	if u.Email != nil {
+		resp.Email = *u.Email
+	}
+	return resp
+}
+
+// PublicProfileResponse 用户公开资料响应，不包含邮箱等隐私信息
+// swagger:model PublicProfileResponse
+type PublicProfileResponse struct {
+	ID          uint      `json:"id" example:"1" description:"用户ID"`
+	Username    string    `json:"username" example:"john_doe" description:"用户名"`
+	DisplayName string    `json:"display_name" example:"John Doe" description:"显示名称"`
+	AvatarURL   string    `json:"avatar_url" example:"https://example.com/avatar.png" description:"头像地址"`
+	Bio         string    `json:"bio" example:"Gopher" description:"个人简介"`
+	CreatedAt   time.Time `json:"created_at" description:"注册时间"`
+}
+
+// NewPublicProfileResponse 将用户模型转换为公开资料响应
+func NewPublicProfileResponse(u *User) PublicProfileResponse {
+	return PublicProfileResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+		Bio:         u.Bio,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+// UpdateProfileRequest 修改个人资料请求
+// swagger:model UpdateProfileRequest
+// 字段为 null 或未提供时保持不变，提供空字符串时清空
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitzero,max=64" example:"John Doe" description:"显示名称，最多64字符"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitzero,http_url,max=512" example:"https://example.com/avatar.png" description:"头像地址，必须是 http 或 https 链接"`
+	Locale      *string `json:"locale" binding:"omitzero,bcp47_language_tag,max=35" example:"zh-CN" description:"语言，BCP 47 语言标签"`
+	Timezone    *string `json:"timezone" binding:"omitzero,timezone,max=64" example:"Asia/Shanghai" description:"时区，IANA 时区名称"`
+	Bio         *string `json:"bio" binding:"omitzero,max=500" example:"Gopher" description:"个人简介，最多500字符"`
+}
+
+// ListUsersRequest 用户列表筛选条件（管理员），分页参数 page、size、sort 和 cursor 由 paging.Bind 读取
+// swagger:model ListUsersRequest
+type ListUsersRequest struct {
+	Username      string     `form:"username" binding:"omitempty,max=50" example:"jo" description:"用户名前缀"`
+	Status        string     `form:"status" binding:"omitempty,oneof=active disabled locked deleted" example:"active" description:"用户状态：active、disabled、locked 或 deleted，不指定时不包含已删除的用户"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00" description:"注册时间不早于，RFC 3339 格式"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00" description:"注册时间早于，RFC 3339 格式"`
+}
+
+// AdminUserResponse 管理员查看的用户信息
+// swagger:model AdminUserResponse
+type AdminUserResponse struct {
+	UserResponse
+	Status                string     `json:"status" example:"active" description:"用户状态：active 或 disabled"`
+	Locked                bool       `json:"locked" example:"false" description:"是否因登录失败次数过多被锁定"`
+	LockedUntil           *time.Time `json:"locked_until,omitempty" description:"锁定截止时间"`
+	FailedLoginCount      int        `json:"failed_login_count" example:"0" description:"连续登录失败次数"`
+	PasswordResetRequired bool       `json:"password_reset_required" example:"false" description:"是否要求重置密码"`
+	DeletedAt             *time.Time `json:"deleted_at,omitempty" description:"删除时间，仅已删除的用户返回"`
+}
+
+// NewAdminUserResponse 将用户模型转换为管理员查看的用户信息，角色取自 u.Roles
+func NewAdminUserResponse(u *User, now time.Time) AdminUserResponse {
+	roles := make([]string, 0, len(u.Roles))
+	for _, r := range u.Roles {
+		roles = append(roles, r.Name)
+	}
+	resp := AdminUserResponse{
+		UserResponse:          NewUserResponse(u, roles),
+		Status:                u.Status,
+		Locked:                u.IsLocked(now),
+		LockedUntil:           u.LockedUntil,
+		FailedLoginCount:      u.FailedLoginCount,
+		PasswordResetRequired: u.PasswordResetRequired,
+	}
+	if u.DeletedAt.Valid {
+		resp.DeletedAt = &u.DeletedAt.Time
+	}
+	return resp
+}
+
+// SessionResponse 登录会话信息
+// swagger:model SessionResponse
+type SessionResponse struct {
+	ID         uint      `json:"id" example:"1" description:"会话ID"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0" description:"登录时的 User-Agent"`
+	IP         string    `json:"ip" example:"203.0.113.1" description:"登录时的IP地址"`
+	CreatedAt  time.Time `json:"created_at" description:"登录时间"`
+	LastSeenAt time.Time `json:"last_seen_at" description:"最近活跃时间，按配置的间隔更新"`
+	ExpiresAt  time.Time `json:"expires_at" description:"会话过期时间"`
+	Current    bool      `json:"current" example:"true" description:"是否为当前请求所在的会话"`
+}
+
+// ChangePasswordRequest 修改密码请求
+// swagger:model ChangePasswordRequest
+type ChangePasswordRequest struct {
+	OldPassword   string `json:"old_password" binding:"required" example:"password123" description:"当前密码"`
+	NewPassword   string `json:"new_password" binding:"required,min=6" example:"newpassword456" description:"新密码，最少6个字符"`
+	RevokeAPIKeys bool   `json:"revoke_api_keys" example:"false" description:"是否同时吊销全部 API Key，怀疑账户被盗用时使用"`
+}
+
+// ForgotPasswordRequest 申请重置密码请求
+// swagger:model ForgotPasswordRequest
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required" example:"john_doe" description:"用户名或邮箱"`
+}
+
+// ResetPasswordRequest 重置密码请求
+// swagger:model ResetPasswordRequest
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"Jx0eZ6kq3m1S..." description:"邮件中的重置令牌"`
+	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword456" description:"新密码，最少6个字符"`
+}
+
+// VerifyEmailRequest 验证邮箱请求
+// swagger:model VerifyEmailRequest
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"Jx0eZ6kq3m1S..." description:"邮件中的验证令牌"`
+}
+
+// ResendVerificationRequest 重新发送验证邮件请求
+// swagger:model ResendVerificationRequest
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john@example.com" description:"注册时填写的邮箱"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+// swagger:model RefreshTokenRequest
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"x5fQ3mWb7Dq0s2Hk..." description:"登录或上次刷新时获得的刷新令牌"`
+}
+
+// RoleResponse 角色信息响应
+// swagger:model RoleResponse
+type RoleResponse struct {
+	ID          uint     `json:"id" example:"1" description:"角色ID"`
+	Name        string   `json:"name" example:"admin" description:"角色名称"`
+	Description string   `json:"description" example:"系统管理员" description:"角色描述"`
+	Permissions []string `json:"permissions" example:"user:delete,role:manage" description:"角色拥有的权限"`
+}
+
+// CreateRoleRequest 创建角色请求
+// swagger:model CreateRoleRequest
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50" example:"editor" description:"角色名称，长度2-50字符"`
+	Description string   `json:"description" binding:"max=255" example:"内容编辑" description:"角色描述"`
+	Permissions []string `json:"permissions" binding:"dive,min=1,max=100" example:"user:read" description:"角色拥有的权限"`
+}
+
+// AssignRoleRequest 分配角色请求
+// swagger:model AssignRoleRequest
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"admin" description:"要分配的角色名称"`
+}
+
+// NewRoleResponse 将角色模型转换为响应
+func NewRoleResponse(r *Role) RoleResponse {
+	return RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.PermissionNames(),
+	}
+}
+
+// CreateAPIKeyRequest 创建 API Key 请求
+// swagger:model CreateAPIKeyRequest
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100" example:"ci-deploy" description:"API Key 名称"`
+	Scopes        []string `json:"scopes" binding:"dive,min=1,max=100" example:"user:read" description:"API Key 可使用的权限，为空时继承用户的全部权限"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650" example:"90" description:"有效期，单位为天，为空时永不过期"`
+}
+
+// APIKeyResponse API Key 信息响应
+// swagger:model APIKeyResponse
+type APIKeyResponse struct {
+	ID         uint       `json:"id" example:"1" description:"API Key ID"`
+	Name       string     `json:"name" example:"ci-deploy" description:"API Key 名称"`
+	Prefix     string     `json:"prefix" example:"3f9a1c7e" description:"API Key 前缀，用于识别密钥"`
+	Scopes     []string   `json:"scopes" example:"user:read" description:"API Key 可使用的权限"`
+	ExpiresAt  *time.Time `json:"expires_at" description:"过期时间"`
+	LastUsedAt *time.Time `json:"last_used_at" description:"最近使用时间"`
+	CreatedAt  time.Time  `json:"created_at" description:"创建时间"`
+}
+
+// CreateAPIKeyResponse 创建 API Key 响应
+// swagger:model CreateAPIKeyResponse
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"ghk_3f9a1c7e_x5fQ3mWb7Dq0s2Hk..." description:"完整的 API Key，仅在创建时返回一次"`
+}
+
+// NewAPIKeyResponse 将 API Key 模型转换为响应
+func NewAPIKeyResponse(k *APIKey) APIKeyResponse {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreateOAuthClientRequest 注册 OAuth2 客户端请求
+// swagger:model CreateOAuthClientRequest
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,min=1,max=100" example:"Wiki" description:"客户端名称，显示在授权确认页面"`
+	RedirectURIs []string `json:"redirect_uris" binding:"max=20,dive,uri,max=2048" example:"https://wiki.example.com/oauth/callback" description:"允许的回调地址，授权请求中的 redirect_uri 必须与其中之一完全一致"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code client_credentials" example:"authorization_code" description:"允许的授权类型，可能的值为 authorization_code 或 client_credentials"`
+	Scopes       []string `json:"scopes" binding:"dive,min=1,max=100" example:"wiki:read" description:"除 openid、profile、email 外允许申请的权限范围"`
+	Public       bool     `json:"public" example:"false" description:"是否为公开客户端，公开客户端没有密钥且只能使用授权码模式"`
+}
+
+// OAuthClientResponse OAuth2 客户端信息响应
+// swagger:model OAuthClientResponse
+type OAuthClientResponse struct {
+	ID           uint      `json:"id" example:"1" description:"客户端记录ID"`
+	ClientID     string    `json:"client_id" example:"5d0f3c1a9b7e42c8a1f0e6d2c4b8a3f7" description:"客户端标识"`
+	Name         string    `json:"name" example:"Wiki" description:"客户端名称"`
+	RedirectURIs []string  `json:"redirect_uris" example:"https://wiki.example.com/oauth/callback" description:"允许的回调地址"`
+	GrantTypes   []string  `json:"grant_types" example:"authorization_code" description:"允许的授权类型"`
+	Scopes       []string  `json:"scopes" example:"wiki:read" description:"允许申请的权限范围"`
+	Public       bool      `json:"public" example:"false" description:"是否为公开客户端"`
+	CreatedAt    time.Time `json:"created_at" description:"创建时间"`
+}
+
+// CreateOAuthClientResponse 注册 OAuth2 客户端响应
+// swagger:model CreateOAuthClientResponse
+type CreateOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty" example:"x5fQ3mWb7Dq0s2Hk..." description:"客户端密钥，仅在创建机密客户端时返回一次"`
+}
+
+// NewOAuthClientResponse 将 OAuth2 客户端模型转换为响应
+func NewOAuthClientResponse(c *OAuthClient) OAuthClientResponse {
+	nonNil := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+	return OAuthClientResponse{
+		ID:           c.ID,
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: nonNil(c.RedirectURIs),
+		GrantTypes:   nonNil(c.GrantTypes),
+		Scopes:       nonNil(c.Scopes),
+		Public:       c.IsPublic(),
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// AuthorizeRequest OAuth2 授权请求（RFC 6749 4.1.1，RFC 7636）
+// 参数错误需要按协议重定向回客户端，因此不使用 binding 校验
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// OAuthTokenRequest OAuth2 令牌请求（RFC 6749 4.1.3、4.4.2）
+// 客户端凭证也可以通过 HTTP Basic 认证提供
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthTokenResponse OAuth2 令牌响应
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	IDToken     string `json:"id_token,omitempty"`
+}
+
+// OAuthIntrospectionResponse 令牌自省响应（RFC 7662），令牌无效时只包含 active=false
+type OAuthIntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	JTI       string   `json:"jti,omitempty"`
+}
+
+// OIDCUserInfoResponse OpenID Connect UserInfo 响应
+type OIDCUserInfoResponse struct {
+	Subject string `json:"sub"`
+	OIDCUserInfo
+}
+
+// OIDCDiscoveryResponse OpenID Connect 发现文档
+type OIDCDiscoveryResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	AuthorizationResponseIssParameter bool     `json:"authorization_response_iss_parameter_supported"`
 }
@@ -0,0 +1,36 @@
+package user
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity 外部身份模型，将外部身份提供方的用户（Provider + Subject）关联到本地用户
+type Identity struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"index;not null" json:"user_id"`
+	Provider    string    `gorm:"type:varchar(50);uniqueIndex:idx_identities_provider_subject,priority:1;not null" json:"provider"`
+	Subject     string    `gorm:"type:varchar(255);uniqueIndex:idx_identities_provider_subject,priority:2;not null" json:"subject"`
+	Email       string    `gorm:"type:varchar(255)" json:"email"` // 最近一次登录时提供方返回的邮箱
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// SSOStateClaims 外部登录流程的状态令牌，保存在浏览器 Cookie 中，回调时校验
+// jti 即发送给身份提供方的 state 参数，只能使用一次
+type SSOStateClaims struct {
+	Provider     string `json:"sso_provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+// OIDCIdentityClaims 外部身份提供方 ID Token 中使用的 Claims
+type OIDCIdentityClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Picture           string `json:"picture"`
+}
@@ -0,0 +1,11 @@
+package user
+
+import "time"
+
+// LoginAttempt 登录失败计数，以用户名或客户端 IP 为键
+type LoginAttempt struct {
+	Key          string    `gorm:"column:attempt_key;primaryKey;type:varchar(191)" json:"key"`
+	Failures     int       `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time `gorm:"not null" json:"last_failed_at"`
+	ExpiresAt    time.Time `gorm:"index;not null" json:"expires_at"` // 超过该时间没有新的失败记录则计数清零
+}
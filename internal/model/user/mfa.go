@@ -0,0 +1,24 @@
+package user
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RecoveryCode 两步验证恢复码模型
+// 丢失身份验证器时可以用恢复码代替验证码登录，每个恢复码只能使用一次，数据库中只保存其摘要
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAClaims 两步验证挑战令牌的 Claims
+// 用户 ID 使用与访问令牌不同的字段名，挑战令牌即使被当作访问令牌解析也不包含用户身份
+type MFAClaims struct {
+	UserID uint `json:"mfa_uid"`
+	jwt.RegisteredClaims
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OAuth2 授权类型
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OpenID Connect 标准权限范围
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// OAuthClient OAuth2 客户端模型
+// 机密客户端的密钥只在创建时返回一次，数据库中只保存其摘要；公开客户端没有密钥，必须使用 PKCE
+type OAuthClient struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ClientID     string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"client_id"`
+	SecretHash   string    `gorm:"type:varchar(64)" json:"-"`
+	Name         string    `gorm:"type:varchar(100);not null" json:"name"`
+	RedirectURIs []string  `gorm:"serializer:json" json:"redirect_uris"`
+	GrantTypes   []string  `gorm:"serializer:json" json:"grant_types"`
+	Scopes       []string  `gorm:"serializer:json" json:"scopes"` // 除 OpenID Connect 标准范围外允许申请的权限范围
+	CreatedBy    uint      `json:"created_by"`                    // 创建客户端的管理员ID，该用户被彻底删除后为 0
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsPublic 判断是否为公开客户端
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// AllowsGrant 判断客户端是否可以使用指定的授权类型
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// AllowsRedirectURI 判断回调地址是否已登记，要求完全一致
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// OAuthAuthorizationCode OAuth2 授权码模型
+// 授权码只能使用一次，数据库中只保存其摘要；TokenID 记录用该授权码签发的访问令牌，授权码被重放时吊销
+type OAuthAuthorizationCode struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CodeHash      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ClientID      string     `gorm:"type:varchar(64);index;not null" json:"client_id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`
+	RedirectURI   string     `gorm:"type:varchar(2048);not null" json:"redirect_uri"`
+	Scope         string     `gorm:"type:varchar(1024)" json:"scope"`
+	Nonce         string     `gorm:"type:varchar(255)" json:"-"`
+	CodeChallenge string     `gorm:"type:varchar(128);not null" json:"-"`
+	TokenID       string     `gorm:"type:varchar(64)" json:"-"`
+	ExpiresAt     time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OAuthClaims OAuth2 访问令牌的 Claims
+// 授权码模式的 sub 为用户 ID，客户端凭证模式的 sub 为 client_id；受众与 GinHub 访问令牌不同，不能用于调用 GinHub API
+type OAuthClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// OIDCUserInfo OpenID Connect 用户信息，按授权的权限范围填充
+type OIDCUserInfo struct {
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	Zoneinfo          string `json:"zoneinfo,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// IDTokenClaims OpenID Connect ID Token 的 Claims
+type IDTokenClaims struct {
+	Nonce           string `json:"nonce,omitempty"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	OIDCUserInfo
+	jwt.RegisteredClaims
+}
+
+// OAuthConsentClaims 授权确认页面携带的令牌，记录已校验的授权请求和发起授权的用户
+// 只有渲染确认页面的响应中包含该令牌，因此同时用于防止跨站请求伪造
+type OAuthConsentClaims struct {
+	UserID        uint   `json:"consent_uid"`
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	State         string `json:"state,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+	CodeChallenge string `json:"code_challenge"`
+	jwt.RegisteredClaims
+}
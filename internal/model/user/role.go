@@ -0,0 +1,46 @@
+package user
+
+import "time"
+
+// 内置角色
+const (
+	RoleAdmin = "admin" // 管理员，拥有全部权限
+	RoleUser  = "user"  // 普通用户，注册时默认分配
+)
+
+// 内置权限，格式为 "资源:操作"
+const (
+	PermissionAll         = "*"            // 通配权限
+	PermissionUserRead    = "user:read"    // 查看任意用户
+	PermissionUserUpdate  = "user:update"  // 修改任意用户
+	PermissionUserDelete  = "user:delete"  // 删除任意用户
+	PermissionRoleManage  = "role:manage"  // 管理角色及角色分配
+	PermissionOAuthManage = "oauth:manage" // 管理 OAuth2 客户端
+)
+
+// Role 角色模型
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
+	Description string       `gorm:"type:varchar(255)" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Permission 权限模型
+type Permission struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PermissionNames 返回角色拥有的权限名称
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
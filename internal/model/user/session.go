@@ -0,0 +1,17 @@
+package user
+
+import "time"
+
+// Session 登录会话模型
+// 每次登录创建一个会话，FamilyID 与刷新令牌的 FamilyID 和访问令牌的 sid 一致
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	FamilyID   string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP         string     `gorm:"column:ip;type:varchar(64)" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"index;not null" json:"expires_at"` // 与最新刷新令牌的过期时间一致
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package user
+
+import "time"
+
+// RefreshToken 刷新令牌模型
+// 同一次登录派生出的刷新令牌共享 FamilyID，每次刷新都会轮换出新的令牌
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	FamilyID  string     `gorm:"type:varchar(64);index;not null" json:"family_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -1,12 +1,73 @@
 package user
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 用户状态
+const (
+	StatusActive   = "active"   // 正常
+	StatusDisabled = "disabled" // 已被管理员禁用，不能登录
+)
 
 // User 用户模型
+// 接口不直接返回 User，而是通过 NewUserResponse 和 NewPublicProfileResponse 转换为 DTO
 type User struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
+	Username  string    `gorm:"type:varchar(50);uniqueIndex:idx_users_username,priority:1;not null" json:"username"`
 	Password  string    `gorm:"type:varchar(255);not null" json:"-"`
+	Email     *string   `gorm:"type:varchar(255);uniqueIndex:idx_users_email,priority:1" json:"email,omitempty"` // 规范化（去除首尾空白并转为小写）后保存
+	Roles     []Role    `gorm:"many2many:user_roles;" json:"roles,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// 软删除，DeletedID 在删除时设为用户ID，未删除时为0
+	// 用户名和邮箱的唯一索引包含 DeletedID，已删除用户的用户名和邮箱可以被重新注册
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedID uint           `gorm:"not null;default:0;uniqueIndex:idx_users_username,priority:2;uniqueIndex:idx_users_email,priority:2" json:"-"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"` // 为 nil 表示邮箱未验证
+
+	// 个人资料
+	DisplayName string `gorm:"type:varchar(64)" json:"display_name"`
+	AvatarURL   string `gorm:"type:varchar(512)" json:"avatar_url"`
+	Locale      string `gorm:"type:varchar(35)" json:"locale"`   // BCP 47 语言标签，例如 zh-CN
+	Timezone    string `gorm:"type:varchar(64)" json:"timezone"` // IANA 时区名称，例如 Asia/Shanghai
+	Bio         string `gorm:"type:varchar(500)" json:"bio"`
+
+	// 两步验证（TOTP），TOTPSecret 在确认绑定前即已保存，但只有 TOTPEnabled 为 true 时才在登录时要求验证码
+	TOTPSecret   string `gorm:"column:totp_secret;type:varchar(64)" json:"-"`
+	TOTPEnabled  bool   `gorm:"column:totp_enabled;not null;default:false" json:"totp_enabled"`
+	TOTPLastStep int64  `gorm:"column:totp_last_step;not null;default:0" json:"-"` // 最近一次使用的验证码时间步，用于防止重放
+
+	// 登录失败锁定，连续失败次数达到阈值后在 LockedUntil 之前拒绝登录
+	FailedLoginCount int        `gorm:"not null;default:0" json:"failed_login_count"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty"`
+
+	// 管理状态
+	Status                string `gorm:"type:varchar(16);not null;default:active;index" json:"status"`
+	PasswordResetRequired bool   `gorm:"not null;default:false" json:"password_reset_required"` // 管理员要求重置密码，重置前不能登录
+}
+
+// IsDisabled 判断账户是否已被禁用
+func (u *User) IsDisabled() bool {
+	return u.Status == StatusDisabled
+}
+
+// IsLocked 判断账户在指定时间是否处于锁定状态
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// EmailVerified 判断用户的邮箱是否已验证
+func (u *User) EmailVerified() bool {
+	return u.Email != nil && u.EmailVerifiedAt != nil
+}
+
+// NormalizeEmail 规范化邮箱地址，去除首尾空白并转为小写
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
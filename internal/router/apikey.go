@@ -0,0 +1,12 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1APIKeyRoutes 设置 v1 版本的 API Key 路由
+func setupV1APIKeyRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Account routes - 账户路由，需要登录令牌认证，不允许使用 API Key
+	// 路径: /api/v1/user/api-keys
+	routerGroup.AccountRouterGroup.POST("/user/api-keys", h.APIKeyHandler.CreateAPIKey())
+	routerGroup.AccountRouterGroup.GET("/user/api-keys", h.APIKeyHandler.ListAPIKeys())
+	routerGroup.AccountRouterGroup.DELETE("/user/api-keys/:id", h.APIKeyHandler.RevokeAPIKey())
+}
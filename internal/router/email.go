@@ -0,0 +1,11 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1EmailRoutes 设置 v1 版本的邮箱验证路由
+func setupV1EmailRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Public routes - 公开路由，要求验证邮箱后才能登录时，未验证的用户也需要访问
+	// 路径: POST /api/v1/user/verify-email, POST /api/v1/user/verify-email/resend
+	routerGroup.PublicRouterGroup.POST("/user/verify-email", h.EmailVerificationHandler.VerifyEmail())
+	routerGroup.PublicRouterGroup.POST("/user/verify-email/resend", h.EmailVerificationHandler.ResendVerification())
+}
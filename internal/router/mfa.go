@@ -0,0 +1,16 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1MFARoutes 设置 v1 版本的两步验证路由
+func setupV1MFARoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Public routes - 公开路由，使用登录返回的挑战令牌
+	// 路径: POST /api/v1/login/mfa
+	routerGroup.PublicRouterGroup.POST("/login/mfa", h.MFAHandler.LoginMFA())
+
+	// Account routes - 账户路由，需要登录令牌认证，不允许使用 API Key
+	// 路径: /api/v1/user/2fa/setup, /api/v1/user/2fa/confirm, /api/v1/user/2fa/disable
+	routerGroup.AccountRouterGroup.POST("/user/2fa/setup", h.MFAHandler.SetupTOTP())
+	routerGroup.AccountRouterGroup.POST("/user/2fa/confirm", h.MFAHandler.ConfirmTOTP())
+	routerGroup.AccountRouterGroup.POST("/user/2fa/disable", h.MFAHandler.DisableTOTP())
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/HoronLee/GinHub/internal/middleware"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/gin-gonic/gin"
+)
+
+// setupOAuthRoutes 设置 OAuth2 授权服务器协议端点
+// 端点路径不挂载在 /api 版本前缀下，授权端点可选认证，未登录时提示用户先登录
+func setupOAuthRoutes(r *gin.Engine, h *handler.Handlers, auth *middleware.Authenticator) {
+	oauth := r.Group("/oauth")
+
+	// 路径: /oauth/authorize, /oauth/token, /oauth/introspect, /oauth/revoke, /oauth/userinfo
+	oauth.GET("/authorize", auth.Optional(), h.OAuthHandler.Authorize())
+	oauth.POST("/authorize", auth.Optional(), h.OAuthHandler.Approve())
+	oauth.POST("/token", h.OAuthHandler.Token())
+	oauth.POST("/introspect", h.OAuthHandler.Introspect())
+	oauth.POST("/revoke", h.OAuthHandler.Revoke())
+	oauth.GET("/userinfo", h.OAuthHandler.UserInfo())
+	oauth.POST("/userinfo", h.OAuthHandler.UserInfo())
+}
+
+// setupV1OAuthClientRoutes 设置 v1 版本的 OAuth2 客户端管理路由
+func setupV1OAuthClientRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Admin routes - 管理员路由，需要 JWT 认证、管理员角色和 oauth:manage 权限
+	// 路径: /api/v1/admin/oauth/clients
+	clients := routerGroup.AdminRouterGroup.Group("/oauth/clients", middleware.RequirePermission(user.PermissionOAuthManage))
+	clients.GET("", h.OAuthHandler.ListOAuthClients())
+	clients.POST("", h.OAuthHandler.CreateOAuthClient())
+	clients.DELETE("/:id", h.OAuthHandler.DeleteOAuthClient())
+}
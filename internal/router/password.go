@@ -0,0 +1,15 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1PasswordRoutes 设置 v1 版本的修改密码和找回密码路由
+func setupV1PasswordRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Public routes - 公开路由，无需认证
+	// 路径: POST /api/v1/password/forgot, POST /api/v1/password/reset
+	routerGroup.PublicRouterGroup.POST("/password/forgot", h.PasswordResetHandler.ForgotPassword())
+	routerGroup.PublicRouterGroup.POST("/password/reset", h.PasswordResetHandler.ResetPassword())
+
+	// Account routes - 账户路由，需要登录令牌认证，不允许使用 API Key
+	// 路径: PUT /api/v1/user/password
+	routerGroup.AccountRouterGroup.PUT("/user/password", h.UserHandler.ChangePassword())
+}
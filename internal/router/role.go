@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/HoronLee/GinHub/internal/middleware"
+	"github.com/HoronLee/GinHub/internal/model/user"
+)
+
+// setupV1RoleRoutes 设置 v1 版本的角色管理路由
+func setupV1RoleRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Admin routes - 管理员路由，需要 JWT 认证和管理员角色
+	// 路径: /api/v1/admin/roles, /api/v1/admin/users/:id/roles
+	roles := routerGroup.AdminRouterGroup.Group("", middleware.RequirePermission(user.PermissionRoleManage))
+	roles.GET("/roles", h.RoleHandler.ListRoles())
+	roles.POST("/roles", h.RoleHandler.CreateRole())
+	roles.GET("/users/:id/roles", h.RoleHandler.GetUserRoles())
+	roles.POST("/users/:id/roles", h.RoleHandler.AssignRole())
+	roles.DELETE("/users/:id/roles/:role", h.RoleHandler.RemoveRole())
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/HoronLee/GinHub/internal/handler"
 	"github.com/HoronLee/GinHub/internal/middleware"
+	"github.com/HoronLee/GinHub/internal/model/user"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,12 +11,20 @@ import (
 type VersionedRouterGroup struct {
 	PublicRouterGroup  *gin.RouterGroup
 	PrivateRouterGroup *gin.RouterGroup
+	AccountRouterGroup *gin.RouterGroup // 管理自身账户、凭证和会话，不允许使用 API Key
+	AdminRouterGroup   *gin.RouterGroup
 }
 
 // SetupRouter 配置路由
-func SetupRouter(r *gin.Engine, h *handler.Handlers) {
+func SetupRouter(r *gin.Engine, h *handler.Handlers, auth *middleware.Authenticator) {
+	// 设置 .well-known 路由（JWKS 等）
+	setupWellKnownRoutes(r, h)
+
+	// 设置 OAuth2 授权服务器协议端点
+	setupOAuthRoutes(r, h, auth)
+
 	// 设置 v1 版本路由
-	v1RouterGroup := setupV1RouterGroup(r)
+	v1RouterGroup := setupV1RouterGroup(r, auth)
 	setupV1Routes(v1RouterGroup, h)
 
 	// 设置资源路由（包括 Swagger UI）
@@ -27,17 +36,25 @@ func SetupRouter(r *gin.Engine, h *handler.Handlers) {
 }
 
 // setupV1RouterGroup 初始化 v1 版本路由组
-func setupV1RouterGroup(r *gin.Engine) *VersionedRouterGroup {
+func setupV1RouterGroup(r *gin.Engine, auth *middleware.Authenticator) *VersionedRouterGroup {
 	apiGroup := r.Group("/api")
 	v1Group := apiGroup.Group("/v1")
 
 	public := v1Group.Group("")
 	private := v1Group.Group("")
-	private.Use(middleware.JWTAuthMiddleware()) // JWT认证中间件
+	private.Use(auth.Required()) // JWT认证中间件
+
+	account := private.Group("")
+	account.Use(middleware.RejectAPIKey()) // 只允许用户本人通过登录令牌操作
+
+	admin := private.Group("/admin")
+	admin.Use(middleware.RequireRole(user.RoleAdmin)) // 管理员角色校验
 
 	return &VersionedRouterGroup{
 		PublicRouterGroup:  public,
 		PrivateRouterGroup: private,
+		AccountRouterGroup: account,
+		AdminRouterGroup:   admin,
 	}
 }
 
@@ -45,4 +62,13 @@ func setupV1RouterGroup(r *gin.Engine) *VersionedRouterGroup {
 func setupV1Routes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
 	setupV1HelloWorldRoutes(routerGroup, h)
 	setupV1UserRoutes(routerGroup, h)
+	setupV1TokenRoutes(routerGroup, h)
+	setupV1RoleRoutes(routerGroup, h)
+	setupV1APIKeyRoutes(routerGroup, h)
+	setupV1MFARoutes(routerGroup, h)
+	setupV1PasswordRoutes(routerGroup, h)
+	setupV1EmailRoutes(routerGroup, h)
+	setupV1SessionRoutes(routerGroup, h)
+	setupV1OAuthClientRoutes(routerGroup, h)
+	setupV1SSORoutes(routerGroup, h)
 }
@@ -0,0 +1,11 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1SessionRoutes 设置 v1 版本的登录会话路由
+func setupV1SessionRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Account routes - 账户路由，需要登录令牌认证，不允许使用 API Key
+	// 路径: /api/v1/user/sessions，DELETE /api/v1/user/sessions/others 吊销其他所有会话
+	routerGroup.AccountRouterGroup.GET("/user/sessions", h.SessionHandler.ListSessions())
+	routerGroup.AccountRouterGroup.DELETE("/user/sessions/:id", h.SessionHandler.RevokeSession())
+}
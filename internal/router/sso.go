@@ -0,0 +1,12 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1SSORoutes 设置 v1 版本的外部身份登录路由
+func setupV1SSORoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Public routes - 公开路由，无需认证
+	// 路径: GET /api/v1/auth/providers, GET /api/v1/auth/:provider/login, GET /api/v1/auth/:provider/callback
+	routerGroup.PublicRouterGroup.GET("/auth/providers", h.SSOHandler.ListProviders())
+	routerGroup.PublicRouterGroup.GET("/auth/:provider/login", h.SSOHandler.Login())
+	routerGroup.PublicRouterGroup.GET("/auth/:provider/callback", h.SSOHandler.Callback())
+}
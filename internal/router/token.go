@@ -0,0 +1,14 @@
+package router
+
+import "github.com/HoronLee/GinHub/internal/handler"
+
+// setupV1TokenRoutes 设置 v1 版本的令牌路由
+func setupV1TokenRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Public routes - 公开路由，无需认证
+	// 路径: POST /api/v1/token/refresh
+	routerGroup.PublicRouterGroup.POST("/token/refresh", h.TokenHandler.RefreshToken())
+
+	// Private routes - 私有路由，需要 JWT 认证
+	// 路径: POST /api/v1/logout
+	routerGroup.PrivateRouterGroup.POST("/logout", h.TokenHandler.Logout())
+}
@@ -1,6 +1,10 @@
 package router
 
-import "github.com/HoronLee/GinHub/internal/handler"
+import (
+	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/HoronLee/GinHub/internal/middleware"
+	"github.com/HoronLee/GinHub/internal/model/user"
+)
 
 // setupV1UserRoutes 设置 v1 版本的用户路由
 func setupV1UserRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
@@ -10,6 +14,26 @@ func setupV1UserRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
 	routerGroup.PublicRouterGroup.POST("/login", h.UserHandler.Login())
 
 	// Private routes - 私有路由，需要 JWT 认证
-	// 路径: DELETE /api/v1/user
-	routerGroup.PrivateRouterGroup.DELETE("/user", h.UserHandler.DeleteUser())
+	// 路径: GET /api/v1/user/me, GET /api/v1/users/:id
+	routerGroup.PrivateRouterGroup.GET("/user/me", h.UserHandler.GetMe())
+	routerGroup.PrivateRouterGroup.GET("/users/:id", h.UserHandler.GetUser())
+
+	// Account routes - 账户路由，需要登录令牌认证，不允许使用 API Key
+	// 路径: DELETE /api/v1/user, PATCH /api/v1/user/me
+	routerGroup.AccountRouterGroup.DELETE("/user", h.UserHandler.DeleteUser())
+	routerGroup.AccountRouterGroup.PATCH("/user/me", h.UserHandler.UpdateMe())
+
+	// Admin routes - 管理员路由，需要 JWT 认证、管理员角色和相应的 user:* 权限
+	// 路径: GET /api/v1/admin/users, DELETE /api/v1/admin/users/:id, POST /api/v1/admin/users/:id/restore,
+	//       POST /api/v1/admin/users/:id/{unlock,disable,enable,password-reset}
+	users := routerGroup.AdminRouterGroup.Group("/users")
+	users.GET("", middleware.RequirePermission(user.PermissionUserRead), h.UserHandler.ListUsers())
+	users.DELETE("/:id", middleware.RequirePermission(user.PermissionUserDelete), h.UserHandler.AdminDeleteUser())
+	users.POST("/:id/restore", middleware.RequirePermission(user.PermissionUserDelete), h.UserHandler.RestoreUser())
+
+	update := users.Group("", middleware.RequirePermission(user.PermissionUserUpdate))
+	update.POST("/:id/unlock", h.UserHandler.UnlockUser())
+	update.POST("/:id/disable", h.UserHandler.DisableUser())
+	update.POST("/:id/enable", h.UserHandler.EnableUser())
+	update.POST("/:id/password-reset", h.PasswordResetHandler.ForceReset())
 }
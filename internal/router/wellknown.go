@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/HoronLee/GinHub/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// setupWellKnownRoutes 设置 /.well-known 路由
+// 这些路径由 RFC 约定，不挂载在 /api 版本前缀下
+func setupWellKnownRoutes(r *gin.Engine, h *handler.Handlers) {
+	wellKnown := r.Group("/.well-known")
+
+	// 路径: GET /.well-known/jwks.json
+	wellKnown.GET("/jwks.json", h.TokenHandler.JWKS())
+
+	// 路径: GET /.well-known/openid-configuration
+	wellKnown.GET("/openid-configuration", h.OAuthHandler.Discovery())
+}
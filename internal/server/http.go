@@ -22,6 +22,7 @@ import (
 	"github.com/HoronLee/GinHub/internal/handler"
 	"github.com/HoronLee/GinHub/internal/middleware"
 	"github.com/HoronLee/GinHub/internal/router"
+	"github.com/HoronLee/GinHub/internal/service"
 	util "github.com/HoronLee/GinHub/internal/util/log"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -35,6 +36,8 @@ type HTTPServer struct {
 	handlers   *handler.Handlers
 	db         *gorm.DB
 	logger     *util.Logger
+	auth       *middleware.Authenticator
+	purger     *service.UserPurger
 }
 
 func NewHTTPServer(
@@ -42,6 +45,8 @@ func NewHTTPServer(
 	handlers *handler.Handlers,
 	db *gorm.DB,
 	logger *util.Logger,
+	auth *middleware.Authenticator,
+	purger *service.UserPurger,
 ) *HTTPServer {
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -55,6 +60,8 @@ func NewHTTPServer(
 	engine := gin.New()
 	engine.Use(middleware.Logger(logger))
 	engine.Use(middleware.Recovery(logger))
+	engine.Use(middleware.ClientInfo())
+	engine.Use(middleware.ReadYourWrites())
 
 	return &HTTPServer{
 		cfg:      cfg,
@@ -62,11 +69,13 @@ func NewHTTPServer(
 		handlers: handlers,
 		db:       db,
 		logger:   logger,
+		auth:     auth,
+		purger:   purger,
 	}
 }
 
 func (s *HTTPServer) Start() error {
-	router.SetupRouter(s.engine, s.handlers)
+	router.SetupRouter(s.engine, s.handlers, s.auth)
 
 	addr := fmt.Sprintf("%s:%s", s.cfg.Server.Host, s.cfg.Server.Port)
 	s.httpServer = &http.Server{
@@ -76,6 +85,9 @@ func (s *HTTPServer) Start() error {
 
 	s.logger.Info("Server starting", zap.String("addr", addr))
 
+	// 启动后台任务
+	s.purger.Start()
+
 	go func() {
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			s.logger.Fatal("Failed to start server", zap.Error(err))
@@ -87,6 +99,7 @@ func (s *HTTPServer) Start() error {
 
 func (s *HTTPServer) Stop(ctx context.Context) error {
 	s.logger.Info("Shutting down server...")
+	s.purger.Stop()
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
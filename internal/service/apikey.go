@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAPIKey API Key 不存在、已过期或已被吊销
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyNotFound 要操作的 API Key 不存在
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrScopeNotGranted 请求的权限范围超出了调用方拥有的权限
+	ErrScopeNotGranted = errors.New("scope not granted")
+)
+
+// APIKeyRepo 定义 API Key 数据访问接口
+type APIKeyRepo interface {
+	CreateAPIKey(ctx context.Context, key *user.APIKey) error
+	ListUserAPIKeys(ctx context.Context, userID uint) ([]user.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*user.APIKey, error)
+	// RevokeAPIKey 吊销用户的 API Key，未找到可吊销的密钥时返回 false
+	RevokeAPIKey(ctx context.Context, userID, id uint) (bool, error)
+	// RevokeUserAPIKeys 吊销用户全部未吊销的 API Key
+	RevokeUserAPIKeys(ctx context.Context, userID uint) error
+	UpdateAPIKeyLastUsed(ctx context.Context, id uint, at time.Time) error
+}
+
+// APIKeyService API Key 服务
+type APIKeyService struct {
+	repo             APIKeyRepo
+	userRepo         UserRepo
+	roleRepo         RoleRepo
+	lastUsedInterval time.Duration
+}
+
+// NewAPIKeyService 创建APIKeyService实例（通过Wire注入）
+func NewAPIKeyService(cfg *config.AppConfig, repo APIKeyRepo, userRepo UserRepo, roleRepo RoleRepo) *APIKeyService {
+	return &APIKeyService{
+		repo:             repo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		lastUsedInterval: time.Duration(cfg.Auth.APIKey.LastUsedInterval) * time.Second,
+	}
+}
+
+// CreateAPIKey 为调用方创建 API Key，完整密钥只在返回值中出现一次
+// 调用方通过 API Key 认证时，新密钥必须指定权限范围且不能超出调用方密钥的权限范围，避免受限的密钥创建不受限的密钥
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, caller *user.Claims, req user.CreateAPIKeyRequest) (*user.CreateAPIKeyResponse, error) {
+	userID := caller.UserID
+
+	// 1. 权限范围不能超出用户当前拥有的权限
+	_, permissions, err := loadUserRoles(ctx, s.roleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	granted := &user.Claims{Permissions: permissions}
+	if caller.IsAPIKey() && len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: scopes are required when authenticated with an api key", ErrScopeNotGranted)
+	}
+	for _, scope := range req.Scopes {
+		if !granted.HasPermission(scope) || (caller.IsAPIKey() && !caller.HasPermission(scope)) {
+			return nil, fmt.Errorf("%w: %s", ErrScopeNotGranted, scope)
+		}
+	}
+
+	// 2. 生成密钥
+	prefix, err := cryptoUtil.GenerateRandomID(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := user.APIKeyPrefix + "_" + prefix + "_" + secret
+
+	// 3. 保存密钥摘要
+	apiKey := &user.APIKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  prefix,
+		KeyHash: cryptoUtil.SHA256Hex(key),
+		Scopes:  req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &user.CreateAPIKeyResponse{
+		APIKeyResponse: user.NewAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+// ListAPIKeys 查询用户未吊销的 API Key
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uint) ([]user.APIKeyResponse, error) {
+	keys, err := s.repo.ListUserAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]user.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, user.NewAPIKeyResponse(&keys[i]))
+	}
+	return resp, nil
+}
+
+// RevokeAPIKey 吊销用户的 API Key
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, id uint) error {
+	revoked, err := s.repo.RevokeAPIKey(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ValidateAPIKey 验证 API Key 并返回与 JWT 相同结构的用户 claims
+// 配置了权限范围的密钥只保留用户当前仍拥有的那部分权限
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, key string) (*user.Claims, error) {
+	if !strings.HasPrefix(key, user.APIKeyPrefix+"_") {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// 1. 查询并校验密钥状态
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, cryptoUtil.SHA256Hex(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// 2. 加载密钥所属用户及其角色
+	u, err := s.userRepo.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	// 被禁用或被要求重置密码的用户在处理前不能使用 API Key
+	if u.IsDisabled() || u.PasswordResetRequired {
+		return nil, ErrInvalidAPIKey
+	}
+	roles, permissions, err := loadUserRoles(ctx, s.roleRepo, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &user.Claims{
+		UserID:      u.ID,
+		Username:    u.Username,
+		Roles:       roles,
+		Permissions: permissions,
+		AuthMethod:  user.AuthMethodAPIKey,
+	}
+	claims.Subject = u.Username
+	if len(apiKey.Scopes) > 0 {
+		scoped := make([]string, 0, len(apiKey.Scopes))
+		for _, scope := range apiKey.Scopes {
+			if claims.HasPermission(scope) {
+				scoped = append(scoped, scope)
+			}
+		}
+		claims.Permissions = scoped
+	}
+
+	// 3. 按间隔更新最近使用时间，避免每个请求都写数据库
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= s.lastUsedInterval {
+		if err := s.repo.UpdateAPIKeyLastUsed(ctx, apiKey.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
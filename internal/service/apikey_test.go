@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAPIKeyService 创建基于内存 SQLite 的 APIKeyService
+func newTestAPIKeyService(t *testing.T) (*service.APIKeyService, *tokenTestEnv) {
+	env := newTokenTestEnv(t)
+	env.cfg.Auth.APIKey.LastUsedInterval = 300
+	svc := service.NewAPIKeyService(env.cfg, data.NewAPIKeyRepo(env.data), data.NewUserRepo(env.data), env.roleRepo)
+	return svc, env
+}
+
+func TestAPIKeyServiceLifecycle(t *testing.T) {
+	svc, env := newTestAPIKeyService(t)
+	ctx := context.Background()
+	require.NoError(t, env.roleRepo.AssignRole(ctx, env.user.ID, user.RoleUser))
+
+	created, err := svc.CreateAPIKey(ctx, &user.Claims{UserID: env.user.ID}, user.CreateAPIKeyRequest{Name: "ci"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, user.APIKeyPrefix+"_"+created.Prefix+"_"))
+
+	// API Key 解析为与 JWT 相同的用户身份
+	claims, err := svc.ValidateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, env.user.ID, claims.UserID)
+	assert.Equal(t, env.user.Username, claims.Username)
+	assert.Equal(t, []string{user.RoleUser}, claims.Roles)
+
+	// 列表中不包含密钥本身，并记录了最近使用时间
+	keys, err := svc.ListAPIKeys(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.Prefix, keys[0].Prefix)
+	assert.NotNil(t, keys[0].LastUsedAt)
+
+	// 吊销后立即失效
+	require.NoError(t, svc.RevokeAPIKey(ctx, env.user.ID, created.ID))
+	_, err = svc.ValidateAPIKey(ctx, created.Key)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	assert.ErrorIs(t, svc.RevokeAPIKey(ctx, env.user.ID, created.ID), service.ErrAPIKeyNotFound)
+
+	_, err = svc.ValidateAPIKey(ctx, "ghk_unknown_key")
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	_, err = svc.ValidateAPIKey(ctx, "not-an-api-key")
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyServiceScopes(t *testing.T) {
+	svc, env := newTestAPIKeyService(t)
+	ctx := context.Background()
+	require.NoError(t, env.roleRepo.AssignRole(ctx, env.user.ID, user.RoleAdmin))
+
+	created, err := svc.CreateAPIKey(ctx, &user.Claims{UserID: env.user.ID}, user.CreateAPIKeyRequest{
+		Name:   "read-only",
+		Scopes: []string{user.PermissionUserRead},
+	})
+	require.NoError(t, err)
+
+	// 权限被限制在密钥的权限范围内
+	claims, err := svc.ValidateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, claims.HasPermission(user.PermissionUserRead))
+	assert.False(t, claims.HasPermission(user.PermissionRoleManage))
+
+	// 用户失去权限后，密钥的权限随之收回
+	require.NoError(t, env.roleRepo.RemoveRole(ctx, env.user.ID, user.RoleAdmin))
+	claims, err = svc.ValidateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Empty(t, claims.Permissions)
+
+	// 不能创建超出自身权限的密钥
+	_, err = svc.CreateAPIKey(ctx, &user.Claims{UserID: env.user.ID}, user.CreateAPIKeyRequest{
+		Name:   "escalate",
+		Scopes: []string{user.PermissionRoleManage},
+	})
+	assert.ErrorIs(t, err, service.ErrScopeNotGranted)
+}
+
+func TestAPIKeyServiceKeyCannotEscalate(t *testing.T) {
+	svc, env := newTestAPIKeyService(t)
+	ctx := context.Background()
+	require.NoError(t, env.roleRepo.AssignRole(ctx, env.user.ID, user.RoleAdmin))
+
+	created, err := svc.CreateAPIKey(ctx, &user.Claims{UserID: env.user.ID}, user.CreateAPIKeyRequest{
+		Name:   "read-only",
+		Scopes: []string{user.PermissionUserRead},
+	})
+	require.NoError(t, err)
+	caller, err := svc.ValidateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, caller.IsAPIKey())
+
+	// 受限的密钥不能创建不受限或权限更大的密钥，即使用户本身拥有这些权限
+	_, err = svc.CreateAPIKey(ctx, caller, user.CreateAPIKeyRequest{Name: "unscoped"})
+	assert.ErrorIs(t, err, service.ErrScopeNotGranted)
+	_, err = svc.CreateAPIKey(ctx, caller, user.CreateAPIKeyRequest{Name: "wider", Scopes: []string{user.PermissionUserDelete}})
+	assert.ErrorIs(t, err, service.ErrScopeNotGranted)
+
+	// 权限范围内的密钥可以创建
+	_, err = svc.CreateAPIKey(ctx, caller, user.CreateAPIKeyRequest{Name: "narrow", Scopes: []string{user.PermissionUserRead}})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	"github.com/HoronLee/GinHub/internal/util/mail"
+	"gorm.io/gorm"
+)
+
+// defaultVerificationExpires 未配置时邮箱验证令牌的有效期
+const defaultVerificationExpires = 24 * time.Hour
+
+var (
+	// ErrInvalidVerificationToken 验证令牌不存在、已过期、已被使用或邮箱已变更
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	// ErrEmailTaken 邮箱已被其他用户使用
+	ErrEmailTaken = errors.New("email already in use")
+	// ErrEmailNotVerified 配置要求验证邮箱后才能登录
+	ErrEmailNotVerified = errors.New("email not verified")
+)
+
+// EmailVerificationRepo 定义邮箱验证令牌数据访问接口
+type EmailVerificationRepo interface {
+	CreateVerificationToken(ctx context.Context, t *user.EmailVerificationToken) error
+	// ConsumeVerificationToken 将未使用且未过期的验证令牌标记为已使用，令牌无效时返回 gorm.ErrRecordNotFound
+	ConsumeVerificationToken(ctx context.Context, tokenHash string) (*user.EmailVerificationToken, error)
+	// DeleteUserVerificationTokens 删除用户的全部验证令牌
+	DeleteUserVerificationTokens(ctx context.Context, userID uint) error
+}
+
+// EmailVerificationService 邮箱验证服务
+type EmailVerificationService struct {
+	repo     EmailVerificationRepo
+	userRepo UserRepo
+	limiter  *mailLimiter
+	mailer   mail.Mailer
+	required bool
+	expires  time.Duration
+	url      string
+}
+
+// NewEmailVerificationService 创建EmailVerificationService实例（通过Wire注入）
+func NewEmailVerificationService(
+	cfg *config.AppConfig,
+	repo EmailVerificationRepo,
+	userRepo UserRepo,
+	attempts LoginAttemptStore,
+	mailer mail.Mailer,
+) *EmailVerificationService {
+	verifyCfg := cfg.Auth.EmailVerification
+	expires := time.Duration(verifyCfg.Expires) * time.Second
+	if expires <= 0 {
+		expires = defaultVerificationExpires
+	}
+
+	return &EmailVerificationService{
+		repo:     repo,
+		userRepo: userRepo,
+		limiter:  newMailLimiter(attempts, "verify", verifyCfg.UserLimit, verifyCfg.IPLimit),
+		mailer:   mailer,
+		required: verifyCfg.Required,
+		expires:  expires,
+		url:      verifyCfg.URL,
+	}
+}
+
+// Required 返回是否要求验证邮箱后才能登录
+func (s *EmailVerificationService) Required() bool {
+	return s.required
+}
+
+// SendVerification 为用户当前的邮箱签发验证令牌并在后台发送验证邮件
+// 同一用户只保留最新的验证令牌
+func (s *EmailVerificationService) SendVerification(ctx context.Context, u *user.User) error {
+	if u.Email == nil || u.EmailVerified() {
+		return nil
+	}
+
+	if err := s.repo.DeleteUserVerificationTokens(ctx, u.ID); err != nil {
+		return err
+	}
+	token, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	err = s.repo.CreateVerificationToken(ctx, &user.EmailVerificationToken{
+		UserID:    u.ID,
+		Email:     *u.Email,
+		TokenHash: cryptoUtil.SHA256Hex(token),
+		ExpiresAt: time.Now().Add(s.expires),
+	})
+	if err != nil {
+		return err
+	}
+
+	sendMailAsync(ctx, s.mailer, &mail.Message{
+		To:      []string{*u.Email},
+		Subject: "Verify your GinHub email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address using the link below "+
+			"within %d hours:\n\n%s\n\nIf you did not create a GinHub account, you can ignore this email.\n",
+			u.Username, int(s.expires.Hours()), mailLink(s.url, token)),
+	})
+	return nil
+}
+
+// Verify 使用验证令牌确认邮箱
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	vt, err := s.repo.ConsumeVerificationToken(ctx, cryptoUtil.SHA256Hex(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	// 令牌签发后用户更换了邮箱时，旧地址的令牌不再生效
+	if err := s.userRepo.MarkEmailVerified(ctx, vt.UserID, vt.Email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return nil
+}
+
+// Resend 重新发送验证邮件
+// 邮箱不存在、已验证或请求过于频繁时同样返回成功，避免泄露账户是否存在
+func (s *EmailVerificationService) Resend(ctx context.Context, email string) error {
+	if ok, err := s.limiter.allowIP(ctx); err != nil || !ok {
+		return err
+	}
+	u, err := s.userRepo.GetUserByEmail(ctx, user.NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.Email == nil || u.EmailVerified() {
+		return nil
+	}
+	if ok, err := s.limiter.allowUser(ctx, u.ID); err != nil || !ok {
+		return err
+	}
+	return s.SendVerification(ctx, u)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/util/request"
+)
+
+const (
+	// defaultLoginWindow 未配置时失败计数的统计窗口
+	defaultLoginWindow = 15 * time.Minute
+	// defaultLockoutDuration 未配置时账户或IP的锁定时长
+	defaultLockoutDuration = 15 * time.Minute
+	// maxBackoffShift 退避等待翻倍次数的上限，防止位移溢出
+	maxBackoffShift = 30
+)
+
+// ErrLoginThrottled 登录失败次数过多，需要等待后重试
+// 无论用户名是否存在都返回该错误，避免泄露账户是否存在
+var ErrLoginThrottled = errors.New("too many failed login attempts, please try again later")
+
+// ThrottledError 登录被限制时返回的错误，RetryAfter 为需要等待的时长
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return ErrLoginThrottled.Error()
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// LoginAttemptStore 定义登录失败计数的存储接口，也用于限制发送邮件的频率
+// 以用户名或客户端 IP 为键，超过过期时间没有新的失败记录则计数清零
+type LoginAttemptStore interface {
+	// Get 查询指定键的失败计数，不存在或已过期时返回 nil
+	Get(ctx context.Context, key string) (*user.LoginAttempt, error)
+	// RecordFailure 记录一次失败并返回累加后的计数，ttl 为计数的保留时长
+	RecordFailure(ctx context.Context, key string, now time.Time, ttl time.Duration) (*user.LoginAttempt, error)
+	// Reset 清除指定键的失败计数
+	Reset(ctx context.Context, key string) error
+	// DeleteExpired 清理已过期的失败计数，返回清理的条数
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// LoginThrottle 登录限流服务
+// 同一用户名和同一 IP 的失败次数超过 FreeAttempts 后按指数退避等待，
+// 用户名失败达到 MaxAttempts 后锁定账户，IP 失败达到 IPMaxAttempts 后锁定该 IP
+type LoginThrottle struct {
+	store         LoginAttemptStore
+	userRepo      UserRepo
+	window        time.Duration
+	freeAttempts  int
+	backoffBase   time.Duration
+	backoffMax    time.Duration
+	maxAttempts   int
+	ipMaxAttempts int
+	lockout       time.Duration
+}
+
+// NewLoginThrottle 创建LoginThrottle实例（通过Wire注入）
+func NewLoginThrottle(cfg *config.AppConfig, store LoginAttemptStore, userRepo UserRepo) *LoginThrottle {
+	loginCfg := cfg.Auth.Login
+	window := time.Duration(loginCfg.Window) * time.Second
+	if window <= 0 {
+		window = defaultLoginWindow
+	}
+	lockout := time.Duration(loginCfg.LockoutDuration) * time.Second
+	if lockout <= 0 {
+		lockout = defaultLockoutDuration
+	}
+
+	return &LoginThrottle{
+		store:         store,
+		userRepo:      userRepo,
+		window:        window,
+		freeAttempts:  loginCfg.FreeAttempts,
+		backoffBase:   time.Duration(loginCfg.BackoffBase) * time.Second,
+		backoffMax:    time.Duration(loginCfg.BackoffMax) * time.Second,
+		maxAttempts:   loginCfg.MaxAttempts,
+		ipMaxAttempts: loginCfg.IPMaxAttempts,
+		lockout:       lockout,
+	}
+}
+
+// Check 检查用户名和客户端 IP 是否允许登录，被限制时返回 *ThrottledError
+func (t *LoginThrottle) Check(ctx context.Context, username string) error {
+	now := time.Now()
+	var until time.Time
+
+	for key, maxAttempts := range t.keys(ctx, username) {
+		attempt, err := t.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if blocked := t.blockedUntil(attempt, maxAttempts); blocked.After(until) {
+			until = blocked
+		}
+	}
+
+	if now.Before(until) {
+		return &ThrottledError{RetryAfter: until.Sub(now)}
+	}
+	return nil
+}
+
+// CheckUser 检查账户是否处于锁定状态，用于多实例部署时内存计数未同步的情况
+func (t *LoginThrottle) CheckUser(u *user.User) error {
+	now := time.Now()
+	if u.IsLocked(now) {
+		return &ThrottledError{RetryAfter: u.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，u 为 nil 表示用户名不存在
+// 账户连续失败达到阈值时写入锁定时间
+func (t *LoginThrottle) RecordFailure(ctx context.Context, username string, u *user.User) error {
+	now := time.Now()
+	ttl := max(t.window, t.lockout)
+
+	var userAttempt *user.LoginAttempt
+	for key := range t.keys(ctx, username) {
+		attempt, err := t.store.RecordFailure(ctx, key, now, ttl)
+		if err != nil {
+			return err
+		}
+		if key == userKey(username) {
+			userAttempt = attempt
+		}
+	}
+
+	if u == nil || userAttempt == nil {
+		return nil
+	}
+	var lockedUntil *time.Time
+	if t.maxAttempts > 0 && userAttempt.Failures >= t.maxAttempts {
+		until := now.Add(t.lockout)
+		lockedUntil = &until
+	}
+	return t.userRepo.UpdateLoginState(ctx, u.ID, userAttempt.Failures, lockedUntil)
+}
+
+// RecordMFAFailure 记录一次两步验证失败，与密码错误一样计入用户名和 IP 的失败次数，
+// 同时单独统计挑战令牌 challengeID 的失败次数并返回，ttl 为挑战令牌的剩余有效期
+func (t *LoginThrottle) RecordMFAFailure(ctx context.Context, u *user.User, challengeID string, ttl time.Duration) (int, error) {
+	if err := t.RecordFailure(ctx, u.Username, u); err != nil {
+		return 0, err
+	}
+	attempt, err := t.store.RecordFailure(ctx, "mfa:"+challengeID, time.Now(), ttl)
+	if err != nil {
+		return 0, err
+	}
+	return attempt.Failures, nil
+}
+
+// RecordSuccess 登录成功后清除该用户名的失败计数，开启了两步验证的用户在通过两步验证后才调用
+// IP 的失败计数不清除，避免攻击者用自己的账户重置计数
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, u *user.User) error {
+	if err := t.store.Reset(ctx, userKey(u.Username)); err != nil {
+		return err
+	}
+	if u.FailedLoginCount == 0 && u.LockedUntil == nil {
+		return nil
+	}
+	return t.userRepo.UpdateLoginState(ctx, u.ID, 0, nil)
+}
+
+// Unlock 解除账户锁定并清除该用户名的失败计数
+func (t *LoginThrottle) Unlock(ctx context.Context, u *user.User) error {
+	if err := t.store.Reset(ctx, userKey(u.Username)); err != nil {
+		return err
+	}
+	return t.userRepo.UpdateLoginState(ctx, u.ID, 0, nil)
+}
+
+// keys 返回需要计数的键及其锁定阈值
+func (t *LoginThrottle) keys(ctx context.Context, username string) map[string]int {
+	keys := map[string]int{userKey(username): t.maxAttempts}
+	if ip := request.FromContext(ctx).IP; ip != "" {
+		keys["ip:"+ip] = t.ipMaxAttempts
+	}
+	return keys
+}
+
+// blockedUntil 计算失败计数对应的解除限制时间
+func (t *LoginThrottle) blockedUntil(attempt *user.LoginAttempt, maxAttempts int) time.Time {
+	if attempt == nil || attempt.Failures <= t.freeAttempts {
+		return time.Time{}
+	}
+	if maxAttempts > 0 && attempt.Failures >= maxAttempts {
+		return attempt.LastFailedAt.Add(t.lockout)
+	}
+	if t.backoffBase <= 0 {
+		return time.Time{}
+	}
+
+	// 超过免等待次数后，每次失败等待时间翻倍
+	shift := min(attempt.Failures-t.freeAttempts-1, maxBackoffShift)
+	delay := t.backoffBase << shift
+	if t.backoffMax > 0 && delay > t.backoffMax {
+		delay = t.backoffMax
+	}
+	return attempt.LastFailedAt.Add(delay)
+}
+
+// userKey 返回用户名的计数键，忽略大小写以防止通过大小写变体绕过计数
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
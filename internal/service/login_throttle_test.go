@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newThrottledUserService 创建启用登录限流的 UserService，configure 用于调整限流配置
+func newThrottledUserService(t *testing.T, store string, configure func(*tokenTestEnv)) (*service.UserService, *tokenTestEnv) {
+	t.Helper()
+
+	env := newTokenTestEnv(t)
+	env.cfg.Auth.Login.Store = store
+	configure(env)
+
+	svc := newUserTestEnv(t, env, nil).userSvc
+
+	ctx := context.Background()
+	require.NoError(t, svc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123"}))
+	return svc, env
+}
+
+// clientContext 返回携带客户端 IP 的上下文
+func clientContext(ip string) context.Context {
+	return request.NewContext(context.Background(), &request.ClientInfo{IP: ip})
+}
+
+func TestLoginBackoff(t *testing.T) {
+	for _, store := range []string{"memory", "database"} {
+		t.Run(store, func(t *testing.T) {
+			svc, _ := newThrottledUserService(t, store, func(env *tokenTestEnv) {
+				env.cfg.Auth.Login.FreeAttempts = 2
+				env.cfg.Auth.Login.BackoffBase = 60
+				env.cfg.Auth.Login.BackoffMax = 300
+			})
+
+			// 已存在和不存在的用户名得到相同的结果
+			for i, username := range []string{"alice", "ghost"} {
+				ctx := clientContext(fmt.Sprintf("10.0.0.%d", i+1))
+				for n := 0; n < 3; n++ {
+					_, err := svc.Login(ctx, user.LoginRequest{Username: username, Password: "wrong"})
+					require.EqualError(t, err, "invalid username or password", "%s attempt %d", username, n+1)
+				}
+
+				// 超过免等待次数后，即使密码正确也需要等待
+				_, err := svc.Login(ctx, user.LoginRequest{Username: username, Password: "password123"})
+				var throttled *service.ThrottledError
+				require.ErrorAs(t, err, &throttled, username)
+				assert.ErrorIs(t, err, service.ErrLoginThrottled)
+				assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter.Seconds(), 1)
+			}
+
+			// 用户名计数忽略大小写
+			_, err := svc.Login(clientContext("10.0.0.9"), user.LoginRequest{Username: "ALICE", Password: "password123"})
+			assert.ErrorIs(t, err, service.ErrLoginThrottled)
+		})
+	}
+}
+
+func TestLoginLockoutAndUnlock(t *testing.T) {
+	for _, store := range []string{"memory", "database"} {
+		t.Run(store, func(t *testing.T) {
+			svc, env := newThrottledUserService(t, store, func(env *tokenTestEnv) {
+				env.cfg.Auth.Login.FreeAttempts = 2
+				env.cfg.Auth.Login.MaxAttempts = 3
+				env.cfg.Auth.Login.LockoutDuration = 600
+			})
+			userRepo := data.NewUserRepo(env.data)
+
+			// 每次从不同 IP 尝试，验证锁定与 IP 无关
+			for i := 0; i < 3; i++ {
+				_, err := svc.Login(clientContext(fmt.Sprintf("10.0.1.%d", i+1)), user.LoginRequest{Username: "alice", Password: "wrong"})
+				require.EqualError(t, err, "invalid username or password")
+			}
+
+			alice, err := userRepo.GetUserByUsername(context.Background(), "alice")
+			require.NoError(t, err)
+			assert.Equal(t, 3, alice.FailedLoginCount)
+			require.NotNil(t, alice.LockedUntil)
+			assert.True(t, alice.IsLocked(time.Now()))
+
+			_, err = svc.Login(clientContext("10.0.1.9"), user.LoginRequest{Username: "alice", Password: "password123"})
+			var throttled *service.ThrottledError
+			require.ErrorAs(t, err, &throttled)
+			assert.InDelta(t, (10 * time.Minute).Seconds(), throttled.RetryAfter.Seconds(), 1)
+
+			// 管理员解除锁定后可以正常登录
+			require.NoError(t, svc.UnlockUser(context.Background(), alice.ID))
+			resp, err := svc.Login(clientContext("10.0.1.9"), user.LoginRequest{Username: "alice", Password: "password123"})
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.Token)
+
+			alice, err = userRepo.GetUserByID(context.Background(), alice.ID)
+			require.NoError(t, err)
+			assert.Zero(t, alice.FailedLoginCount)
+			assert.Nil(t, alice.LockedUntil)
+		})
+	}
+}
+
+func TestLoginIPLockout(t *testing.T) {
+	svc, _ := newThrottledUserService(t, "memory", func(env *tokenTestEnv) {
+		env.cfg.Auth.Login.IPMaxAttempts = 3
+	})
+	ctx := clientContext("10.0.2.1")
+
+	// 同一 IP 尝试不同的用户名
+	for _, username := range []string{"bob", "carol", "dave"} {
+		_, err := svc.Login(ctx, user.LoginRequest{Username: username, Password: "wrong"})
+		require.EqualError(t, err, "invalid username or password")
+	}
+
+	_, err := svc.Login(ctx, user.LoginRequest{Username: "alice", Password: "password123"})
+	assert.ErrorIs(t, err, service.ErrLoginThrottled)
+
+	// 其他 IP 不受影响
+	_, err = svc.Login(clientContext("10.0.2.2"), user.LoginRequest{Username: "alice", Password: "password123"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/mail"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"go.uber.org/zap"
+)
+
+const (
+	// mailTimeout 后台发送邮件的超时时间
+	mailTimeout = 30 * time.Second
+	// mailLimitWindow 统计邮件请求次数的窗口，超过该时间没有新的请求则计数清零
+	mailLimitWindow = time.Hour
+	// defaultMailUserLimit 未配置时同一账户每小时最多发送的邮件数
+	defaultMailUserLimit = 3
+	// defaultMailIPLimit 未配置时同一 IP 每小时最多请求发送邮件的次数
+	defaultMailIPLimit = 10
+)
+
+// NewMailer 根据配置创建邮件发送器（通过Wire注入）
+func NewMailer(cfg *config.AppConfig, logger *util.Logger) (mail.Mailer, error) {
+	mailCfg := cfg.Mail
+	switch mailCfg.Driver {
+	case "log", "":
+		return mail.NewLogMailer(logger.Logger), nil
+	case "file":
+		return mail.NewFileMailer(mailCfg.From, mailCfg.Dir)
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     mailCfg.SMTP.Host,
+			Port:     mailCfg.SMTP.Port,
+			Username: mailCfg.SMTP.Username,
+			Password: mailCfg.SMTP.Password,
+			From:     mailCfg.From,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", mailCfg.Driver)
+	}
+}
+
+// sendMailAsync 在后台发送邮件，失败只记录日志
+// 发送耗时不计入请求响应时间，避免通过响应时间判断账户是否存在
+func sendMailAsync(ctx context.Context, mailer mail.Mailer, msg *mail.Message) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+		defer cancel()
+		if err := mailer.Send(ctx, msg); err != nil {
+			util.GetLogger().Error("Failed to send mail", zap.String("subject", msg.Subject), zap.Error(err))
+		}
+	}()
+}
+
+// mailLink 生成邮件中的链接，urlTemplate 为空时只返回令牌
+func mailLink(urlTemplate, token string) string {
+	if urlTemplate == "" {
+		return "Token: " + token
+	}
+	return strings.ReplaceAll(urlTemplate, "{token}", token)
+}
+
+// mailLimiter 限制公开接口按账户和客户端 IP 发送邮件的频率，防止被用于轰炸用户邮箱，
+// 计数保存在 LoginAttemptStore 中，kind 用于区分不同用途的计数键
+type mailLimiter struct {
+	store     LoginAttemptStore
+	kind      string
+	userLimit int
+	ipLimit   int
+}
+
+// newMailLimiter 创建 mailLimiter，limit 为 0 时使用默认值
+func newMailLimiter(store LoginAttemptStore, kind string, userLimit, ipLimit int) *mailLimiter {
+	if userLimit <= 0 {
+		userLimit = defaultMailUserLimit
+	}
+	if ipLimit <= 0 {
+		ipLimit = defaultMailIPLimit
+	}
+	return &mailLimiter{store: store, kind: kind, userLimit: userLimit, ipLimit: ipLimit}
+}
+
+// allowIP 记录一次来自客户端 IP 的请求，超过上限时返回 false，在查询账户之前调用
+func (l *mailLimiter) allowIP(ctx context.Context) (bool, error) {
+	ip := request.FromContext(ctx).IP
+	if ip == "" {
+		return true, nil
+	}
+	return l.allow(ctx, l.kind+":ip:"+ip, l.ipLimit)
+}
+
+// allowUser 记录一次向用户发送邮件的请求，超过上限时返回 false
+func (l *mailLimiter) allowUser(ctx context.Context, userID uint) (bool, error) {
+	return l.allow(ctx, fmt.Sprintf("%s:user:%d", l.kind, userID), l.userLimit)
+}
+
+// allow 计数未达到 limit 时记录一次请求并返回 true，达到上限后不再记录，避免持续请求延长限制时间
+func (l *mailLimiter) allow(ctx context.Context, key string, limit int) (bool, error) {
+	attempt, err := l.store.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if attempt != nil && attempt.Failures >= limit {
+		util.GetLogger().Debug("Mail request rate limited", zap.String("key", key))
+		return false, nil
+	}
+	attempt, err = l.store.RecordFailure(ctx, key, time.Now(), mailLimitWindow)
+	if err != nil {
+		return false, err
+	}
+	return attempt.Failures <= limit, nil
+}
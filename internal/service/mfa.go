@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/HoronLee/GinHub/internal/util/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaAudience 两步验证挑战令牌的受众，与访问令牌区分
+	mfaAudience = "ginhub-mfa"
+	// totpSkew 校验验证码时允许的前后时间步偏差
+	totpSkew = 1
+	// defaultChallengeExpires 未配置时挑战令牌的默认有效期
+	defaultChallengeExpires = 5 * time.Minute
+	// defaultRecoveryCodes 未配置时生成的恢复码数量
+	defaultRecoveryCodes = 10
+	// defaultMFAMaxAttempts 未配置时每个挑战令牌允许输错验证码的次数
+	defaultMFAMaxAttempts = 5
+)
+
+var (
+	// ErrInvalidMFACode 验证码或恢复码错误，或验证码已被使用
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+	// ErrInvalidMFAToken 两步验证挑战令牌无效、已过期或已被使用
+	ErrInvalidMFAToken = errors.New("invalid mfa token")
+	// ErrMFAAlreadyEnabled 用户已开启两步验证
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrMFANotEnabled 用户未开启两步验证
+	ErrMFANotEnabled = errors.New("two-factor authentication not enabled")
+)
+
+// MFARepo 定义两步验证数据访问接口
+type MFARepo interface {
+	SetTOTPSecret(ctx context.Context, userID uint, secret string) error
+	// EnableTOTP 开启两步验证，记录已使用的验证码时间步，并替换用户的全部恢复码
+	EnableTOTP(ctx context.Context, userID uint, step int64, codeHashes []string) error
+	// DisableTOTP 关闭两步验证，清除共享密钥和恢复码
+	DisableTOTP(ctx context.Context, userID uint) error
+	// AdvanceTOTPStep 记录已使用的验证码时间步，时间步不大于已记录的值时返回 false
+	AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCode 将未使用的恢复码标记为已使用，恢复码不存在或已使用时返回 false
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+}
+
+// MFAService 两步验证服务
+type MFAService struct {
+	repo             MFARepo
+	userRepo         UserRepo
+	tokenSvc         *TokenService
+	revocations      RevocationStore
+	throttle         *LoginThrottle
+	challenges       *jwtutil.JWT[user.MFAClaims]
+	issuer           string
+	challengeExpires time.Duration
+	recoveryCodes    int
+	maxAttempts      int
+}
+
+// NewMFAService 创建MFAService实例（通过Wire注入）
+// 挑战令牌与访问令牌共享签名密钥，但使用独立的受众
+func NewMFAService(
+	cfg *config.AppConfig,
+	repo MFARepo,
+	userRepo UserRepo,
+	tokenSvc *TokenService,
+	revocations RevocationStore,
+	throttle *LoginThrottle,
+	jwtHelper *jwtutil.JWT[user.Claims],
+) *MFAService {
+	challengeExpires := time.Duration(cfg.Auth.MFA.ChallengeExpires) * time.Second
+	if challengeExpires <= 0 {
+		challengeExpires = defaultChallengeExpires
+	}
+	recoveryCodes := cfg.Auth.MFA.RecoveryCodes
+	if recoveryCodes <= 0 {
+		recoveryCodes = defaultRecoveryCodes
+	}
+	maxAttempts := cfg.Auth.MFA.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMFAMaxAttempts
+	}
+
+	return &MFAService{
+		repo:             repo,
+		userRepo:         userRepo,
+		tokenSvc:         tokenSvc,
+		revocations:      revocations,
+		throttle:         throttle,
+		challenges:       jwtutil.Derive[user.MFAClaims](jwtHelper, jwtutil.WithAudience(mfaAudience)),
+		issuer:           cfg.Auth.MFA.Issuer,
+		challengeExpires: challengeExpires,
+		recoveryCodes:    recoveryCodes,
+		maxAttempts:      maxAttempts,
+	}
+}
+
+// Setup 为用户生成新的 TOTP 共享密钥，需调用 Confirm 确认后才会开启两步验证
+func (s *MFAService) Setup(ctx context.Context, userID uint) (*user.TOTPSetupResponse, error) {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &user.TOTPSetupResponse{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, u.Username, secret),
+	}, nil
+}
+
+// Confirm 使用身份验证器生成的验证码确认绑定，开启两步验证并返回恢复码
+func (s *MFAService) Confirm(ctx context.Context, userID uint, code string) (*user.RecoveryCodesResponse, error) {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if u.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup not started")
+	}
+
+	step, ok := totp.Validate(u.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &user.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 关闭两步验证，必须提供当前有效的验证码
+func (s *MFAService) Disable(ctx context.Context, userID uint, code string) error {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.TOTPEnabled {
+		return ErrMFANotEnabled
+	}
+	if err := s.verifyTOTP(ctx, u, code); err != nil {
+		return err
+	}
+	return s.repo.DisableTOTP(ctx, userID)
+}
+
+// Challenge 为已通过密码验证的用户签发两步验证挑战令牌
+func (s *MFAService) Challenge(ctx context.Context, u *user.User) (string, error) {
+	jti, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.challenges.GenerateToken(&user.MFAClaims{
+		UserID: u.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.challengeExpires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    config.Config.Auth.Jwt.Issuer,
+			Audience:  []string{mfaAudience},
+			ID:        jti,
+		},
+	})
+}
+
+// VerifyLogin 使用挑战令牌和验证码（或恢复码）完成登录，签发访问令牌和刷新令牌
+// 挑战令牌在登录成功后失效；验证码错误计入登录失败次数，同一挑战令牌输错 maxAttempts 次后失效
+func (s *MFAService) VerifyLogin(ctx context.Context, mfaToken, code string) (*user.LoginResponse, error) {
+	// 1. 校验挑战令牌
+	claims, err := s.challenges.ParseToken(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidMFAToken
+	}
+
+	u, err := s.getUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.TOTPEnabled || u.IsDisabled() {
+		return nil, ErrInvalidMFAToken
+	}
+
+	// 2. 检查登录失败次数，账户被锁定时不再接受验证码
+	if err := s.throttle.Check(ctx, u.Username); err != nil {
+		return nil, err
+	}
+	if err := s.throttle.CheckUser(u); err != nil {
+		return nil, err
+	}
+
+	// 3. 校验验证码或恢复码
+	if isTOTPCode(code) {
+		err = s.verifyTOTP(ctx, u, code)
+	} else {
+		err = s.useRecoveryCode(ctx, u.ID, code)
+	}
+	if errors.Is(err, ErrInvalidMFACode) {
+		return nil, s.verifyFailed(ctx, u, claims, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 挑战令牌只能使用一次，通过两步验证后才清除失败计数
+	if err := s.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	if err := s.throttle.RecordSuccess(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return s.tokenSvc.IssueTokens(ctx, u)
+}
+
+// verifyFailed 记录一次验证码错误，挑战令牌输错次数达到上限时将其吊销，返回 err
+func (s *MFAService) verifyFailed(ctx context.Context, u *user.User, claims *user.MFAClaims, err error) error {
+	expiresAt := claims.ExpiresAt.Time
+	failures, recordErr := s.throttle.RecordMFAFailure(ctx, u, claims.ID, time.Until(expiresAt))
+	if recordErr != nil {
+		return recordErr
+	}
+	if failures >= s.maxAttempts {
+		if revokeErr := s.revocations.Revoke(ctx, claims.ID, expiresAt); revokeErr != nil {
+			return revokeErr
+		}
+	}
+	return err
+}
+
+// verifyTOTP 校验验证码，同一时间步的验证码只能使用一次
+func (s *MFAService) verifyTOTP(ctx context.Context, u *user.User, code string) error {
+	step, ok := totp.Validate(u.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok || step <= u.TOTPLastStep {
+		return ErrInvalidMFACode
+	}
+	advanced, err := s.repo.AdvanceTOTPStep(ctx, u.ID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return ErrInvalidMFACode
+	}
+	u.TOTPLastStep = step
+	return nil
+}
+
+// useRecoveryCode 使用恢复码
+func (s *MFAService) useRecoveryCode(ctx context.Context, userID uint, code string) error {
+	used, err := s.repo.UseRecoveryCode(ctx, userID, cryptoUtil.SHA256Hex(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码及其摘要，恢复码形如 3f9a1-c7e2b
+func (s *MFAService) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, s.recoveryCodes)
+	hashes := make([]string, 0, s.recoveryCodes)
+	for range s.recoveryCodes {
+		raw, err := cryptoUtil.GenerateRandomID(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, cryptoUtil.SHA256Hex(raw))
+	}
+	return codes, hashes, nil
+}
+
+// getUser 查询用户
+func (s *MFAService) getUser(ctx context.Context, userID uint) (*user.User, error) {
+	u, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// isTOTPCode 判断输入是否为 TOTP 验证码（纯数字且位数一致）
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRecoveryCode 规范化用户输入的恢复码，忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
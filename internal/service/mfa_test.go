@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/HoronLee/GinHub/internal/util/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mfaTestEnv 两步验证测试环境
+type mfaTestEnv struct {
+	*tokenTestEnv
+	mfa     *service.MFAService
+	userSvc *service.UserService
+}
+
+// newMFATestEnv 创建基于内存 SQLite 的两步验证测试环境
+func newMFATestEnv(t *testing.T) *mfaTestEnv {
+	t.Helper()
+
+	env := newTokenTestEnv(t)
+	revocations, stopGC, err := data.NewRevocationStore(env.cfg, env.data)
+	require.NoError(t, err)
+	t.Cleanup(stopGC)
+
+	userEnv := newUserTestEnv(t, env, nil)
+	mfa := service.NewMFAService(env.cfg, data.NewMFARepo(env.data), userEnv.userRepo, env.svc, revocations, userEnv.throttle, env.jwt)
+	userSvc := service.NewUserService(userEnv.userRepo, env.roleRepo, userEnv.apiKeyRepo, data.NewTransactor(env.cfg, env.data), userEnv.hasher, env.svc, mfa, userEnv.throttle, userEnv.emailSvc)
+	return &mfaTestEnv{tokenTestEnv: env, mfa: mfa, userSvc: userSvc}
+}
+
+// enableTOTP 为测试用户开启两步验证，返回共享密钥和恢复码
+func (env *mfaTestEnv) enableTOTP(t *testing.T) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	setup, err := env.mfa.Setup(ctx, env.user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.URI, "secret="+setup.Secret)
+
+	// 错误的验证码不能开启
+	_, err = env.mfa.Confirm(ctx, env.user.ID, "000000")
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+
+	// 使用上一个时间步的验证码确认，当前时间步的验证码留给后续登录
+	code, err := totp.GenerateCode(setup.Secret, time.Now().Add(-totp.Period*time.Second))
+	require.NoError(t, err)
+	recovery, err := env.mfa.Confirm(ctx, env.user.ID, code)
+	require.NoError(t, err)
+	assert.Len(t, recovery.RecoveryCodes, 10)
+
+	return setup.Secret, recovery.RecoveryCodes
+}
+
+func TestMFALoginWithTOTP(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+
+	// 开启两步验证前直接签发令牌
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123"}))
+	resp, err := env.userSvc.Login(ctx, user.LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.False(t, resp.MFARequired)
+	assert.NotEmpty(t, resp.Token)
+
+	alice, err := data.NewUserRepo(env.data).GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	env.user = alice
+	secret, _ := env.enableTOTP(t)
+
+	// 开启后登录只返回挑战令牌
+	resp, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	assert.Empty(t, resp.Token)
+	require.NotEmpty(t, resp.MFAToken)
+
+	// 挑战令牌的受众与访问令牌不同，不能作为访问令牌使用
+	_, err = env.jwt.ParseToken(resp.MFAToken, jwtUtil.WithAudience("ginhub-api"))
+	assert.ErrorIs(t, err, jwtUtil.ErrTokenInvalidAudience)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	tokens, err := env.mfa.VerifyLogin(ctx, resp.MFAToken, code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.Token)
+
+	// 挑战令牌只能使用一次
+	_, err = env.mfa.VerifyLogin(ctx, resp.MFAToken, code)
+	assert.ErrorIs(t, err, service.ErrInvalidMFAToken)
+
+	// 同一验证码不能在新的挑战中重放
+	again, err := env.userSvc.Login(ctx, user.LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	_, err = env.mfa.VerifyLogin(ctx, again.MFAToken, code)
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+
+	_, err = env.mfa.VerifyLogin(ctx, "not-a-token", code)
+	assert.ErrorIs(t, err, service.ErrInvalidMFAToken)
+}
+
+func TestMFARecoveryCodes(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+	_, codes := env.enableTOTP(t)
+
+	challenge, err := env.mfa.Challenge(ctx, env.user)
+	require.NoError(t, err)
+	_, err = env.mfa.VerifyLogin(ctx, challenge, codes[0])
+	require.NoError(t, err)
+
+	// 每个恢复码只能使用一次，输入时忽略大小写和连字符
+	challenge, err = env.mfa.Challenge(ctx, env.user)
+	require.NoError(t, err)
+	_, err = env.mfa.VerifyLogin(ctx, challenge, codes[0])
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+	_, err = env.mfa.VerifyLogin(ctx, challenge, " "+codes[1][:5]+codes[1][6:]+" ")
+	assert.NoError(t, err)
+}
+
+func TestMFADisableRequiresCode(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+	secret, _ := env.enableTOTP(t)
+
+	_, err := env.mfa.Setup(ctx, env.user.ID)
+	assert.ErrorIs(t, err, service.ErrMFAAlreadyEnabled)
+
+	assert.ErrorIs(t, env.mfa.Disable(ctx, env.user.ID, "000000"), service.ErrInvalidMFACode)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, env.mfa.Disable(ctx, env.user.ID, code))
+
+	u, err := data.NewUserRepo(env.data).GetUserByID(ctx, env.user.ID)
+	require.NoError(t, err)
+	assert.False(t, u.TOTPEnabled)
+	assert.Empty(t, u.TOTPSecret)
+	assert.ErrorIs(t, env.mfa.Disable(ctx, env.user.ID, code), service.ErrMFANotEnabled)
+}
+
+func TestMFALoginAttemptsLimited(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+	userRepo := data.NewUserRepo(env.data)
+
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123"}))
+	alice, err := userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	env.user = alice
+	secret, _ := env.enableTOTP(t)
+
+	login := user.LoginRequest{Username: "alice", Password: "password123"}
+	resp, err := env.userSvc.Login(ctx, login)
+	require.NoError(t, err)
+
+	// 验证码错误计入登录失败次数，同一挑战令牌输错 5 次后失效
+	for range 5 {
+		_, err = env.mfa.VerifyLogin(ctx, resp.MFAToken, "000000")
+		assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+	}
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	_, err = env.mfa.VerifyLogin(ctx, resp.MFAToken, code)
+	assert.ErrorIs(t, err, service.ErrInvalidMFAToken)
+
+	// 密码正确但未通过两步验证时不清除失败次数
+	resp, err = env.userSvc.Login(ctx, login)
+	require.NoError(t, err)
+	alice, err = userRepo.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, alice.FailedLoginCount)
+
+	// 通过两步验证后清除
+	_, err = env.mfa.VerifyLogin(ctx, resp.MFAToken, code)
+	require.NoError(t, err)
+	alice, err = userRepo.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Zero(t, alice.FailedLoginCount)
+}
@@ -0,0 +1,680 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// oauthAudience OAuth2 访问令牌的受众，与 GinHub 访问令牌区分
+	oauthAudience = "ginhub-oauth"
+	// consentAudience 授权确认令牌的受众
+	consentAudience = "ginhub-oauth-consent"
+	// defaultOAuthCodeExpires 未配置时授权码和授权确认页面的有效期
+	defaultOAuthCodeExpires = 5 * time.Minute
+	// defaultOAuthTokenExpires 未配置时 OAuth2 访问令牌和 ID Token 的有效期
+	defaultOAuthTokenExpires = time.Hour
+	// codeChallengeMethodS256 唯一支持的 PKCE 挑战方式，不支持 plain
+	codeChallengeMethodS256 = "S256"
+)
+
+// OAuth2 错误码（RFC 6749 4.1.2.1、5.2，RFC 6750 3.1）
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+	OAuthErrInvalidToken            = "invalid_token"
+	OAuthErrInsufficientScope       = "insufficient_scope"
+)
+
+var (
+	// ErrInvalidOAuthClient 授权请求中的客户端不存在，不能重定向回客户端
+	ErrInvalidOAuthClient = errors.New("unknown oauth client")
+	// ErrInvalidRedirectURI 授权请求中的回调地址缺失或未登记，不能重定向回客户端
+	ErrInvalidRedirectURI = errors.New("invalid redirect uri")
+	// ErrInvalidConsent 授权确认令牌无效、已过期、已使用或不属于当前用户
+	ErrInvalidConsent = errors.New("invalid or expired consent")
+)
+
+// oidcScopes 所有客户端都可以申请的 OpenID Connect 标准权限范围
+var oidcScopes = []string{user.ScopeOpenID, user.ScopeProfile, user.ScopeEmail}
+
+// OAuthError OAuth2 协议错误，Code 为协议定义的错误码，与 Description 一起原样返回给客户端
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// newOAuthError 创建 OAuth2 协议错误
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthRepo 定义 OAuth2 客户端和授权码数据访问接口
+type OAuthRepo interface {
+	CreateClient(ctx context.Context, c *user.OAuthClient) error
+	GetClientByClientID(ctx context.Context, clientID string) (*user.OAuthClient, error)
+	ListClients(ctx context.Context) ([]user.OAuthClient, error)
+	// DeleteClient 删除客户端及其授权码，客户端不存在时返回 gorm.ErrRecordNotFound
+	DeleteClient(ctx context.Context, id uint) error
+	CreateAuthorizationCode(ctx context.Context, code *user.OAuthAuthorizationCode) error
+	// ConsumeAuthorizationCode 将授权码标记为已使用，授权码已被使用过时返回 false，不存在时返回 gorm.ErrRecordNotFound
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*user.OAuthAuthorizationCode, bool, error)
+	// SetAuthorizationCodeToken 记录用授权码签发的访问令牌的 jti
+	SetAuthorizationCodeToken(ctx context.Context, id uint, tokenID string) error
+}
+
+// OAuthService OAuth2 授权服务器和 OpenID Connect 提供方
+// 访问令牌、ID Token 和授权确认令牌都与 GinHub 访问令牌共享签名密钥，通过受众区分
+type OAuthService struct {
+	repo         OAuthRepo
+	userRepo     UserRepo
+	revocations  RevocationStore
+	tokens       *jwtutil.JWT[user.OAuthClaims]
+	idTokens     *jwtutil.JWT[user.IDTokenClaims]
+	consents     *jwtutil.JWT[user.OAuthConsentClaims]
+	issuer       string
+	loginURL     string
+	codeExpires  time.Duration
+	tokenExpires time.Duration
+}
+
+// NewOAuthService 创建OAuthService实例（通过Wire注入）
+func NewOAuthService(
+	cfg *config.AppConfig,
+	repo OAuthRepo,
+	userRepo UserRepo,
+	revocations RevocationStore,
+	jwtHelper *jwtutil.JWT[user.Claims],
+) *OAuthService {
+	issuer := strings.TrimSuffix(cfg.Auth.OAuth.Issuer, "/")
+	if issuer == "" {
+		issuer = cfg.Auth.Jwt.Issuer
+	}
+	codeExpires := time.Duration(cfg.Auth.OAuth.CodeExpires) * time.Second
+	if codeExpires <= 0 {
+		codeExpires = defaultOAuthCodeExpires
+	}
+	tokenExpires := time.Duration(cfg.Auth.OAuth.AccessTokenExpires) * time.Second
+	if tokenExpires <= 0 {
+		tokenExpires = defaultOAuthTokenExpires
+	}
+
+	return &OAuthService{
+		repo:         repo,
+		userRepo:     userRepo,
+		revocations:  revocations,
+		tokens:       jwtutil.Derive[user.OAuthClaims](jwtHelper, jwtutil.WithIssuer(issuer), jwtutil.WithAudience(oauthAudience)),
+		idTokens:     jwtutil.Derive[user.IDTokenClaims](jwtHelper),
+		consents:     jwtutil.Derive[user.OAuthConsentClaims](jwtHelper, jwtutil.WithIssuer(issuer), jwtutil.WithAudience(consentAudience)),
+		issuer:       issuer,
+		loginURL:     cfg.Auth.OAuth.LoginURL,
+		codeExpires:  codeExpires,
+		tokenExpires: tokenExpires,
+	}
+}
+
+// OAuthConsent 已校验的授权请求，等待用户确认
+type OAuthConsent struct {
+	Client  *user.OAuthClient
+	Scopes  []string
+	Request user.AuthorizeRequest
+}
+
+// ValidateAuthorize 校验授权请求
+// 客户端或回调地址无效时返回 ErrInvalidOAuthClient 或 ErrInvalidRedirectURI，不能重定向回客户端；
+// 其他错误返回 *OAuthError，应通过 ErrorRedirect 重定向回客户端
+func (s *OAuthService) ValidateAuthorize(ctx context.Context, req user.AuthorizeRequest) (*OAuthConsent, error) {
+	// 1. 校验客户端和回调地址
+	client, err := s.repo.GetClientByClientID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOAuthClient
+		}
+		return nil, err
+	}
+	if req.RedirectURI == "" || !client.AllowsRedirectURI(req.RedirectURI) {
+		return nil, ErrInvalidRedirectURI
+	}
+
+	// 2. 校验授权类型和 PKCE 参数
+	if req.ResponseType != "code" {
+		return nil, newOAuthError(OAuthErrUnsupportedResponseType, "response_type must be code")
+	}
+	if !client.AllowsGrant(user.GrantTypeAuthorizationCode) {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use the authorization code grant")
+	}
+	if req.CodeChallengeMethod != codeChallengeMethodS256 {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "code_challenge_method must be S256")
+	}
+	if !isPKCEValue(req.CodeChallenge) {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "code_challenge is missing or malformed")
+	}
+
+	// 3. 校验权限范围
+	scopes, err := authorizeScopes(client, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthConsent{Client: client, Scopes: scopes, Request: req}, nil
+}
+
+// ConsentToken 为已校验的授权请求签发授权确认令牌，用户确认时提交
+func (s *OAuthService) ConsentToken(consent *OAuthConsent, userID uint) (string, error) {
+	jti, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.consents.GenerateToken(&user.OAuthConsentClaims{
+		UserID:        userID,
+		ClientID:      consent.Client.ClientID,
+		RedirectURI:   consent.Request.RedirectURI,
+		Scope:         strings.Join(consent.Scopes, " "),
+		State:         consent.Request.State,
+		Nonce:         consent.Request.Nonce,
+		CodeChallenge: consent.Request.CodeChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.codeExpires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.issuer,
+			Audience:  []string{consentAudience},
+			ID:        jti,
+		},
+	})
+}
+
+// Approve 处理用户对授权请求的确认，返回重定向回客户端的地址
+// 同意时签发授权码，拒绝时返回 access_denied；确认令牌只能使用一次
+func (s *OAuthService) Approve(ctx context.Context, claims *user.Claims, consentToken string, approved bool) (string, error) {
+	// 1. 校验确认令牌
+	consent, err := s.consents.ParseToken(consentToken)
+	if err != nil || consent.UserID != claims.UserID {
+		return "", ErrInvalidConsent
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, consent.ID)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
+		return "", ErrInvalidConsent
+	}
+	if err := s.revocations.Revoke(ctx, consent.ID, consent.ExpiresAt.Time); err != nil {
+		return "", err
+	}
+
+	if !approved {
+		return s.ErrorRedirect(consent.RedirectURI, consent.State,
+			newOAuthError(OAuthErrAccessDenied, "the user denied the request")), nil
+	}
+
+	// 2. 客户端可能在确认期间被删除
+	if _, err := s.repo.GetClientByClientID(ctx, consent.ClientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidConsent
+		}
+		return "", err
+	}
+
+	// 3. 签发授权码
+	code, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = s.repo.CreateAuthorizationCode(ctx, &user.OAuthAuthorizationCode{
+		CodeHash:      cryptoUtil.SHA256Hex(code),
+		ClientID:      consent.ClientID,
+		UserID:        consent.UserID,
+		RedirectURI:   consent.RedirectURI,
+		Scope:         consent.Scope,
+		Nonce:         consent.Nonce,
+		CodeChallenge: consent.CodeChallenge,
+		ExpiresAt:     time.Now().Add(s.codeExpires),
+	})
+	if err != nil {
+		return "", err
+	}
+	return redirectWithParams(consent.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {consent.State},
+		"iss":   {s.issuer},
+	}), nil
+}
+
+// ErrorRedirect 返回携带错误信息重定向回客户端的地址（RFC 6749 4.1.2.1，RFC 9207）
+func (s *OAuthService) ErrorRedirect(redirectURI, state string, e *OAuthError) string {
+	return redirectWithParams(redirectURI, url.Values{
+		"error":             {e.Code},
+		"error_description": {e.Description},
+		"state":             {state},
+		"iss":               {s.issuer},
+	})
+}
+
+// LoginRedirect 返回用户未登录时跳转的登录地址，未配置登录页面时返回空字符串
+func (s *OAuthService) LoginRedirect(returnTo string) string {
+	if s.loginURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(s.loginURL, "{return_to}", url.QueryEscape(returnTo))
+}
+
+// Exchange 处理令牌请求，支持授权码模式和客户端凭证模式
+// 返回的错误为 *OAuthError 时应按协议返回给客户端
+func (s *OAuthService) Exchange(ctx context.Context, req user.OAuthTokenRequest) (*user.OAuthTokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case user.GrantTypeAuthorizationCode, user.GrantTypeClientCredentials:
+		if !client.AllowsGrant(req.GrantType) {
+			return nil, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use this grant type")
+		}
+	case "":
+		return nil, newOAuthError(OAuthErrInvalidRequest, "grant_type is required")
+	default:
+		return nil, newOAuthError(OAuthErrUnsupportedGrantType, "unsupported grant_type")
+	}
+
+	if req.GrantType == user.GrantTypeClientCredentials {
+		return s.exchangeClientCredentials(client, req.Scope)
+	}
+	return s.exchangeCode(ctx, client, req)
+}
+
+// exchangeCode 使用授权码和 PKCE 验证码换取访问令牌，申请了 openid 时同时签发 ID Token
+// 授权码被重放时吊销用它签发的访问令牌（RFC 6749 4.1.2）
+func (s *OAuthService) exchangeCode(ctx context.Context, client *user.OAuthClient, req user.OAuthTokenRequest) (*user.OAuthTokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "code and code_verifier are required")
+	}
+
+	// 1. 消费授权码
+	code, fresh, err := s.repo.ConsumeAuthorizationCode(ctx, cryptoUtil.SHA256Hex(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+		}
+		return nil, err
+	}
+	if !fresh {
+		if code.TokenID != "" {
+			if err := s.revocations.Revoke(ctx, code.TokenID, time.Now().Add(s.tokenExpires)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, newOAuthError(OAuthErrInvalidGrant, "authorization code already used")
+	}
+
+	// 2. 校验授权码的归属、回调地址和 PKCE
+	if code.ClientID != client.ClientID || time.Now().After(code.ExpiresAt) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+	if code.RedirectURI != req.RedirectURI {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "code_verifier does not match the code_challenge")
+	}
+
+	u, err := s.userRepo.GetUserByID(ctx, code.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+		}
+		return nil, err
+	}
+	if u.IsDisabled() {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+
+	// 3. 签发访问令牌和 ID Token
+	resp, jti, err := s.issueAccessToken(client.ClientID, strconv.FormatUint(uint64(u.ID), 10), code.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetAuthorizationCodeToken(ctx, code.ID, jti); err != nil {
+		return nil, err
+	}
+	if slices.Contains(strings.Fields(code.Scope), user.ScopeOpenID) {
+		resp.IDToken, err = s.issueIDToken(client, u, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// exchangeClientCredentials 为机密客户端自身签发访问令牌，权限范围只能是客户端登记的范围
+func (s *OAuthService) exchangeClientCredentials(client *user.OAuthClient, scope string) (*user.OAuthTokenResponse, error) {
+	if client.IsPublic() {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "public clients cannot use the client credentials grant")
+	}
+
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	for _, sc := range scopes {
+		if !slices.Contains(client.Scopes, sc) {
+			return nil, newOAuthError(OAuthErrInvalidScope, "scope not allowed: "+sc)
+		}
+	}
+
+	resp, _, err := s.issueAccessToken(client.ClientID, client.ClientID, strings.Join(scopes, " "))
+	return resp, err
+}
+
+// Introspect 令牌自省（RFC 7662），只允许机密客户端调用
+// 令牌无效、已过期、已吊销或签发给已删除的客户端时返回 active=false
+func (s *OAuthService) Introspect(ctx context.Context, clientID, clientSecret, token string) (*user.OAuthIntrospectionResponse, error) {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsPublic() {
+		return nil, newOAuthError(OAuthErrInvalidClient, "public clients cannot introspect tokens")
+	}
+
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		var oauthErr *OAuthError
+		if errors.As(err, &oauthErr) {
+			return &user.OAuthIntrospectionResponse{Active: false}, nil
+		}
+		return nil, err
+	}
+	return &user.OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: TokenTypeBearer,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		Issuer:    claims.Issuer,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		JTI:       claims.ID,
+	}, nil
+}
+
+// Revoke 吊销客户端自己的访问令牌（RFC 7009），令牌无效时同样返回成功
+func (s *OAuthService) Revoke(ctx context.Context, clientID, clientSecret, token string) error {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		var oauthErr *OAuthError
+		if errors.As(err, &oauthErr) {
+			return nil
+		}
+		return err
+	}
+	if claims.ClientID != client.ClientID {
+		return newOAuthError(OAuthErrUnauthorizedClient, "token was not issued to this client")
+	}
+	return s.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// UserInfo 返回访问令牌所属用户的信息（OpenID Connect UserInfo），访问令牌需要包含 openid 权限范围
+func (s *OAuthService) UserInfo(ctx context.Context, token string) (*user.OIDCUserInfoResponse, error) {
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(strings.Fields(claims.Scope), user.ScopeOpenID) {
+		return nil, newOAuthError(OAuthErrInsufficientScope, "the access token does not include the openid scope")
+	}
+
+	// 客户端凭证模式签发的令牌 sub 为 client_id，不对应任何用户
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, newOAuthError(OAuthErrInvalidToken, "the access token is not bound to a user")
+	}
+	u, err := s.userRepo.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidToken, "the access token is not bound to a user")
+		}
+		return nil, err
+	}
+	if u.IsDisabled() {
+		return nil, newOAuthError(OAuthErrInvalidToken, "the access token is not bound to a user")
+	}
+
+	return &user.OIDCUserInfoResponse{
+		Subject:      claims.Subject,
+		OIDCUserInfo: oidcUserInfo(u, claims.Scope),
+	}, nil
+}
+
+// Discovery 返回 OpenID Connect 发现文档，各端点地址由签发者拼接而成
+func (s *OAuthService) Discovery() *user.OIDCDiscoveryResponse {
+	return &user.OIDCDiscoveryResponse{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.issuer + "/oauth/authorize",
+		TokenEndpoint:                     s.issuer + "/oauth/token",
+		UserInfoEndpoint:                  s.issuer + "/oauth/userinfo",
+		JWKSURI:                           s.issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:             s.issuer + "/oauth/introspect",
+		RevocationEndpoint:                s.issuer + "/oauth/revoke",
+		ScopesSupported:                   oidcScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{user.GrantTypeAuthorizationCode, user.GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.idTokens.Algorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "nonce", "azp",
+			"name", "preferred_username", "picture", "locale", "zoneinfo", "updated_at",
+			"email", "email_verified",
+		},
+		AuthorizationResponseIssParameter: true,
+	}
+}
+
+// authenticateClient 验证客户端身份，公开客户端只需提供 client_id
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*user.OAuthClient, error) {
+	if clientID == "" {
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication required")
+	}
+	client, err := s.repo.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+		}
+		return nil, err
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(cryptoUtil.SHA256Hex(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
+
+// parseAccessToken 解析 OAuth2 访问令牌，并检查令牌是否已被吊销、客户端是否仍然存在
+func (s *OAuthService) parseAccessToken(ctx context.Context, token string) (*user.OAuthClaims, error) {
+	claims, err := s.tokens.ParseToken(token)
+	if err != nil {
+		return nil, newOAuthError(OAuthErrInvalidToken, "the access token is invalid or expired")
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, newOAuthError(OAuthErrInvalidToken, "the access token has been revoked")
+	}
+	if _, err := s.repo.GetClientByClientID(ctx, claims.ClientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidToken, "the access token is invalid or expired")
+		}
+		return nil, err
+	}
+	return claims, nil
+}
+
+// issueAccessToken 签发 OAuth2 访问令牌，返回令牌响应和令牌的 jti
+func (s *OAuthService) issueAccessToken(clientID, subject, scope string) (*user.OAuthTokenResponse, string, error) {
+	jti, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	token, err := s.tokens.GenerateToken(&user.OAuthClaims{
+		ClientID: clientID,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenExpires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.issuer,
+			Subject:   subject,
+			Audience:  []string{oauthAudience},
+			ID:        jti,
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &user.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   TokenTypeBearer,
+		ExpiresIn:   int(s.tokenExpires.Seconds()),
+		Scope:       scope,
+	}, jti, nil
+}
+
+// issueIDToken 签发 ID Token，受众为客户端，用户信息按授权的权限范围填充
+func (s *OAuthService) issueIDToken(client *user.OAuthClient, u *user.User, code *user.OAuthAuthorizationCode) (string, error) {
+	now := time.Now()
+	return s.idTokens.GenerateToken(&user.IDTokenClaims{
+		Nonce:           code.Nonce,
+		AuthorizedParty: client.ClientID,
+		OIDCUserInfo:    oidcUserInfo(u, code.Scope),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenExpires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.issuer,
+			Subject:   strconv.FormatUint(uint64(u.ID), 10),
+			Audience:  []string{client.ClientID},
+		},
+	})
+}
+
+// oidcUserInfo 按权限范围生成 OpenID Connect 用户信息
+func oidcUserInfo(u *user.User, scope string) user.OIDCUserInfo {
+	var info user.OIDCUserInfo
+	scopes := strings.Fields(scope)
+	if slices.Contains(scopes, user.ScopeProfile) {
+		info.Name = u.DisplayName
+		info.PreferredUsername = u.Username
+		info.Picture = u.AvatarURL
+		info.Locale = u.Locale
+		info.Zoneinfo = u.Timezone
+		info.UpdatedAt = u.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, user.ScopeEmail) && u.Email != nil {
+		verified := u.EmailVerified()
+		info.Email = *u.Email
+		info.EmailVerified = &verified
+	}
+	return info
+}
+
+// authorizeScopes 校验授权请求的权限范围并去重，未指定时默认为 openid
+func authorizeScopes(client *user.OAuthClient, scope string) ([]string, error) {
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		return []string{user.ScopeOpenID}, nil
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, sc := range requested {
+		if !slices.Contains(oidcScopes, sc) && !slices.Contains(client.Scopes, sc) {
+			return nil, newOAuthError(OAuthErrInvalidScope, "scope not allowed: "+sc)
+		}
+		if !slices.Contains(scopes, sc) {
+			scopes = append(scopes, sc)
+		}
+	}
+	return scopes, nil
+}
+
+// isPKCEValue 判断是否为合法的 PKCE 验证码或挑战值：43 到 128 个 base64url 或 RFC 7636 允许的字符
+func isPKCEValue(v string) bool {
+	if len(v) < 43 || len(v) > 128 {
+		return false
+	}
+	for _, c := range v {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.ContainsRune("-._~", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyCodeChallenge 校验 PKCE 验证码：BASE64URL(SHA256(code_verifier)) 必须等于 code_challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if !isPKCEValue(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// redirectWithParams 在回调地址原有的查询参数后追加 params，忽略空值
+func redirectWithParams(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			if v != "" {
+				query.Add(k, v)
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	"gorm.io/gorm"
+)
+
+// ErrOAuthClientNotFound 要操作的 OAuth2 客户端不存在
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+// CreateClient 注册 OAuth2 客户端（管理员），机密客户端的密钥只在返回值中出现一次
+func (s *OAuthService) CreateClient(ctx context.Context, adminID uint, req user.CreateOAuthClientRequest) (*user.CreateOAuthClientResponse, error) {
+	// 1. 校验授权类型与客户端类型的组合
+	if slices.Contains(req.GrantTypes, user.GrantTypeAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return nil, errors.New("redirect_uris are required for the authorization code grant")
+	}
+	if req.Public && slices.Contains(req.GrantTypes, user.GrantTypeClientCredentials) {
+		return nil, errors.New("public clients cannot use the client credentials grant")
+	}
+
+	// 2. 生成客户端标识和密钥
+	clientID, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return nil, err
+	}
+	client := &user.OAuthClient{
+		ClientID:     clientID,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   slices.Compact(slices.Sorted(slices.Values(req.GrantTypes))),
+		Scopes:       req.Scopes,
+		CreatedBy:    adminID,
+	}
+	var secret string
+	if !req.Public {
+		secret, err = cryptoUtil.GenerateSecureToken(32)
+		if err != nil {
+			return nil, err
+		}
+		client.SecretHash = cryptoUtil.SHA256Hex(secret)
+	}
+
+	// 3. 保存客户端
+	if err := s.repo.CreateClient(ctx, client); err != nil {
+		return nil, err
+	}
+	return &user.CreateOAuthClientResponse{
+		OAuthClientResponse: user.NewOAuthClientResponse(client),
+		ClientSecret:        secret,
+	}, nil
+}
+
+// ListClients 查询全部 OAuth2 客户端（管理员）
+func (s *OAuthService) ListClients(ctx context.Context) ([]user.OAuthClientResponse, error) {
+	clients, err := s.repo.ListClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]user.OAuthClientResponse, 0, len(clients))
+	for i := range clients {
+		resp = append(resp, user.NewOAuthClientResponse(&clients[i]))
+	}
+	return resp, nil
+}
+
+// DeleteClient 删除 OAuth2 客户端（管理员），已签发给该客户端的访问令牌随即失效
+func (s *OAuthService) DeleteClient(ctx context.Context, id uint) error {
+	if err := s.repo.DeleteClient(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOAuthClientNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOAuthIssuer   = "https://ginhub.example.com"
+	testRedirectURI   = "https://app.example.com/callback"
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testOAuthNonce    = "n-0S6_WzA2Mj"
+	testOAuthState    = "af0ifjsldkj"
+	testOAuthAPIScope = "wiki:read"
+)
+
+// oauthTestEnv OAuthService 测试环境
+type oauthTestEnv struct {
+	*tokenTestEnv
+	oauth *service.OAuthService
+}
+
+// newOAuthTestEnv 创建基于内存 SQLite 的 OAuthService 测试环境
+func newOAuthTestEnv(t *testing.T) *oauthTestEnv {
+	t.Helper()
+
+	env := newTokenTestEnv(t)
+	env.cfg.Auth.OAuth.Issuer = testOAuthIssuer + "/"
+	return &oauthTestEnv{
+		tokenTestEnv: env,
+		oauth:        service.NewOAuthService(env.cfg, data.NewOAuthRepo(env.data), data.NewUserRepo(env.data), env.revocations, env.jwt),
+	}
+}
+
+// createClient 注册测试客户端
+func (e *oauthTestEnv) createClient(t *testing.T, public bool, grantTypes ...string) *user.CreateOAuthClientResponse {
+	t.Helper()
+
+	resp, err := e.oauth.CreateClient(context.Background(), e.user.ID, user.CreateOAuthClientRequest{
+		Name:         "Wiki",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   grantTypes,
+		Scopes:       []string{testOAuthAPIScope},
+		Public:       public,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+// authorize 完成授权确认，返回授权码
+func (e *oauthTestEnv) authorize(t *testing.T, clientID, scope string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	consent, err := e.oauth.ValidateAuthorize(ctx, user.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               scope,
+		State:               testOAuthState,
+		Nonce:               testOAuthNonce,
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	})
+	require.NoError(t, err)
+	token, err := e.oauth.ConsentToken(consent, e.user.ID)
+	require.NoError(t, err)
+
+	redirect, err := e.oauth.Approve(ctx, &user.Claims{UserID: e.user.ID}, token, true)
+	require.NoError(t, err)
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, testOAuthState, u.Query().Get("state"))
+	assert.Equal(t, testOAuthIssuer, u.Query().Get("iss"))
+	require.NotEmpty(t, u.Query().Get("code"))
+	return u.Query().Get("code")
+}
+
+// codeChallenge 计算 PKCE S256 验证码挑战
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// requireOAuthError 断言错误为指定错误码的 OAuthError
+func requireOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oauthErr *service.OAuthError
+	require.ErrorAs(t, err, &oauthErr)
+	assert.Equal(t, code, oauthErr.Code)
+}
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, true, user.GrantTypeAuthorizationCode)
+	assert.Empty(t, client.ClientSecret)
+
+	code := env.authorize(t, client.ClientID, "openid profile "+testOAuthAPIScope)
+	resp, err := env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+		ClientID:     client.ClientID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, service.TokenTypeBearer, resp.TokenType)
+	assert.Equal(t, "openid profile "+testOAuthAPIScope, resp.Scope)
+	require.NotEmpty(t, resp.IDToken)
+
+	// ID Token 的受众为客户端，携带授权请求中的 nonce
+	idTokens := jwtutil.Derive[user.IDTokenClaims](env.jwt, jwtutil.WithIssuer(testOAuthIssuer), jwtutil.WithAudience(client.ClientID))
+	idToken, err := idTokens.ParseToken(resp.IDToken)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(env.user.ID), 10), idToken.Subject)
+	assert.Equal(t, testOAuthNonce, idToken.Nonce)
+	assert.Equal(t, env.user.Username, idToken.PreferredUsername)
+
+	info, err := env.oauth.UserInfo(ctx, resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, idToken.Subject, info.Subject)
+	assert.Equal(t, env.user.Username, info.PreferredUsername)
+	assert.Empty(t, info.Email)
+}
+
+func TestOAuthAuthorizationCodeReplayRevokesToken(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, false, user.GrantTypeAuthorizationCode)
+
+	req := user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		Code:         env.authorize(t, client.ClientID, testOAuthAPIScope),
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+	}
+	resp, err := env.oauth.Exchange(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, resp.IDToken)
+
+	introspection, err := env.oauth.Introspect(ctx, client.ClientID, client.ClientSecret, resp.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, introspection.Active)
+
+	// 重放授权码失败，并吊销第一次签发的访问令牌
+	_, err = env.oauth.Exchange(ctx, req)
+	requireOAuthError(t, err, service.OAuthErrInvalidGrant)
+
+	introspection, err = env.oauth.Introspect(ctx, client.ClientID, client.ClientSecret, resp.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, introspection.Active)
+}
+
+func TestOAuthAuthorizationCodeVerification(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, true, user.GrantTypeAuthorizationCode)
+
+	// PKCE 验证码错误
+	_, err := env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		Code:         env.authorize(t, client.ClientID, ""),
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: "wrong-verifier-wrong-verifier-wrong-verifier",
+		ClientID:     client.ClientID,
+	})
+	requireOAuthError(t, err, service.OAuthErrInvalidGrant)
+
+	// 回调地址与授权请求不一致
+	_, err = env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		Code:         env.authorize(t, client.ClientID, ""),
+		RedirectURI:  "https://app.example.com/other",
+		CodeVerifier: testCodeVerifier,
+		ClientID:     client.ClientID,
+	})
+	requireOAuthError(t, err, service.OAuthErrInvalidGrant)
+
+	// 公开客户端不能携带密钥
+	_, err = env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		Code:         env.authorize(t, client.ClientID, ""),
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+		ClientID:     client.ClientID,
+		ClientSecret: "secret",
+	})
+	requireOAuthError(t, err, service.OAuthErrInvalidClient)
+}
+
+func TestOAuthValidateAuthorize(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, true, user.GrantTypeAuthorizationCode)
+
+	valid := user.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ClientID,
+		RedirectURI:         testRedirectURI,
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	}
+	consent, err := env.oauth.ValidateAuthorize(ctx, valid)
+	require.NoError(t, err)
+	assert.Equal(t, []string{user.ScopeOpenID}, consent.Scopes)
+
+	req := valid
+	req.ClientID = "unknown"
+	_, err = env.oauth.ValidateAuthorize(ctx, req)
+	assert.ErrorIs(t, err, service.ErrInvalidOAuthClient)
+
+	req = valid
+	req.RedirectURI = "https://evil.example.com/callback"
+	_, err = env.oauth.ValidateAuthorize(ctx, req)
+	assert.ErrorIs(t, err, service.ErrInvalidRedirectURI)
+
+	req = valid
+	req.CodeChallengeMethod = "plain"
+	_, err = env.oauth.ValidateAuthorize(ctx, req)
+	requireOAuthError(t, err, service.OAuthErrInvalidRequest)
+
+	req = valid
+	req.Scope = "admin"
+	_, err = env.oauth.ValidateAuthorize(ctx, req)
+	requireOAuthError(t, err, service.OAuthErrInvalidScope)
+}
+
+func TestOAuthConsentSingleUse(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, true, user.GrantTypeAuthorizationCode)
+
+	consent, err := env.oauth.ValidateAuthorize(ctx, user.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ClientID,
+		RedirectURI:         testRedirectURI,
+		State:               testOAuthState,
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	})
+	require.NoError(t, err)
+	token, err := env.oauth.ConsentToken(consent, env.user.ID)
+	require.NoError(t, err)
+
+	// 其他用户不能提交该确认令牌
+	_, err = env.oauth.Approve(ctx, &user.Claims{UserID: env.user.ID + 1}, token, true)
+	assert.ErrorIs(t, err, service.ErrInvalidConsent)
+
+	// 拒绝授权时携带 access_denied 重定向回客户端
+	redirect, err := env.oauth.Approve(ctx, &user.Claims{UserID: env.user.ID}, token, false)
+	require.NoError(t, err)
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, service.OAuthErrAccessDenied, u.Query().Get("error"))
+	assert.Equal(t, testOAuthState, u.Query().Get("state"))
+
+	// 确认令牌只能使用一次
+	_, err = env.oauth.Approve(ctx, &user.Claims{UserID: env.user.ID}, token, true)
+	assert.ErrorIs(t, err, service.ErrInvalidConsent)
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, false, user.GrantTypeClientCredentials)
+	require.NotEmpty(t, client.ClientSecret)
+
+	resp, err := env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeClientCredentials,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testOAuthAPIScope, resp.Scope)
+	assert.Empty(t, resp.IDToken)
+
+	introspection, err := env.oauth.Introspect(ctx, client.ClientID, client.ClientSecret, resp.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, client.ClientID, introspection.Subject)
+
+	// 客户端凭证模式的令牌不对应用户
+	_, err = env.oauth.UserInfo(ctx, resp.AccessToken)
+	requireOAuthError(t, err, service.OAuthErrInsufficientScope)
+
+	_, err = env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeClientCredentials,
+		Scope:        "admin",
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+	})
+	requireOAuthError(t, err, service.OAuthErrInvalidScope)
+
+	_, err = env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeClientCredentials,
+		ClientID:     client.ClientID,
+		ClientSecret: "wrong",
+	})
+	requireOAuthError(t, err, service.OAuthErrInvalidClient)
+
+	// 未登记的授权类型
+	_, err = env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+		GrantType:    user.GrantTypeAuthorizationCode,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+	})
+	requireOAuthError(t, err, service.OAuthErrUnauthorizedClient)
+
+	// 公开客户端不能注册客户端凭证模式
+	_, err = env.oauth.CreateClient(ctx, env.user.ID, user.CreateOAuthClientRequest{
+		Name:       "CLI",
+		GrantTypes: []string{user.GrantTypeClientCredentials},
+		Public:     true,
+	})
+	assert.Error(t, err)
+}
+
+func TestOAuthRevokeAndDeleteClient(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	ctx := context.Background()
+	client := env.createClient(t, false, user.GrantTypeClientCredentials)
+	other := env.createClient(t, false, user.GrantTypeClientCredentials)
+
+	issue := func() string {
+		resp, err := env.oauth.Exchange(ctx, user.OAuthTokenRequest{
+			GrantType:    user.GrantTypeClientCredentials,
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+		})
+		require.NoError(t, err)
+		return resp.AccessToken
+	}
+
+	// 只能吊销签发给自己的令牌，无效令牌同样返回成功
+	token := issue()
+	err := env.oauth.Revoke(ctx, other.ClientID, other.ClientSecret, token)
+	requireOAuthError(t, err, service.OAuthErrUnauthorizedClient)
+	require.NoError(t, env.oauth.Revoke(ctx, other.ClientID, other.ClientSecret, "invalid"))
+
+	require.NoError(t, env.oauth.Revoke(ctx, client.ClientID, client.ClientSecret, token))
+	introspection, err := env.oauth.Introspect(ctx, other.ClientID, other.ClientSecret, token)
+	require.NoError(t, err)
+	assert.False(t, introspection.Active)
+
+	// 删除客户端后已签发的令牌随即失效
+	token = issue()
+	require.NoError(t, env.oauth.DeleteClient(ctx, client.ID))
+	introspection, err = env.oauth.Introspect(ctx, other.ClientID, other.ClientSecret, token)
+	require.NoError(t, err)
+	assert.False(t, introspection.Active)
+
+	assert.ErrorIs(t, env.oauth.DeleteClient(ctx, client.ID), service.ErrOAuthClientNotFound)
+}
+
+func TestOAuthDiscovery(t *testing.T) {
+	env := newOAuthTestEnv(t)
+
+	doc := env.oauth.Discovery()
+	assert.Equal(t, testOAuthIssuer, doc.Issuer)
+	assert.Equal(t, testOAuthIssuer+"/oauth/token", doc.TokenEndpoint)
+	assert.Equal(t, testOAuthIssuer+"/.well-known/jwks.json", doc.JWKSURI)
+	assert.Equal(t, []string{"S256"}, doc.CodeChallengeMethodsSupported)
+}
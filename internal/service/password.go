@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/HoronLee/GinHub/internal/config"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+)
+
+// NewPasswordHasher 根据配置创建密码哈希器（通过Wire注入）
+func NewPasswordHasher(cfg *config.AppConfig) (cryptoUtil.PasswordHasher, error) {
+	pwCfg := cfg.Auth.Password
+	return cryptoUtil.NewPasswordHasher(cryptoUtil.PasswordConfig{
+		Algorithm:  pwCfg.Algorithm,
+		BcryptCost: pwCfg.BcryptCost,
+		Argon2: cryptoUtil.Argon2Params{
+			Memory:      pwCfg.Argon2.Memory,
+			Iterations:  pwCfg.Argon2.Iterations,
+			Parallelism: pwCfg.Argon2.Parallelism,
+			SaltLength:  pwCfg.Argon2.SaltLength,
+			KeyLength:   pwCfg.Argon2.KeyLength,
+		},
+	})
+}
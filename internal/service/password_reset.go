@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/mail"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultResetExpires 未配置时密码重置令牌的有效期
+const defaultResetExpires = 30 * time.Minute
+
+var (
+	// ErrInvalidResetToken 重置令牌不存在、已过期或已被使用
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrNoEmail 用户未设置邮箱，无法接收重置邮件
+	ErrNoEmail = errors.New("user has no email address")
+)
+
+// PasswordResetRepo 定义密码重置令牌数据访问接口
+type PasswordResetRepo interface {
+	CreateResetToken(ctx context.Context, t *user.PasswordResetToken) error
+	// ConsumeResetToken 将未使用且未过期的重置令牌标记为已使用，令牌无效时返回 gorm.ErrRecordNotFound
+	ConsumeResetToken(ctx context.Context, tokenHash string) (*user.PasswordResetToken, error)
+	// DeleteUserResetTokens 删除用户的全部重置令牌
+	DeleteUserResetTokens(ctx context.Context, userID uint) error
+}
+
+// PasswordResetService 找回密码服务
+type PasswordResetService struct {
+	repo       PasswordResetRepo
+	userRepo   UserRepo
+	roleRepo   RoleRepo
+	apiKeyRepo APIKeyRepo
+	hasher     cryptoUtil.PasswordHasher
+	tokenSvc   *TokenService
+	throttle   *LoginThrottle
+	limiter    *mailLimiter
+	mailer     mail.Mailer
+	expires    time.Duration
+	url        string
+}
+
+// NewPasswordResetService 创建PasswordResetService实例（通过Wire注入）
+func NewPasswordResetService(
+	cfg *config.AppConfig,
+	repo PasswordResetRepo,
+	userRepo UserRepo,
+	roleRepo RoleRepo,
+	apiKeyRepo APIKeyRepo,
+	hasher cryptoUtil.PasswordHasher,
+	tokenSvc *TokenService,
+	throttle *LoginThrottle,
+	attempts LoginAttemptStore,
+	mailer mail.Mailer,
+) *PasswordResetService {
+	resetCfg := cfg.Auth.PasswordReset
+	expires := time.Duration(resetCfg.Expires) * time.Second
+	if expires <= 0 {
+		expires = defaultResetExpires
+	}
+
+	return &PasswordResetService{
+		repo:       repo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		apiKeyRepo: apiKeyRepo,
+		hasher:     hasher,
+		tokenSvc:   tokenSvc,
+		throttle:   throttle,
+		limiter:    newMailLimiter(attempts, "reset", resetCfg.UserLimit, resetCfg.IPLimit),
+		mailer:     mailer,
+		expires:    expires,
+		url:        resetCfg.URL,
+	}
+}
+
+// RequestReset 申请重置密码，向用户邮箱发送一次性重置令牌
+// 用户不存在、未设置邮箱或请求过于频繁时同样返回成功，避免泄露账户是否存在；邮件在后台发送
+func (s *PasswordResetService) RequestReset(ctx context.Context, req user.ForgotPasswordRequest) error {
+	if ok, err := s.limiter.allowIP(ctx); err != nil || !ok {
+		return err
+	}
+	u, err := findUserByLogin(ctx, s.userRepo, req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.Email == nil || *u.Email == "" {
+		util.GetLogger().Debug("Password reset requested for user without email", zap.Uint("id", u.ID))
+		return nil
+	}
+	if ok, err := s.limiter.allowUser(ctx, u.ID); err != nil || !ok {
+		return err
+	}
+	return s.sendResetToken(ctx, u, false)
+}
+
+// ForceReset 要求用户重置密码（管理员）
+// 用户在重置密码前不能登录或使用 API Key，已登录的会话被吊销，并向用户邮箱发送重置邮件；
+// 用户未设置邮箱时返回 ErrNoEmail，避免用户无法完成重置；不能要求最后一个管理员重置密码
+func (s *PasswordResetService) ForceReset(ctx context.Context, userID uint) error {
+	u, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	if u.Email == nil || *u.Email == "" {
+		return ErrNoEmail
+	}
+	if err := ensureNotLastAdmin(ctx, s.roleRepo, u); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateUser(ctx, u.ID, map[string]any{"password_reset_required": true}); err != nil {
+		return err
+	}
+	if err := s.tokenSvc.RevokeUserTokens(ctx, u.ID); err != nil {
+		return err
+	}
+	return s.sendResetToken(ctx, u, true)
+}
+
+// sendResetToken 签发重置令牌并在后台发送重置邮件，同一用户只保留最新的重置令牌
+// forced 表示由管理员要求重置，邮件内容有所不同
+func (s *PasswordResetService) sendResetToken(ctx context.Context, u *user.User, forced bool) error {
+	if err := s.repo.DeleteUserResetTokens(ctx, u.ID); err != nil {
+		return err
+	}
+	token, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	err = s.repo.CreateResetToken(ctx, &user.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: cryptoUtil.SHA256Hex(token),
+		ExpiresAt: time.Now().Add(s.expires),
+	})
+	if err != nil {
+		return err
+	}
+
+	intro := "We received a request to reset your password."
+	outro := "If you did not request a password reset, you can ignore this email."
+	if forced {
+		intro = "An administrator has required you to reset your password before signing in again."
+		outro = "If the link expires, you can request a new one from the forgot password page."
+	}
+	sendMailAsync(ctx, s.mailer, &mail.Message{
+		To:      []string{*u.Email},
+		Subject: "Reset your GinHub password",
+		Body: fmt.Sprintf("Hi %s,\n\n%s Use the link below within %d minutes to choose a new one:\n\n%s\n\n%s\n",
+			u.Username, intro, int(s.expires.Minutes()), mailLink(s.url, token), outro),
+	})
+	return nil
+}
+
+// ResetPassword 使用重置令牌设置新密码
+// 令牌只能使用一次；重置后吊销用户的所有会话和 API Key、解除登录锁定并清除管理员设置的重置密码要求，
+// 账户被盗用后找回时，盗用者创建的 API Key 随之失效
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req user.ResetPasswordRequest) error {
+	// 1. 校验并消费重置令牌
+	rt, err := s.repo.ConsumeResetToken(ctx, cryptoUtil.SHA256Hex(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	u, err := s.userRepo.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// 2. 更新密码
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, u.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	// 3. 使其他重置令牌、已登录的会话和 API Key 失效
+	if err := s.repo.DeleteUserResetTokens(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.tokenSvc.RevokeUserTokens(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.apiKeyRepo.RevokeUserAPIKeys(ctx, u.ID); err != nil {
+		return err
+	}
+	return s.throttle.Unlock(ctx, u)
+}
@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	jwtUtil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passwordTestEnv 修改密码和找回密码测试环境
+type passwordTestEnv struct {
+	*userTestEnv
+	resetSvc *service.PasswordResetService
+}
+
+func newPasswordTestEnv(t *testing.T) *passwordTestEnv {
+	t.Helper()
+
+	env := newTokenTestEnv(t)
+	env.cfg.Auth.PasswordReset.URL = "https://ginhub.dev/reset?token={token}"
+	uenv := newUserTestEnv(t, env, nil)
+	penv := &passwordTestEnv{
+		userTestEnv: uenv,
+		resetSvc:    service.NewPasswordResetService(env.cfg, data.NewPasswordResetRepo(env.data), uenv.userRepo, env.roleRepo, uenv.apiKeyRepo, uenv.hasher, env.svc, uenv.throttle, uenv.attempts, uenv.mailer),
+	}
+	require.NoError(t, penv.userSvc.Register(context.Background(), user.RegisterRequest{
+		Username: "alice",
+		Password: "password123",
+		Email:    "alice@example.com",
+	}))
+	// 丢弃注册时发送的验证邮件
+	penv.mailer.receive(t)
+	return penv
+}
+
+// login 登录并返回令牌
+func (env *passwordTestEnv) login(t *testing.T, password string) *user.LoginResponse {
+	t.Helper()
+	resp, err := env.userSvc.Login(context.Background(), user.LoginRequest{Username: "alice", Password: password})
+	require.NoError(t, err)
+	return resp
+}
+
+// createAPIKey 为 alice 创建一个 API Key
+func (env *passwordTestEnv) createAPIKey(t *testing.T, prefix string) {
+	t.Helper()
+	ctx := context.Background()
+	u, err := env.userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.NoError(t, env.apiKeyRepo.CreateAPIKey(ctx, &user.APIKey{
+		UserID:  u.ID,
+		Name:    prefix,
+		Prefix:  prefix,
+		KeyHash: cryptoUtil.SHA256Hex(prefix),
+	}))
+}
+
+// apiKeys 返回 alice 未吊销的 API Key 数量
+func (env *passwordTestEnv) apiKeys(t *testing.T) int {
+	t.Helper()
+	ctx := context.Background()
+	u, err := env.userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	keys, err := env.apiKeyRepo.ListUserAPIKeys(ctx, u.ID)
+	require.NoError(t, err)
+	return len(keys)
+}
+
+func TestChangePassword(t *testing.T) {
+	env := newPasswordTestEnv(t)
+	ctx := context.Background()
+
+	current := env.login(t, "password123")
+	other := env.login(t, "password123")
+	claims, err := env.jwt.ParseToken(current.Token)
+	require.NoError(t, err)
+	authCtx := jwtUtil.NewContext(ctx, claims)
+
+	err = env.userSvc.ChangePassword(authCtx, claims, user.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "newpassword456"})
+	assert.EqualError(t, err, "old password is incorrect")
+
+	require.NoError(t, env.userSvc.ChangePassword(authCtx, claims, user.ChangePasswordRequest{
+		OldPassword: "password123",
+		NewPassword: "newpassword456",
+	}))
+
+	// 其他会话被吊销，当前会话仍可刷新
+	_, err = env.svc.Refresh(ctx, other.RefreshToken)
+	assert.Error(t, err)
+	_, err = env.svc.Refresh(ctx, current.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "alice", Password: "password123"})
+	assert.Error(t, err)
+	env.login(t, "newpassword456")
+
+	// API Key 默认保留，指定 revoke_api_keys 时全部吊销
+	env.createAPIKey(t, "key1")
+	require.NoError(t, env.userSvc.ChangePassword(authCtx, claims, user.ChangePasswordRequest{
+		OldPassword: "newpassword456",
+		NewPassword: "another789",
+	}))
+	assert.Equal(t, 1, env.apiKeys(t))
+	require.NoError(t, env.userSvc.ChangePassword(authCtx, claims, user.ChangePasswordRequest{
+		OldPassword:   "another789",
+		NewPassword:   "newpassword456",
+		RevokeAPIKeys: true,
+	}))
+	assert.Zero(t, env.apiKeys(t))
+}
+
+func TestPasswordReset(t *testing.T) {
+	env := newPasswordTestEnv(t)
+	ctx := context.Background()
+	session := env.login(t, "password123")
+	env.createAPIKey(t, "key1")
+
+	// 不存在的用户同样返回成功，且不发送邮件
+	require.NoError(t, env.resetSvc.RequestReset(ctx, user.ForgotPasswordRequest{Username: "ghost"}))
+
+	// 可以使用用户名或邮箱申请
+	require.NoError(t, env.resetSvc.RequestReset(ctx, user.ForgotPasswordRequest{Username: "alice"}))
+	msg := env.mailer.receive(t)
+	assert.Equal(t, []string{"alice@example.com"}, msg.To)
+	stale := extractToken(t, msg.Body)
+	require.NoError(t, env.resetSvc.RequestReset(ctx, user.ForgotPasswordRequest{Username: "ALICE@example.com"}))
+	token := extractToken(t, env.mailer.receive(t).Body)
+	assert.Empty(t, env.mailer.sent)
+
+	// 重新申请后旧令牌失效
+	err := env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: stale, NewPassword: "newpassword456"})
+	assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+
+	err = env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: "invalid", NewPassword: "newpassword456"})
+	assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+
+	require.NoError(t, env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: token, NewPassword: "newpassword456"}))
+
+	// 令牌只能使用一次
+	err = env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: token, NewPassword: "another789"})
+	assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+
+	// 重置后已登录的会话和 API Key 失效，新密码可以登录
+	_, err = env.svc.Refresh(ctx, session.RefreshToken)
+	assert.Error(t, err)
+	assert.Zero(t, env.apiKeys(t))
+	env.login(t, "newpassword456")
+}
+
+func TestPasswordResetRateLimited(t *testing.T) {
+	env := newPasswordTestEnv(t)
+	ctx := context.Background()
+
+	// 同一账户每小时最多发送 3 封重置邮件，被限制的请求不会使之前的令牌失效
+	var token string
+	for range 3 {
+		require.NoError(t, env.resetSvc.RequestReset(ctx, user.ForgotPasswordRequest{Username: "alice"}))
+		token = extractToken(t, env.mailer.receive(t).Body)
+	}
+	require.NoError(t, env.resetSvc.RequestReset(ctx, user.ForgotPasswordRequest{Username: "alice"}))
+	require.NoError(t, env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: token, NewPassword: "newpassword456"}))
+
+	// 同一 IP 每小时最多申请 10 次，不存在的用户同样计数
+	env.cfg.Auth.PasswordReset.UserLimit = 100
+	resetSvc := service.NewPasswordResetService(env.cfg, data.NewPasswordResetRepo(env.data), env.userRepo, env.roleRepo, env.apiKeyRepo, env.hasher, env.svc, env.throttle, env.attempts, env.mailer)
+	fromIP := func(ip string) context.Context {
+		return request.NewContext(ctx, &request.ClientInfo{IP: ip})
+	}
+	for range 10 {
+		require.NoError(t, resetSvc.RequestReset(fromIP("203.0.113.1"), user.ForgotPasswordRequest{Username: "ghost"}))
+	}
+	require.NoError(t, resetSvc.RequestReset(fromIP("203.0.113.1"), user.ForgotPasswordRequest{Username: "alice"}))
+	require.NoError(t, resetSvc.RequestReset(fromIP("203.0.113.2"), user.ForgotPasswordRequest{Username: "alice"}))
+	assert.Equal(t, []string{"alice@example.com"}, env.mailer.receive(t).To)
+	assert.Empty(t, env.mailer.sent)
+}
+
+func TestPasswordResetExpired(t *testing.T) {
+	env := newPasswordTestEnv(t)
+	ctx := context.Background()
+
+	token := "expired-token"
+	require.NoError(t, data.NewPasswordResetRepo(env.data).CreateResetToken(ctx, &user.PasswordResetToken{
+		UserID:    1,
+		TokenHash: cryptoUtil.SHA256Hex(token),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	err := env.resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: token, NewPassword: "newpassword456"})
+	assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// RevocationStore 定义访问令牌吊销列表的存储接口
+// 以 JWT 的 jti 为键，记录保留到令牌本身过期为止
+type RevocationStore interface {
+	// Revoke 吊销指定 jti，expiresAt 为令牌原本的过期时间
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsRevoked 判断指定 jti 是否已被吊销
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// DeleteExpired 清理已过期的吊销记录，返回清理的条数
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"gorm.io/gorm"
+)
+
+// ErrRoleExists 角色名已存在
+var ErrRoleExists = errors.New("role already exists")
+
+// ErrLastAdmin 操作会使系统失去最后一个有效的管理员
+var ErrLastAdmin = errors.New("cannot remove the last admin")
+
+// RoleRepo 定义角色数据访问接口
+type RoleRepo interface {
+	ListRoles(ctx context.Context) ([]user.Role, error)
+	GetRoleByName(ctx context.Context, name string) (*user.Role, error)
+	CreateRole(ctx context.Context, role *user.Role, permissions []string) error
+	GetUserRoles(ctx context.Context, userID uint) ([]user.Role, error)
+	AssignRole(ctx context.Context, userID uint, roleName string) error
+	RemoveRole(ctx context.Context, userID uint, roleName string) error
+	// CountUsersWithRole 统计拥有指定角色的有效用户数，已删除和已禁用的用户不计入
+	CountUsersWithRole(ctx context.Context, roleName string) (int64, error)
+}
+
+// RoleService 角色服务实现
+type RoleService struct {
+	repo     RoleRepo
+	userRepo UserRepo
+}
+
+// NewRoleService 创建RoleService实例（通过Wire注入）
+func NewRoleService(repo RoleRepo, userRepo UserRepo) *RoleService {
+	return &RoleService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// ListRoles 查询所有角色
+func (s *RoleService) ListRoles(ctx context.Context) ([]user.RoleResponse, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponses(roles), nil
+}
+
+// CreateRole 创建角色
+func (s *RoleService) CreateRole(ctx context.Context, req user.CreateRoleRequest) (*user.RoleResponse, error) {
+	// 1. 检查角色名是否已存在
+	existing, err := s.repo.GetRoleByName(ctx, req.Name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	// 2. 创建角色及其权限
+	role := &user.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.repo.CreateRole(ctx, role, req.Permissions); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrRoleExists
+		}
+		return nil, err
+	}
+
+	resp := user.NewRoleResponse(role)
+	return &resp, nil
+}
+
+// GetUserRoles 查询用户的角色
+func (s *RoleService) GetUserRoles(ctx context.Context, userID uint) ([]user.RoleResponse, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponses(roles), nil
+}
+
+// AssignRole 为用户分配角色
+func (s *RoleService) AssignRole(ctx context.Context, userID uint, roleName string) error {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetRoleByName(ctx, roleName); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("role not found")
+		}
+		return err
+	}
+	return s.repo.AssignRole(ctx, userID, roleName)
+}
+
+// RemoveRole 移除用户的角色
+// 不允许移除最后一个管理员的管理员角色，避免系统失去管理入口
+func (s *RoleService) RemoveRole(ctx context.Context, userID uint, roleName string) error {
+	u, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	if roleName == user.RoleAdmin {
+		if err := ensureNotLastAdmin(ctx, s.repo, u); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveRole(ctx, userID, roleName)
+}
+
+// ensureUserExists 检查用户是否存在
+func (s *RoleService) ensureUserExists(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureNotLastAdmin 删除、禁用用户或移除其管理员角色前调用，u 是最后一个有效（未删除且未禁用）的管理员时返回 ErrLastAdmin
+func ensureNotLastAdmin(ctx context.Context, repo RoleRepo, u *user.User) error {
+	if u.IsDisabled() {
+		return nil
+	}
+	roles, err := repo.GetUserRoles(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(roles, func(r user.Role) bool { return r.Name == user.RoleAdmin }) {
+		return nil
+	}
+	count, err := repo.CountUsersWithRole(ctx, user.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// loadUserRoles 加载用户的角色名称和去重后的权限名称
+func loadUserRoles(ctx context.Context, repo RoleRepo, userID uint) ([]string, []string, error) {
+	userRoles, err := repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles := make([]string, 0, len(userRoles))
+	var permissions []string
+	seen := make(map[string]struct{})
+	for i := range userRoles {
+		roles = append(roles, userRoles[i].Name)
+		for _, p := range userRoles[i].PermissionNames() {
+			if _, ok := seen[p]; !ok {
+				seen[p] = struct{}{}
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return roles, permissions, nil
+}
+
+// toRoleResponses 将角色模型列表转换为响应
+func toRoleResponses(roles []user.Role) []user.RoleResponse {
+	resp := make([]user.RoleResponse, 0, len(roles))
+	for i := range roles {
+		resp = append(resp, user.NewRoleResponse(&roles[i]))
+	}
+	return resp
+}
@@ -3,4 +3,4 @@ package service
 import "github.com/google/wire"
 
 // ProviderSet is service providers.
-var ProviderSet = wire.NewSet(NewHelloWorldService, NewUserService)
+var ProviderSet = wire.NewSet(NewPasswordHasher, NewJWT, NewHelloWorldService, NewTokenService, NewUserService, NewRoleService, NewAPIKeyService, NewMFAService, NewLoginThrottle, NewMailer, NewPasswordResetService, NewEmailVerificationService, NewUserPurger, NewSessionService, NewOAuthService, NewSSOService)
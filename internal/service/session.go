@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLastSeenInterval 未配置时会话最近活跃时间的更新间隔
+	defaultLastSeenInterval = 5 * time.Minute
+	// maxTrackedSessions 内存中记录最近写入时间的会话数上限，超过后清理过期的记录
+	maxTrackedSessions = 10000
+)
+
+// ErrSessionNotFound 会话不存在、已失效或不属于当前用户
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepo 定义登录会话数据访问接口
+type SessionRepo interface {
+	CreateSession(ctx context.Context, s *user.Session) error
+	// ListActiveSessions 查询用户未吊销且未过期的会话
+	ListActiveSessions(ctx context.Context, userID uint) ([]user.Session, error)
+	// RefreshSession 刷新令牌轮换后更新会话的活跃时间和过期时间
+	RefreshSession(ctx context.Context, familyID string, lastSeenAt, expiresAt time.Time) error
+	// TouchSession 更新会话的最近活跃时间，在 before 之后已更新过时不写入
+	TouchSession(ctx context.Context, familyID string, at, before time.Time) error
+	RevokeSessions(ctx context.Context, familyIDs []string) error
+}
+
+// SessionRevocationKey 返回会话在吊销列表中的键，与 jti 共用同一个吊销列表
+func SessionRevocationKey(sessionID string) string {
+	return "sid:" + sessionID
+}
+
+// SessionService 登录会话管理服务
+type SessionService struct {
+	repo     SessionRepo
+	tokenSvc *TokenService
+	interval time.Duration
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time // 会话最近一次写入活跃时间的时间
+}
+
+// NewSessionService 创建SessionService实例（通过Wire注入）
+func NewSessionService(cfg *config.AppConfig, repo SessionRepo, tokenSvc *TokenService) *SessionService {
+	interval := time.Duration(cfg.Auth.Session.LastSeenInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultLastSeenInterval
+	}
+
+	return &SessionService{
+		repo:     repo,
+		tokenSvc: tokenSvc,
+		interval: interval,
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// ListSessions 列出当前用户的有效会话
+func (s *SessionService) ListSessions(ctx context.Context, claims *user.Claims) ([]user.SessionResponse, error) {
+	sessions, err := s.repo.ListActiveSessions(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]user.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, user.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.FamilyID == claims.SessionID,
+		})
+	}
+	return resp, nil
+}
+
+// RevokeSession 吊销当前用户的指定会话，会话内的刷新令牌和访问令牌立即失效
+func (s *SessionService) RevokeSession(ctx context.Context, claims *user.Claims, sessionID uint) error {
+	sessions, err := s.repo.ListActiveSessions(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return s.tokenSvc.RevokeSessions(ctx, []string{session.FamilyID})
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeOtherSessions 吊销当前用户除当前会话以外的所有会话
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, claims *user.Claims) error {
+	return s.tokenSvc.RevokeOtherSessions(ctx, claims.UserID, claims.SessionID)
+}
+
+// Touch 记录会话的最近活跃时间
+// 同一会话在更新间隔内只写入一次数据库，写入失败不影响请求
+func (s *SessionService) Touch(ctx context.Context, claims *user.Claims) {
+	if claims.SessionID == "" {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if last, ok := s.lastSeen[claims.SessionID]; ok && now.Sub(last) < s.interval {
+		s.mu.Unlock()
+		return
+	}
+	if len(s.lastSeen) >= maxTrackedSessions {
+		for sid, last := range s.lastSeen {
+			if now.Sub(last) >= s.interval {
+				delete(s.lastSeen, sid)
+			}
+		}
+	}
+	s.lastSeen[claims.SessionID] = now
+	s.mu.Unlock()
+
+	// 多实例部署时通过条件更新避免各实例重复写入
+	if err := s.repo.TouchSession(ctx, claims.SessionID, now, now.Add(-s.interval)); err != nil {
+		util.GetLogger().Warn("Failed to update session last seen", zap.Error(err))
+	}
+}
+
+// truncate 将字符串截断为最多 n 个字节，不截断多字节字符
+func truncate(str string, n int) string {
+	if len(str) <= n {
+		return str
+	}
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	return str[:n]
+}
@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSession 以指定的客户端信息登录，返回令牌和访问令牌的 claims
+func newTestSession(t *testing.T, env *tokenTestEnv, ua string) (*user.LoginResponse, *user.Claims) {
+	t.Helper()
+
+	ctx := request.NewContext(context.Background(), &request.ClientInfo{IP: "10.0.0.1", UserAgent: ua})
+	resp, err := env.svc.IssueTokens(ctx, env.user)
+	require.NoError(t, err)
+	claims, err := env.jwt.ParseToken(resp.Token)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.SessionID)
+	return resp, claims
+}
+
+// isSessionRevoked 判断会话是否已加入吊销列表
+func isSessionRevoked(t *testing.T, env *tokenTestEnv, claims *user.Claims) bool {
+	t.Helper()
+
+	revoked, err := env.revocations.IsRevoked(context.Background(), service.SessionRevocationKey(claims.SessionID))
+	require.NoError(t, err)
+	return revoked
+}
+
+func TestListAndRevokeSessions(t *testing.T) {
+	env := newTokenTestEnv(t)
+	svc := service.NewSessionService(env.cfg, env.sessions, env.svc)
+	ctx := context.Background()
+
+	_, current := newTestSession(t, env, "firefox")
+	other, otherClaims := newTestSession(t, env, "curl")
+	_, thirdClaims := newTestSession(t, env, "safari")
+
+	sessions, err := svc.ListSessions(ctx, current)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	byAgent := make(map[string]user.SessionResponse)
+	for _, session := range sessions {
+		byAgent[session.UserAgent] = session
+	}
+	assert.Equal(t, "10.0.0.1", byAgent["firefox"].IP)
+	assert.True(t, byAgent["firefox"].Current)
+	assert.False(t, byAgent["curl"].Current)
+
+	// 吊销单个会话后刷新令牌和访问令牌都失效
+	require.NoError(t, svc.RevokeSession(ctx, current, byAgent["curl"].ID))
+	assert.True(t, isSessionRevoked(t, env, otherClaims))
+	_, err = env.svc.Refresh(ctx, other.RefreshToken)
+	assert.Error(t, err)
+	assert.ErrorIs(t, svc.RevokeSession(ctx, current, byAgent["curl"].ID), service.ErrSessionNotFound)
+
+	// 吊销其他会话时保留当前会话
+	require.NoError(t, svc.RevokeOtherSessions(ctx, current))
+	assert.True(t, isSessionRevoked(t, env, thirdClaims))
+	assert.False(t, isSessionRevoked(t, env, current))
+	sessions, err = svc.ListSessions(ctx, current)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.True(t, sessions[0].Current)
+}
+
+func TestRevokeSessionOfOtherUser(t *testing.T) {
+	env := newTokenTestEnv(t)
+	svc := service.NewSessionService(env.cfg, env.sessions, env.svc)
+	ctx := context.Background()
+
+	_, claims := newTestSession(t, env, "firefox")
+	sessions, err := svc.ListSessions(ctx, claims)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	intruder := &user.Claims{UserID: env.user.ID + 1}
+	assert.ErrorIs(t, svc.RevokeSession(ctx, intruder, sessions[0].ID), service.ErrSessionNotFound)
+	assert.False(t, isSessionRevoked(t, env, claims))
+}
+
+func TestTouchSessionThrottled(t *testing.T) {
+	env := newTokenTestEnv(t)
+	env.cfg.Auth.Session.LastSeenInterval = 3600
+	svc := service.NewSessionService(env.cfg, env.sessions, env.svc)
+	ctx := context.Background()
+
+	_, claims := newTestSession(t, env, "firefox")
+	lastSeen := func() time.Time {
+		sessions, err := svc.ListSessions(ctx, claims)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		return sessions[0].LastSeenAt
+	}
+	created := lastSeen()
+
+	// 登录时刚写入过活跃时间，更新间隔内不再写入
+	svc.Touch(ctx, claims)
+	assert.True(t, lastSeen().Equal(created))
+
+	// 活跃时间早于更新间隔时写入一次，之后的请求只检查内存记录
+	stale := created.Add(-2 * time.Hour)
+	require.NoError(t, env.sessions.TouchSession(ctx, claims.SessionID, stale, time.Now().Add(time.Hour)))
+	svc = service.NewSessionService(env.cfg, env.sessions, env.svc)
+	svc.Touch(ctx, claims)
+	touched := lastSeen()
+	assert.True(t, touched.After(stale))
+
+	require.NoError(t, env.sessions.TouchSession(ctx, claims.SessionID, stale, time.Now().Add(time.Hour)))
+	svc.Touch(ctx, claims)
+	assert.True(t, lastSeen().Equal(stale))
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// ssoStateAudience 外部登录状态令牌的受众，与访问令牌区分
+	ssoStateAudience = "ginhub-sso-state"
+	// defaultSSOStateExpires 未配置时外部登录流程的默认有效期
+	defaultSSOStateExpires = 10 * time.Minute
+	// ssoHTTPTimeout 请求身份提供方的超时时间
+	ssoHTTPTimeout = 10 * time.Second
+	// ssoUsernameMaxLen 自动生成用户名时基础部分的最大长度，为随机后缀留出空间
+	ssoUsernameMaxLen = 40
+	// ssoUsernameAttempts 自动生成用户名时的最大尝试次数
+	ssoUsernameAttempts = 5
+)
+
+var (
+	// ErrSSOProviderNotFound 未配置指定的外部身份提供方
+	ErrSSOProviderNotFound = errors.New("unknown identity provider")
+	// ErrInvalidSSOState 外部登录状态无效、已过期或已被使用
+	ErrInvalidSSOState = errors.New("invalid or expired sso state")
+	// ErrSSOLoginFailed 与身份提供方交换授权码或校验 ID Token 失败
+	ErrSSOLoginFailed = errors.New("external login failed")
+	// ErrSSOAccountNotLinked 外部身份未关联本地账户，且提供方未开启自动注册
+	ErrSSOAccountNotLinked = errors.New("no account linked to this identity")
+)
+
+// IdentityRepo 定义外部身份数据访问接口
+type IdentityRepo interface {
+	GetIdentity(ctx context.Context, provider, subject string) (*user.Identity, error)
+	// CreateUserWithIdentity 在事务中创建用户并关联外部身份，identity.UserID 由新用户的 ID 填充
+	CreateUserWithIdentity(ctx context.Context, u *user.User, identity *user.Identity) error
+	UpdateIdentityLogin(ctx context.Context, id uint, email string, at time.Time) error
+}
+
+// ssoProvider 外部身份提供方，发现文档在首次使用时获取
+type ssoProvider struct {
+	cfg config.OIDCProvider
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// SSOService 外部身份登录服务
+type SSOService struct {
+	identities   IdentityRepo
+	userRepo     UserRepo
+	roleRepo     RoleRepo
+	tx           Transactor
+	tokenSvc     *TokenService
+	mfaSvc       *MFAService
+	emailSvc     *EmailVerificationService
+	revocations  RevocationStore
+	states       *jwtutil.JWT[user.SSOStateClaims]
+	providers    map[string]*ssoProvider
+	names        []string
+	client       *http.Client
+	stateExpires time.Duration
+}
+
+// NewSSOService 创建SSOService实例（通过Wire注入）
+// 状态令牌与访问令牌共享签名密钥，但使用独立的受众
+func NewSSOService(
+	cfg *config.AppConfig,
+	identities IdentityRepo,
+	userRepo UserRepo,
+	roleRepo RoleRepo,
+	tx Transactor,
+	tokenSvc *TokenService,
+	mfaSvc *MFAService,
+	emailSvc *EmailVerificationService,
+	revocations RevocationStore,
+	jwtHelper *jwtutil.JWT[user.Claims],
+) *SSOService {
+	stateExpires := time.Duration(cfg.Auth.SSO.StateExpires) * time.Second
+	if stateExpires <= 0 {
+		stateExpires = defaultSSOStateExpires
+	}
+
+	providers := make(map[string]*ssoProvider, len(cfg.Auth.SSO.Providers))
+	names := make([]string, 0, len(cfg.Auth.SSO.Providers))
+	for _, p := range cfg.Auth.SSO.Providers {
+		providers[p.Name] = &ssoProvider{cfg: p}
+		names = append(names, p.Name)
+	}
+
+	return &SSOService{
+		identities:   identities,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		tx:           tx,
+		tokenSvc:     tokenSvc,
+		mfaSvc:       mfaSvc,
+		emailSvc:     emailSvc,
+		revocations:  revocations,
+		states:       jwtutil.Derive[user.SSOStateClaims](jwtHelper, jwtutil.WithAudience(ssoStateAudience)),
+		providers:    providers,
+		names:        names,
+		client:       &http.Client{Timeout: ssoHTTPTimeout},
+		stateExpires: stateExpires,
+	}
+}
+
+// Providers 返回已配置的外部身份提供方标识
+func (s *SSOService) Providers() []string {
+	return s.names
+}
+
+// StateExpires 返回外部登录流程的有效期
+func (s *SSOService) StateExpires() time.Duration {
+	return s.stateExpires
+}
+
+// StartLogin 开始外部登录，返回身份提供方的授权地址和状态令牌
+// 状态令牌需要保存在浏览器中，回调时与 state 参数一起提交，用于防止跨站请求伪造和 ID Token 重放
+func (s *SSOService) StartLogin(name string) (string, string, error) {
+	p, err := s.provider(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 1. 生成 state、nonce 和 PKCE 验证码
+	state, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := cryptoUtil.GenerateSecureToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// 2. 签发状态令牌
+	now := time.Now()
+	stateToken, err := s.states.GenerateToken(&user.SSOStateClaims{
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.stateExpires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    config.Config.Auth.Jwt.Issuer,
+			Audience:  []string{ssoStateAudience},
+			ID:        state,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), stateToken, nil
+}
+
+// Callback 完成外部登录，签发访问令牌和刷新令牌；开启了两步验证的用户只返回挑战令牌
+// 外部身份首次登录时，提供方开启了自动注册则创建本地账户，否则返回 ErrSSOAccountNotLinked
+func (s *SSOService) Callback(ctx context.Context, name, stateToken, state, code string) (*user.LoginResponse, error) {
+	p, err := s.provider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 校验状态令牌，每个状态令牌只能使用一次
+	claims, err := s.states.ParseToken(stateToken)
+	if err != nil || claims.Provider != name || state == "" || claims.ID != state {
+		return nil, ErrInvalidSSOState
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidSSOState
+	}
+	if err := s.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	// 2. 使用授权码换取 ID Token 并校验签名、签发者、受众和 nonce
+	if code == "" {
+		return nil, fmt.Errorf("%w: missing authorization code", ErrSSOLoginFailed)
+	}
+	clientCtx := oidc.ClientContext(ctx, s.client)
+	token, err := p.oauth2.Exchange(clientCtx, code, oauth2.VerifierOption(claims.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrSSOLoginFailed)
+	}
+	idToken, err := p.verifier.Verify(clientCtx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+	if idToken.Nonce != claims.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrSSOLoginFailed)
+	}
+	var info user.OIDCIdentityClaims
+	if err := idToken.Claims(&info); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+
+	// 3. 查找或创建关联的本地用户
+	u, err := s.resolveUser(ctx, p, idToken.Subject, info)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsDisabled() {
+		return nil, ErrUserDisabled
+	}
+	// 与密码登录一致，管理员要求重置密码的用户在重置前不能通过外部身份登录
+	if u.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	if s.emailSvc.Required() && !u.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	// 4. 开启了两步验证时，需要通过 /login/mfa 提交验证码后才签发令牌
+	if u.TOTPEnabled {
+		mfaToken, err := s.mfaSvc.Challenge(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		return &user.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	// 5. 签发访问令牌和刷新令牌
+	return s.tokenSvc.IssueTokens(ctx, u)
+}
+
+// provider 返回指定的外部身份提供方，首次使用时获取其发现文档
+// 发现文档延迟获取，提供方暂时不可用不影响服务启动
+func (s *SSOService) provider(name string) (*ssoProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrSSOProviderNotFound
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.verifier != nil {
+		return p, nil
+	}
+
+	// 发现文档中的公钥集会在后台按需刷新，不能绑定到单个请求的上下文
+	op, err := oidc.NewProvider(oidc.ClientContext(context.Background(), s.client), p.cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider %s: %w", name, err)
+	}
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range p.cfg.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     op.Endpoint(),
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       scopes,
+	}
+	p.verifier = op.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	return p, nil
+}
+
+// resolveUser 查询外部身份关联的本地用户，首次登录且开启了自动注册时创建用户
+func (s *SSOService) resolveUser(ctx context.Context, p *ssoProvider, subject string, info user.OIDCIdentityClaims) (*user.User, error) {
+	identity, err := s.identities.GetIdentity(ctx, p.cfg.Name, subject)
+	if err == nil {
+		u, err := s.userRepo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			// 关联的用户已被删除，在彻底清理之前不能重新注册
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserDisabled
+			}
+			return nil, err
+		}
+		if err := s.identities.UpdateIdentityLogin(ctx, identity.ID, info.Email, time.Now()); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !p.cfg.AutoRegister {
+		return nil, ErrSSOAccountNotLinked
+	}
+	return s.register(ctx, p, subject, info)
+}
+
+// register 为首次登录的外部身份创建本地用户
+// 只使用提供方已验证的邮箱，邮箱已被其他用户使用时返回 ErrEmailTaken，不会自动关联到已有账户
+func (s *SSOService) register(ctx context.Context, p *ssoProvider, subject string, info user.OIDCIdentityClaims) (*user.User, error) {
+	// 1. 校验邮箱
+	var email *string
+	var emailVerifiedAt *time.Time
+	if info.Email != "" && info.EmailVerified {
+		normalized := user.NormalizeEmail(info.Email)
+		existing, err := s.userRepo.GetUserByEmail(ctx, normalized)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrEmailTaken
+		}
+		now := time.Now()
+		email, emailVerifiedAt = &normalized, &now
+	}
+
+	// 2. 生成未被使用的用户名
+	username, err := s.availableUsername(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 在同一事务中创建用户、关联外部身份并分配默认角色，外部身份创建的账户没有本地密码，可以通过重置密码设置。
+	// 用户名由提供方决定，即使与 auth.rbac.admins 中的用户名相同也不授予管理员角色
+	var u *user.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		u = &user.User{
+			Username:        username,
+			Email:           email,
+			EmailVerifiedAt: emailVerifiedAt,
+			DisplayName:     truncate(info.Name, 64),
+		}
+		if len(info.Picture) <= 512 {
+			u.AvatarURL = info.Picture
+		}
+		if err := s.identities.CreateUserWithIdentity(ctx, u, &user.Identity{
+			Provider:    p.cfg.Name,
+			Subject:     subject,
+			Email:       info.Email,
+			LastLoginAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+		return assignInitialRoles(ctx, s.roleRepo, u)
+	})
+	if err != nil {
+		return nil, uniqueViolation(ctx, s.userRepo, username, email, err)
+	}
+	return u, nil
+}
+
+// availableUsername 根据提供方返回的用户名或邮箱生成未被使用的用户名，冲突时追加随机后缀
+func (s *SSOService) availableUsername(ctx context.Context, info user.OIDCIdentityClaims) (string, error) {
+	base := sanitizeUsername(info.PreferredUsername)
+	if base == "" {
+		local, _, _ := strings.Cut(info.Email, "@")
+		base = sanitizeUsername(local)
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for range ssoUsernameAttempts {
+		_, err := s.userRepo.GetUserByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		suffix, err := cryptoUtil.GenerateRandomID(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + suffix
+	}
+	return "", ErrUsernameTaken
+}
+
+// sanitizeUsername 只保留字母、数字、下划线、连字符和点，并截断到最大长度
+func sanitizeUsername(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		}
+		return -1
+	}, name)
+	if len(name) > ssoUsernameMaxLen {
+		name = name[:ssoUsernameMaxLen]
+	}
+	return name
+}
@@ -0,0 +1,334 @@
+package service_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSSOProvider     = "corp"
+	testSSOClientID     = "ginhub"
+	testSSOClientSecret = "ginhub-secret"
+	testSSORedirectURL  = "http://localhost:8080/api/v1/auth/corp/callback"
+)
+
+// mockIDTokenClaims 模拟身份提供方签发的 ID Token
+type mockIDTokenClaims struct {
+	Nonce string `json:"nonce,omitempty"`
+	user.OIDCIdentityClaims
+	jwt.RegisteredClaims
+}
+
+// mockAuthorization 模拟身份提供方记录的授权请求
+type mockAuthorization struct {
+	nonce         string
+	codeChallenge string
+	identity      mockIDTokenClaims
+}
+
+// mockOIDCProvider 本地模拟的 OpenID Connect 身份提供方
+type mockOIDCProvider struct {
+	server *httptest.Server
+	signer *jwtutil.JWT[mockIDTokenClaims]
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+// newMockOIDCProvider 启动模拟身份提供方，提供发现文档、公钥集和令牌端点
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jwtutil.New[mockIDTokenClaims](&jwtutil.Config{
+		Algorithm: jwtutil.AlgorithmRS256,
+		Keys:      []*jwtutil.Key{{ID: "idp-1", PrivateKey: key, PublicKey: key.Public()}},
+	})
+	require.NoError(t, err)
+
+	m := &mockOIDCProvider{signer: signer, codes: make(map[string]mockAuthorization)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{jwtutil.AlgorithmRS256},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.signer.JWKS())
+	})
+	mux.HandleFunc("POST /token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize 模拟用户在身份提供方登录并同意授权，返回回调地址中的授权码和 state
+func (m *mockOIDCProvider) authorize(t *testing.T, authURL string, identity mockIDTokenClaims) (string, string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, m.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, testSSOClientID, q.Get("client_id"))
+	assert.Equal(t, testSSORedirectURL, q.Get("redirect_uri"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Contains(t, q.Get("scope"), "openid")
+
+	code := rand.Text()
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{nonce: q.Get("nonce"), codeChallenge: q.Get("code_challenge"), identity: identity}
+	m.mu.Unlock()
+	return code, q.Get("state")
+}
+
+// token 令牌端点，校验客户端凭证和 PKCE 后签发 ID Token
+func (m *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != testSSOClientID || clientSecret != testSSOClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	m.mu.Lock()
+	auth, ok := m.codes[r.PostFormValue("code")]
+	delete(m.codes, r.PostFormValue("code"))
+	m.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := auth.identity
+	if claims.Nonce == "" {
+		claims.Nonce = auth.nonce
+	}
+	claims.Issuer = m.server.URL
+	claims.Audience = jwt.ClaimStrings{testSSOClientID}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(time.Minute))
+	idToken, err := m.signer.GenerateToken(&claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "idp-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ssoTestEnv SSOService 测试环境
+type ssoTestEnv struct {
+	*userTestEnv
+	idp *mockOIDCProvider
+	sso *service.SSOService
+}
+
+// newSSOTestEnv 创建连接到模拟身份提供方的 SSOService
+func newSSOTestEnv(t *testing.T, autoRegister bool) *ssoTestEnv {
+	t.Helper()
+
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	idp := newMockOIDCProvider(t)
+	env.cfg.Auth.SSO.Providers = []config.OIDCProvider{{
+		Name:         testSSOProvider,
+		Issuer:       idp.server.URL,
+		ClientID:     testSSOClientID,
+		ClientSecret: testSSOClientSecret,
+		RedirectURL:  testSSORedirectURL,
+		Scopes:       []string{"profile", "email"},
+		AutoRegister: autoRegister,
+	}}
+
+	return &ssoTestEnv{
+		userTestEnv: env,
+		idp:         idp,
+		sso: service.NewSSOService(env.cfg, data.NewIdentityRepo(env.data), env.userRepo, env.roleRepo, data.NewTransactor(env.cfg, env.data),
+			env.svc, nil, env.emailSvc, env.revocations, env.jwt),
+	}
+}
+
+// login 完成一次外部登录
+func (e *ssoTestEnv) login(t *testing.T, identity mockIDTokenClaims) (*user.LoginResponse, error) {
+	t.Helper()
+
+	authURL, stateToken, err := e.sso.StartLogin(testSSOProvider)
+	require.NoError(t, err)
+	code, state := e.idp.authorize(t, authURL, identity)
+	return e.sso.Callback(context.Background(), testSSOProvider, stateToken, state, code)
+}
+
+// mockIdentity 构造身份提供方返回的用户信息
+func mockIdentity(subject, username, email string) mockIDTokenClaims {
+	return mockIDTokenClaims{
+		OIDCIdentityClaims: user.OIDCIdentityClaims{
+			Email:             email,
+			EmailVerified:     email != "",
+			PreferredUsername: username,
+			Name:              "Alice Liddell",
+		},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}
+}
+
+func TestSSOLoginCreatesAndLinksUser(t *testing.T) {
+	env := newSSOTestEnv(t, true)
+	ctx := context.Background()
+
+	resp, err := env.login(t, mockIdentity("sub-alice", "alice", "Alice@Example.com"))
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Token)
+	require.NotEmpty(t, resp.RefreshToken)
+
+	// 首次登录创建本地用户，使用提供方已验证的邮箱
+	claims, err := env.jwt.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Username)
+	alice, err := env.userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, alice.Email)
+	assert.Equal(t, "alice@example.com", *alice.Email)
+	assert.True(t, alice.EmailVerified())
+	assert.Equal(t, "Alice Liddell", alice.DisplayName)
+
+	// 再次登录关联到同一用户，即使提供方的用户名发生变化
+	resp, err = env.login(t, mockIdentity("sub-alice", "alice.renamed", "alice@example.com"))
+	require.NoError(t, err)
+	claims, err = env.jwt.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, claims.UserID)
+
+	// 外部账户没有本地密码，不能使用密码登录
+	_, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "alice", Password: ""})
+	assert.Error(t, err)
+}
+
+func TestSSOLoginState(t *testing.T) {
+	env := newSSOTestEnv(t, true)
+	ctx := context.Background()
+	identity := mockIdentity("sub-alice", "alice", "")
+
+	// state 参数与状态令牌不一致
+	authURL, stateToken, err := env.sso.StartLogin(testSSOProvider)
+	require.NoError(t, err)
+	code, _ := env.idp.authorize(t, authURL, identity)
+	_, err = env.sso.Callback(ctx, testSSOProvider, stateToken, "forged", code)
+	assert.ErrorIs(t, err, service.ErrInvalidSSOState)
+
+	// 缺少状态令牌，例如回调请求来自其他浏览器
+	authURL, _, err = env.sso.StartLogin(testSSOProvider)
+	require.NoError(t, err)
+	code, state := env.idp.authorize(t, authURL, identity)
+	_, err = env.sso.Callback(ctx, testSSOProvider, "", state, code)
+	assert.ErrorIs(t, err, service.ErrInvalidSSOState)
+
+	// 状态令牌只能使用一次
+	authURL, stateToken, err = env.sso.StartLogin(testSSOProvider)
+	require.NoError(t, err)
+	code, state = env.idp.authorize(t, authURL, identity)
+	_, err = env.sso.Callback(ctx, testSSOProvider, stateToken, state, code)
+	require.NoError(t, err)
+	_, err = env.sso.Callback(ctx, testSSOProvider, stateToken, state, code)
+	assert.ErrorIs(t, err, service.ErrInvalidSSOState)
+
+	_, _, err = env.sso.StartLogin("unknown")
+	assert.ErrorIs(t, err, service.ErrSSOProviderNotFound)
+}
+
+func TestSSOLoginRejectsNonceMismatch(t *testing.T) {
+	env := newSSOTestEnv(t, true)
+
+	identity := mockIdentity("sub-alice", "alice", "")
+	identity.Nonce = "replayed-nonce"
+	_, err := env.login(t, identity)
+	assert.ErrorIs(t, err, service.ErrSSOLoginFailed)
+}
+
+func TestSSOLoginConflicts(t *testing.T) {
+	env := newSSOTestEnv(t, true)
+	ctx := context.Background()
+
+	// 用户名已被使用时追加随机后缀
+	resp, err := env.login(t, mockIdentity("sub-1", env.user.Username, ""))
+	require.NoError(t, err)
+	claims, err := env.jwt.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Regexp(t, `^testuser_[0-9a-f]{6}$`, claims.Username)
+
+	// 邮箱已被其他用户使用时不会自动关联到已有账户
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "bob", Password: "password123", Email: "bob@example.com"}))
+	_, err = env.login(t, mockIdentity("sub-2", "bob2", "bob@example.com"))
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+
+	// 被要求重置密码的用户在重置前不能通过外部身份登录
+	require.NoError(t, env.userRepo.UpdateUser(ctx, claims.UserID, map[string]any{"password_reset_required": true}))
+	_, err = env.login(t, mockIdentity("sub-1", env.user.Username, ""))
+	assert.ErrorIs(t, err, service.ErrPasswordResetRequired)
+	require.NoError(t, env.userRepo.UpdateUser(ctx, claims.UserID, map[string]any{"password_reset_required": false}))
+
+	// 已禁用的用户不能通过外部身份登录
+	require.NoError(t, env.userRepo.UpdateUser(ctx, claims.UserID, map[string]any{"status": user.StatusDisabled}))
+	_, err = env.login(t, mockIdentity("sub-1", env.user.Username, ""))
+	assert.ErrorIs(t, err, service.ErrUserDisabled)
+}
+
+func TestSSOLoginWithoutAutoRegister(t *testing.T) {
+	env := newSSOTestEnv(t, false)
+
+	_, err := env.login(t, mockIdentity("sub-alice", "alice", "alice@example.com"))
+	assert.ErrorIs(t, err, service.ErrSSOAccountNotLinked)
+}
+
+func TestSSOLoginDoesNotGrantAdmin(t *testing.T) {
+	env := newSSOTestEnv(t, true)
+	rbacCfg := config.Config.Auth.RBAC
+	t.Cleanup(func() { config.Config.Auth.RBAC = rbacCfg })
+	config.Config.Auth.RBAC.DefaultRole = user.RoleUser
+	config.Config.Auth.RBAC.Admins = []string{"admin"}
+
+	// 提供方返回的用户名与配置中的管理员用户名相同，自动创建的账户只获得默认角色
+	resp, err := env.login(t, mockIdentity("sub-admin", "admin", ""))
+	require.NoError(t, err)
+	claims, err := env.jwt.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Username)
+	assert.Equal(t, []string{user.RoleUser}, claims.Roles)
+}
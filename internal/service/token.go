@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// TokenTypeBearer 访问令牌类型
+	TokenTypeBearer = "Bearer"
+	// defaultRefreshExpires 未配置时刷新令牌的默认有效期
+	defaultRefreshExpires = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌不存在、已过期或已被吊销
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused 已使用过的刷新令牌被重放，整个令牌族已被吊销
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// RefreshTokenRepo 定义刷新令牌数据访问接口
+type RefreshTokenRepo interface {
+	CreateRefreshToken(ctx context.Context, rt *user.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*user.RefreshToken, error)
+	// MarkRefreshTokenUsed 将未使用的刷新令牌标记为已使用，令牌已被使用时返回 false
+	MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint) error
+	// RevokeOtherRefreshTokens 吊销用户除指定令牌族以外的所有刷新令牌
+	RevokeOtherRefreshTokens(ctx context.Context, userID uint, keepFamilyID string) error
+}
+
+// TokenService 令牌签发与刷新服务
+type TokenService struct {
+	repo        RefreshTokenRepo
+	sessions    SessionRepo
+	userRepo    UserRepo
+	roleRepo    RoleRepo
+	revocations RevocationStore
+	jwtHelper   *jwtutil.JWT[user.Claims]
+}
+
+// NewJWT 根据配置创建访问令牌的签名与验证器（通过Wire注入）
+// 默认使用 HS256 和 config.JWT_SECRET；配置非对称算法时从 PEM 文件加载密钥集
+// 解析令牌时校验配置中的签发者、受众和时钟偏差
+func NewJWT(cfg *config.AppConfig) (*jwtutil.JWT[user.Claims], error) {
+	jwtCfg := &jwtutil.Config{
+		SecretKey:   string(config.JWT_SECRET),
+		Algorithm:   cfg.Auth.Jwt.Algorithm,
+		ActiveKeyID: cfg.Auth.Jwt.ActiveKeyID,
+		Issuer:      cfg.Auth.Jwt.Issuer,
+		Audience:    cfg.Auth.Jwt.Audience,
+		Leeway:      time.Duration(cfg.Auth.Jwt.Leeway) * time.Second,
+	}
+
+	for _, k := range cfg.Auth.Jwt.Keys {
+		key, err := jwtutil.LoadKey(k.ID, k.PrivateKey, k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jwt key %s: %w", k.ID, err)
+		}
+		jwtCfg.Keys = append(jwtCfg.Keys, key)
+	}
+
+	return jwtutil.New[user.Claims](jwtCfg)
+}
+
+// NewTokenService 创建TokenService实例（通过Wire注入）
+func NewTokenService(
+	repo RefreshTokenRepo,
+	sessions SessionRepo,
+	userRepo UserRepo,
+	roleRepo RoleRepo,
+	revocations RevocationStore,
+	jwtHelper *jwtutil.JWT[user.Claims],
+) *TokenService {
+	return &TokenService{
+		repo:        repo,
+		sessions:    sessions,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		revocations: revocations,
+		jwtHelper:   jwtHelper,
+	}
+}
+
+// JWKS 返回用于验证访问令牌的公钥集合
+func (s *TokenService) JWKS() jwtutil.JWKSet {
+	return s.jwtHelper.JWKS()
+}
+
+// IssueTokens 为用户签发新的访问令牌和刷新令牌（开启新的令牌族），并记录登录会话
+func (s *TokenService) IssueTokens(ctx context.Context, u *user.User) (*user.LoginResponse, error) {
+	familyID, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	client := request.FromContext(ctx)
+	if err := s.sessions.CreateSession(ctx, &user.Session{
+		UserID:     u.ID,
+		FamilyID:   familyID,
+		UserAgent:  truncate(client.UserAgent, 512),
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(refreshExpires()),
+	}); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, u, familyID)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+// 每个刷新令牌只能使用一次；已使用的令牌被再次提交时视为泄露，吊销整个令牌族
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*user.LoginResponse, error) {
+	// 1. 查询刷新令牌
+	rt, err := s.repo.GetRefreshTokenByHash(ctx, cryptoUtil.SHA256Hex(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 2. 校验令牌状态
+	if rt.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if rt.UsedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, rt)
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 3. 标记为已使用，并发刷新时只有一个请求能成功
+	marked, err := s.repo.MarkRefreshTokenUsed(ctx, rt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, s.revokeReusedFamily(ctx, rt)
+	}
+
+	// 4. 在同一令牌族内签发新的令牌对
+	u, err := s.userRepo.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if u.IsDisabled() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	resp, err := s.issueTokens(ctx, u, rt.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.sessions.RefreshSession(ctx, rt.FamilyID, now, now.Add(refreshExpires())); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Logout 注销当前登录会话
+// 吊销当前访问令牌，并吊销同一会话下的所有刷新令牌和访问令牌
+func (s *TokenService) Logout(ctx context.Context, claims *user.Claims) error {
+	if err := s.RevokeAccessToken(ctx, claims); err != nil {
+		return err
+	}
+	if claims.SessionID == "" {
+		return nil
+	}
+	return s.RevokeSessions(ctx, []string{claims.SessionID})
+}
+
+// RevokeAccessToken 将访问令牌加入吊销列表，直到其自然过期
+func (s *TokenService) RevokeAccessToken(ctx context.Context, claims *user.Claims) error {
+	if claims.ID == "" {
+		return errors.New("token has no jti")
+	}
+	expiresAt := time.Now().Add(time.Duration(config.Config.Auth.Jwt.Expires) * time.Second)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return s.revocations.Revoke(ctx, claims.ID, expiresAt)
+}
+
+// RevokeUserTokens 吊销用户的所有会话和刷新令牌，并吊销上下文中的当前访问令牌
+func (s *TokenService) RevokeUserTokens(ctx context.Context, userID uint) error {
+	if err := s.revokeUserSessions(ctx, userID, ""); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	if claims, ok := jwtutil.FromContext[user.Claims](ctx); ok && claims.UserID == userID {
+		return s.RevokeAccessToken(ctx, claims)
+	}
+	return nil
+}
+
+// RevokeOtherSessions 吊销用户除 keepSessionID 以外的所有会话
+func (s *TokenService) RevokeOtherSessions(ctx context.Context, userID uint, keepSessionID string) error {
+	if err := s.revokeUserSessions(ctx, userID, keepSessionID); err != nil {
+		return err
+	}
+	if keepSessionID == "" {
+		return s.repo.RevokeUserRefreshTokens(ctx, userID)
+	}
+	return s.repo.RevokeOtherRefreshTokens(ctx, userID, keepSessionID)
+}
+
+// RevokeSessions 吊销会话及其刷新令牌，并将会话标识加入吊销列表，使会话内已签发的访问令牌立即失效
+func (s *TokenService) RevokeSessions(ctx context.Context, familyIDs []string) error {
+	if err := s.sessions.RevokeSessions(ctx, familyIDs); err != nil {
+		return err
+	}
+
+	// 会话内的访问令牌最晚在一个有效期后过期
+	jwtCfg := config.Config.Auth.Jwt
+	expiresAt := time.Now().Add(time.Duration(jwtCfg.Expires+jwtCfg.Leeway) * time.Second)
+	for _, familyID := range familyIDs {
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+			return err
+		}
+		if err := s.revocations.Revoke(ctx, SessionRevocationKey(familyID), expiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeUserSessions 吊销用户除 keepSessionID 以外的所有有效会话
+func (s *TokenService) revokeUserSessions(ctx context.Context, userID uint, keepSessionID string) error {
+	sessions, err := s.sessions.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	familyIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.FamilyID != keepSessionID {
+			familyIDs = append(familyIDs, session.FamilyID)
+		}
+	}
+	return s.RevokeSessions(ctx, familyIDs)
+}
+
+// revokeReusedFamily 吊销被重放的刷新令牌所在的会话
+func (s *TokenService) revokeReusedFamily(ctx context.Context, rt *user.RefreshToken) error {
+	if err := s.RevokeSessions(ctx, []string{rt.FamilyID}); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// issueTokens 在指定令牌族内签发访问令牌和刷新令牌
+func (s *TokenService) issueTokens(ctx context.Context, u *user.User, familyID string) (*user.LoginResponse, error) {
+	now := time.Now()
+	expires := time.Duration(config.Config.Auth.Jwt.Expires) * time.Second
+
+	jti, err := cryptoUtil.GenerateRandomID(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 加载角色和权限，写入访问令牌
+	roles, permissions, err := loadUserRoles(ctx, s.roleRepo, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 生成JWT访问令牌
+	claims := &user.Claims{
+		UserID:      u.ID,
+		Username:    u.Username,
+		SessionID:   familyID,
+		Roles:       roles,
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(expires)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now.Add(-60 * time.Second)), // 允许60秒的时钟偏差
+			Issuer:    config.Config.Auth.Jwt.Issuer,
+			Subject:   u.Username,
+			Audience:  []string{config.Config.Auth.Jwt.Audience},
+			ID:        jti,
+		},
+	}
+
+	accessToken, err := s.jwtHelper.GenerateToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 生成刷新令牌，数据库中只保存其摘要
+	refreshToken, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRefreshToken(ctx, &user.RefreshToken{
+		UserID:    u.ID,
+		FamilyID:  familyID,
+		TokenHash: cryptoUtil.SHA256Hex(refreshToken),
+		ExpiresAt: now.Add(refreshExpires()),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &user.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(expires.Seconds()),
+		TokenType:    TokenTypeBearer,
+	}, nil
+}
+
+// refreshExpires 返回刷新令牌的有效期
+func refreshExpires() time.Duration {
+	expires := time.Duration(config.Config.Auth.Jwt.RefreshExpires) * time.Second
+	if expires <= 0 {
+		return defaultRefreshExpires
+	}
+	return expires
+}
@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenTestEnv TokenService 测试环境
+type tokenTestEnv struct {
+	svc      *service.TokenService
+	user     *user.User
+	roleRepo service.RoleRepo
+	jwt      *jwtutil.JWT[user.Claims]
+	cfg      *config.AppConfig
+	data     *data.Data
+
+	sessions    service.SessionRepo
+	revocations service.RevocationStore
+}
+
+// newTestTokenService 创建基于内存 SQLite 的 TokenService
+func newTestTokenService(t *testing.T) (*service.TokenService, *user.User) {
+	env := newTokenTestEnv(t)
+	return env.svc, env.user
+}
+
+// newTokenTestEnv 创建基于内存 SQLite 的测试环境
+func newTokenTestEnv(t *testing.T) *tokenTestEnv {
+	t.Helper()
+
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+	config.JWT_SECRET = []byte("test-secret-key")
+	config.Config.Auth.Jwt.Expires = 3600
+
+	logger := util.NewLogger(cfg)
+	db, err := data.NewDB(cfg, logger)
+	require.NoError(t, err)
+	d, cleanup, err := data.NewData(db, logger)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	revocations, stopGC, err := data.NewRevocationStore(cfg, d)
+	require.NoError(t, err)
+	t.Cleanup(stopGC)
+
+	userRepo := data.NewUserRepo(d)
+	u := &user.User{Username: "testuser", Password: "hashedpassword"}
+	require.NoError(t, userRepo.CreateUser(context.Background(), u))
+
+	jwtHelper, err := service.NewJWT(cfg)
+	require.NoError(t, err)
+
+	roleRepo := data.NewRoleRepo(d)
+	sessions := data.NewSessionRepo(d)
+	return &tokenTestEnv{
+		svc:         service.NewTokenService(data.NewRefreshTokenRepo(d), sessions, userRepo, roleRepo, revocations, jwtHelper),
+		user:        u,
+		roleRepo:    roleRepo,
+		jwt:         jwtHelper,
+		cfg:         cfg,
+		data:        d,
+		sessions:    sessions,
+		revocations: revocations,
+	}
+}
+
+func TestTokenServiceRefreshRotation(t *testing.T) {
+	svc, u := newTestTokenService(t)
+	ctx := context.Background()
+
+	first, err := svc.IssueTokens(ctx, u)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Token)
+	assert.NotEmpty(t, first.RefreshToken)
+	assert.Equal(t, 3600, first.ExpiresIn)
+	assert.Equal(t, service.TokenTypeBearer, first.TokenType)
+
+	// 刷新后得到新的令牌对
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// 新的刷新令牌可以继续使用
+	third, err := svc.Refresh(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, third.RefreshToken)
+}
+
+func TestTokenServiceRefreshReuseRevokesFamily(t *testing.T) {
+	svc, u := newTestTokenService(t)
+	ctx := context.Background()
+
+	first, err := svc.IssueTokens(ctx, u)
+	require.NoError(t, err)
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	// 重放已使用的刷新令牌
+	_, err = svc.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+
+	// 同一令牌族中尚未使用的令牌也随之失效
+	_, err = svc.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// 其他登录会话不受影响
+	other, err := svc.IssueTokens(ctx, u)
+	require.NoError(t, err)
+	_, err = svc.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenServiceRefreshUnknownToken(t *testing.T) {
+	svc, _ := newTestTokenService(t)
+
+	_, err := svc.Refresh(context.Background(), "not-a-refresh-token")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestTokenServiceLogoutRevokesSession(t *testing.T) {
+	env := newTokenTestEnv(t)
+	svc, u := env.svc, env.user
+	ctx := context.Background()
+
+	current, err := svc.IssueTokens(ctx, u)
+	require.NoError(t, err)
+	other, err := svc.IssueTokens(ctx, u)
+	require.NoError(t, err)
+
+	claims, err := env.jwt.ParseToken(current.Token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotEmpty(t, claims.SessionID)
+	require.NoError(t, svc.Logout(ctx, claims))
+
+	// 当前会话的刷新令牌失效
+	_, err = svc.Refresh(ctx, current.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// 其他会话不受影响
+	_, err = svc.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenServiceEmbedsRoles(t *testing.T) {
+	env := newTokenTestEnv(t)
+	ctx := context.Background()
+	parser := env.jwt
+
+	tokens, err := env.svc.IssueTokens(ctx, env.user)
+	require.NoError(t, err)
+	claims, err := parser.ParseToken(tokens.Token)
+	require.NoError(t, err)
+	assert.Empty(t, claims.Roles)
+	assert.False(t, claims.HasPermission(user.PermissionUserDelete))
+
+	// 分配角色后，刷新得到的访问令牌包含新角色及其权限
+	require.NoError(t, env.roleRepo.AssignRole(ctx, env.user.ID, user.RoleAdmin))
+	require.NoError(t, env.roleRepo.AssignRole(ctx, env.user.ID, user.RoleUser))
+	refreshed, err := env.svc.Refresh(ctx, tokens.RefreshToken)
+	require.NoError(t, err)
+	claims, err = parser.ParseToken(refreshed.Token)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{user.RoleAdmin, user.RoleUser}, claims.Roles)
+	assert.Equal(t, []string{user.PermissionAll}, claims.Permissions)
+	assert.True(t, claims.HasPermission(user.PermissionUserDelete))
+}
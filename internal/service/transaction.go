@@ -0,0 +1,11 @@
+package service
+
+import "context"
+
+// Transactor 事务管理器，由数据层实现
+type Transactor interface {
+	// WithinTx 在事务中执行 fn，fn 中使用传入的 ctx 调用的仓储操作都在该事务中执行；
+	// fn 返回错误时回滚。嵌套调用使用保存点，只回滚内层的修改。
+	// 遇到死锁或序列化冲突时最外层事务会按配置重试，fn 可能被执行多次
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -3,46 +3,91 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/HoronLee/GinHub/internal/config"
 	"github.com/HoronLee/GinHub/internal/model/user"
 	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
-	jwtutil "github.com/HoronLee/GinHub/internal/util/jwt"
-	"github.com/golang-jwt/jwt/v5"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"github.com/HoronLee/GinHub/internal/util/paging"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// ErrUsernameTaken 用户名已被其他用户使用
+var ErrUsernameTaken = errors.New("username already exists")
+
 // UserRepo 定义用户数据访问接口
 type UserRepo interface {
 	CreateUser(ctx context.Context, u *user.User) error
 	GetUserByUsername(ctx context.Context, username string) (*user.User, error)
+	// GetUserByEmail 根据规范化后的邮箱查询用户
+	GetUserByEmail(ctx context.Context, email string) (*user.User, error)
 	GetUserByID(ctx context.Context, id uint) (*user.User, error)
+	// ListUsers 按条件分页查询用户并预加载角色
+	ListUsers(ctx context.Context, opts UserListOptions, params paging.Params) (*paging.Page[user.User], error)
+	// UpdatePassword 更新密码哈希，同时清除管理员设置的重置密码要求
+	UpdatePassword(ctx context.Context, id uint, hashedPassword string) error
+	// UpdateUser 部分更新用户字段，fields 的键为数据库列名，仅更新提供的列
+	UpdateUser(ctx context.Context, id uint, fields map[string]any) error
+	// MarkEmailVerified 将用户邮箱标记为已验证，用户当前邮箱与 email 不一致时返回 gorm.ErrRecordNotFound
+	MarkEmailVerified(ctx context.Context, id uint, email string) error
+	// UpdateLoginState 更新连续登录失败次数和锁定时间，lockedUntil 为 nil 表示未锁定
+	UpdateLoginState(ctx context.Context, id uint, failedCount int, lockedUntil *time.Time) error
+	// DeleteUser 软删除用户，保留期内可以通过 RestoreUser 恢复
 	DeleteUser(ctx context.Context, id uint) error
+	// GetDeletedUserByID 查询已软删除的用户
+	GetDeletedUserByID(ctx context.Context, id uint) (*user.User, error)
+	RestoreUser(ctx context.Context, id uint) error
+	// PurgeDeletedUsers 彻底删除在 before 之前软删除的用户及其凭据，返回删除的数量
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
 }
 
 // UserService 用户服务实现
 type UserService struct {
-	repo      UserRepo
-	jwtHelper *jwtutil.JWT[user.Claims]
+	repo       UserRepo
+	roleRepo   RoleRepo
+	apiKeyRepo APIKeyRepo
+	tx         Transactor
+	hasher     cryptoUtil.PasswordHasher
+	tokenSvc   *TokenService
+	mfaSvc     *MFAService
+	throttle   *LoginThrottle
+	emailSvc   *EmailVerificationService
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 // NewUserService 创建UserService实例（通过Wire注入）
-func NewUserService(repo UserRepo) *UserService {
-	// 创建JWT helper
-	jwtCfg := &jwtutil.Config{
-		SecretKey: string(config.JWT_SECRET),
-	}
-	jwtHelper := jwtutil.NewJWT[user.Claims](jwtCfg)
-
+func NewUserService(
+	repo UserRepo,
+	roleRepo RoleRepo,
+	apiKeyRepo APIKeyRepo,
+	tx Transactor,
+	hasher cryptoUtil.PasswordHasher,
+	tokenSvc *TokenService,
+	mfaSvc *MFAService,
+	throttle *LoginThrottle,
+	emailSvc *EmailVerificationService,
+) *UserService {
 	return &UserService{
-		repo:      repo,
-		jwtHelper: jwtHelper,
+		repo:       repo,
+		roleRepo:   roleRepo,
+		apiKeyRepo: apiKeyRepo,
+		tx:         tx,
+		hasher:     hasher,
+		tokenSvc:   tokenSvc,
+		mfaSvc:     mfaSvc,
+		throttle:   throttle,
+		emailSvc:   emailSvc,
 	}
 }
 
 // Register 用户注册
-// 检查用户名是否存在，使用MD5加密密码，创建用户
+// 检查用户名和邮箱是否存在，使用配置的哈希算法加密密码，创建用户；填写了邮箱时发送验证邮件
 func (s *UserService) Register(ctx context.Context, req user.RegisterRequest) error {
 	// 1. 检查用户名是否已存在
 	existingUser, err := s.repo.GetUserByUsername(ctx, req.Username)
@@ -52,72 +97,308 @@ func (s *UserService) Register(ctx context.Context, req user.RegisterRequest) er
 	}
 	if existingUser != nil {
 		// 用户名已存在
-		return errors.New("username already exists")
+		return ErrUsernameTaken
+	}
+
+	// 2. 检查邮箱是否已被使用
+	var email *string
+	if req.Email != "" {
+		normalized := user.NormalizeEmail(req.Email)
+		existingUser, err = s.repo.GetUserByEmail(ctx, normalized)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if existingUser != nil {
+			return ErrEmailTaken
+		}
+		email = &normalized
+	}
+
+	// 3. 哈希密码
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	// 4. 在同一事务中创建用户并分配初始角色
+	var newUser *user.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		newUser = &user.User{
+			Username: req.Username,
+			Password: hashedPassword,
+			Email:    email,
+		}
+		if err := s.repo.CreateUser(ctx, newUser); err != nil {
+			return err
+		}
+		return assignInitialRoles(ctx, s.roleRepo, newUser)
+	})
+	if err != nil {
+		// 事务回滚后再查询冲突原因，PostgreSQL 中出错的事务不能继续执行查询
+		return uniqueViolation(ctx, s.repo, req.Username, email, err)
 	}
 
-	// 2. 使用MD5加密密码
-	hashedPassword := cryptoUtil.MD5Encrypt(req.Password)
+	// 5. 发送邮箱验证邮件
+	return s.emailSvc.SendVerification(ctx, newUser)
+}
 
-	// 3. 创建用户
-	newUser := &user.User{
-		Username: req.Username,
-		Password: hashedPassword,
+// uniqueViolation 写入用户时唯一索引冲突（如并发注册同一用户名），重新查询区分是用户名还是邮箱已被使用。
+// 各数据库的冲突错误已在连接时统一转换为 gorm.ErrDuplicatedKey
+func uniqueViolation(ctx context.Context, repo UserRepo, username string, email *string, err error) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+	if _, e := repo.GetUserByUsername(ctx, username); e == nil {
+		return ErrUsernameTaken
 	}
+	if email != nil {
+		if _, e := repo.GetUserByEmail(ctx, *email); e == nil {
+			return ErrEmailTaken
+		}
+	}
+	return err
+}
 
-	return s.repo.CreateUser(ctx, newUser)
+// assignInitialRoles 为新用户分配默认角色
+// 新注册的用户不会获得管理员角色，即使用户名在 auth.rbac.admins 中：否则任何人都能抢先注册该用户名成为管理员。
+// 管理员角色只在启动时授予已存在的用户（见 data.seedRBAC）
+func assignInitialRoles(ctx context.Context, roleRepo RoleRepo, u *user.User) error {
+	if defaultRole := config.Config.Auth.RBAC.DefaultRole; defaultRole != "" {
+		return roleRepo.AssignRole(ctx, u.ID, defaultRole)
+	}
+	return nil
 }
 
 // Login 用户登录
-// 验证用户名和密码，生成JWT Token
-func (s *UserService) Login(ctx context.Context, req user.LoginRequest) (string, error) {
-	// 1. 查询用户
-	u, err := s.repo.GetUserByUsername(ctx, req.Username)
+// 验证用户名（或邮箱）和密码，签发访问令牌和刷新令牌；开启了两步验证的用户只返回挑战令牌
+// 同一用户名或 IP 连续失败过多时返回 *ThrottledError，用户名是否存在不影响返回结果
+func (s *UserService) Login(ctx context.Context, req user.LoginRequest) (*user.LoginResponse, error) {
+	// 1. 检查登录失败次数
+	if err := s.throttle.Check(ctx, req.Username); err != nil {
+		return nil, err
+	}
+
+	// 2. 按用户名或邮箱查询用户
+	u, err := findUserByLogin(ctx, s.repo, req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 用户不存在时同样计算一次哈希，避免通过响应时间判断用户名是否存在
+			_, _ = s.hasher.Verify(req.Password, s.getDummyHash())
+			return nil, s.loginFailed(ctx, req.Username, nil)
+		}
+		return nil, err
+	}
+	if err := s.throttle.CheckUser(u); err != nil {
+		return nil, err
+	}
+
+	// 3. 验证密码
+	ok, err := s.hasher.Verify(req.Password, u.Password)
+	if err != nil || !ok {
+		return nil, s.loginFailed(ctx, req.Username, u)
+	}
+	// 开启了两步验证时，失败计数在通过两步验证后才清除，避免通过反复登录重置验证码的猜测次数
+	if !u.TOTPEnabled {
+		if err := s.throttle.RecordSuccess(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+	// 账户状态只在密码正确后返回，避免泄露账户信息
+	if u.IsDisabled() {
+		return nil, ErrUserDisabled
+	}
+	if u.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	if s.emailSvc.Required() && !u.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	// 旧算法或旧参数的哈希在登录成功后透明升级
+	if s.hasher.NeedsRehash(u.Password) {
+		s.rehashPassword(ctx, u, req.Password)
+	}
+
+	// 4. 开启了两步验证时，需要通过 /login/mfa 提交验证码后才签发令牌
+	if u.TOTPEnabled {
+		mfaToken, err := s.mfaSvc.Challenge(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		return &user.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	// 5. 签发访问令牌和刷新令牌
+	return s.tokenSvc.IssueTokens(ctx, u)
+}
+
+// findUserByLogin 根据登录名查询用户，包含 @ 时按邮箱查询，否则按用户名查询
+func findUserByLogin(ctx context.Context, repo UserRepo, login string) (*user.User, error) {
+	if strings.Contains(login, "@") {
+		return repo.GetUserByEmail(ctx, user.NormalizeEmail(login))
+	}
+	return repo.GetUserByUsername(ctx, login)
+}
+
+// loginFailed 记录登录失败并返回统一的错误信息
+func (s *UserService) loginFailed(ctx context.Context, username string, u *user.User) error {
+	if err := s.throttle.RecordFailure(ctx, username, u); err != nil {
+		return err
+	}
+	return errors.New("invalid username or password")
+}
+
+// getDummyHash 返回用于用户不存在时计算的哈希值
+func (s *UserService) getDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.hasher.Hash("ginhub-dummy-password")
+	})
+	return s.dummyHash
+}
+
+// rehashPassword 使用当前配置的算法重新哈希密码，失败不影响登录
+func (s *UserService) rehashPassword(ctx context.Context, u *user.User, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, u.ID, hashedPassword)
+	}
+	if err != nil {
+		util.GetLogger().Warn("Failed to rehash user password", zap.Uint("id", u.ID), zap.Error(err))
+		return
+	}
+	u.Password = hashedPassword
+}
+
+// ChangePassword 修改当前用户的密码
+// 需要验证旧密码；修改成功后吊销该用户其他会话的刷新令牌，当前会话保持登录；
+// req.RevokeAPIKeys 为 true 时同时吊销全部 API Key
+func (s *UserService) ChangePassword(ctx context.Context, claims *user.Claims, req user.ChangePasswordRequest) error {
+	// 1. 验证旧密码
+	u, err := s.repo.GetUserByID(ctx, claims.UserID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", errors.New("invalid username or password")
+			return errors.New("user not found")
 		}
-		return "", err
+		return err
+	}
+	ok, err := s.hasher.Verify(req.OldPassword, u.Password)
+	if err != nil || !ok {
+		return errors.New("old password is incorrect")
 	}
 
-	// 2. 验证密码
-	hashedPassword := cryptoUtil.MD5Encrypt(req.Password)
-	if u.Password != hashedPassword {
-		return "", errors.New("invalid username or password")
+	// 2. 更新密码
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, u.ID, hashedPassword); err != nil {
+		return err
 	}
 
-	// 3. 生成JWT Token
-	claims := &user.Claims{
-		UserID:   u.ID,
-		Username: u.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.Config.Auth.Jwt.Expires) * time.Second)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now().Add(-60 * time.Second)), // 允许60秒的时钟偏差
-			Issuer:    config.Config.Auth.Jwt.Issuer,
-			Subject:   u.Username,
-			Audience:  []string{config.Config.Auth.Jwt.Audience},
-		},
+	// 3. 吊销其他会话，按需吊销 API Key
+	if err := s.tokenSvc.RevokeOtherSessions(ctx, u.ID, claims.SessionID); err != nil {
+		return err
 	}
+	if req.RevokeAPIKeys {
+		return s.apiKeyRepo.RevokeUserAPIKeys(ctx, u.ID)
+	}
+	return nil
+}
 
-	token, err := s.jwtHelper.GenerateToken(claims)
+// GetProfile 获取当前用户的个人信息
+func (s *UserService) GetProfile(ctx context.Context, userID uint) (*user.UserResponse, error) {
+	u, err := s.getUser(ctx, userID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	roles, _, err := loadUserRoles(ctx, s.roleRepo, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := user.NewUserResponse(u, roles)
+	return &resp, nil
+}
+
+// UpdateProfile 修改当前用户的个人资料，仅更新请求中提供的字段
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, req user.UpdateProfileRequest) (*user.UserResponse, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
 	}
 
-	return token, nil
+	fields := make(map[string]any)
+	for column, value := range map[string]*string{
+		"display_name": req.DisplayName,
+		"avatar_url":   req.AvatarURL,
+		"locale":       req.Locale,
+		"timezone":     req.Timezone,
+		"bio":          req.Bio,
+	} {
+		if value != nil {
+			fields[column] = strings.TrimSpace(*value)
+		}
+	}
+	if len(fields) > 0 {
+		if err := s.repo.UpdateUser(ctx, userID, fields); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetProfile(ctx, userID)
 }
 
-// DeleteUser 删除用户
+// GetPublicProfile 获取用户的公开资料
+func (s *UserService) GetPublicProfile(ctx context.Context, userID uint) (*user.PublicProfileResponse, error) {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := user.NewPublicProfileResponse(u)
+	return &resp, nil
+}
+
+// getUser 根据ID查询用户，不存在时返回 user not found
+func (s *UserService) getUser(ctx context.Context, userID uint) (*user.User, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// DeleteUser 删除用户，用户本人注销和管理员删除共用
+// 用户被软删除，在配置的保留期内可以由管理员恢复，超过保留期后由 UserPurger 彻底删除；不能删除最后一个管理员
 func (s *UserService) DeleteUser(ctx context.Context, userID uint) error {
 	// 1. 检查用户是否存在
-	_, err := s.repo.GetUserByID(ctx, userID)
+	u, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("user not found")
 		}
 		return err
 	}
+	if err := ensureNotLastAdmin(ctx, s.roleRepo, u); err != nil {
+		return err
+	}
 
 	// 2. 删除用户
-	return s.repo.DeleteUser(ctx, userID)
+	if err := s.repo.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// 3. 吊销该用户的令牌，已删除的账户不能继续访问
+	return s.tokenSvc.RevokeUserTokens(ctx, userID)
+}
+
+// UnlockUser 解除用户的登录锁定（管理员）
+func (s *UserService) UnlockUser(ctx context.Context, userID uint) error {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	return s.throttle.Unlock(ctx, u)
 }
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/util/paging"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserDisabled 账户已被管理员禁用
+	ErrUserDisabled = errors.New("account disabled")
+	// ErrPasswordResetRequired 管理员要求重置密码，需通过找回密码流程设置新密码后才能登录
+	ErrPasswordResetRequired = errors.New("password reset required")
+)
+
+// UserListOptions 用户列表筛选条件
+type UserListOptions struct {
+	UsernamePrefix string
+	// Status 为 active、disabled、locked 或 deleted，为空时返回除已删除外的全部用户
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// ListUsers 分页查询用户列表（管理员）
+func (s *UserService) ListUsers(ctx context.Context, req user.ListUsersRequest, params paging.Params) (*paging.Page[user.AdminUserResponse], error) {
+	page, err := s.repo.ListUsers(ctx, UserListOptions{
+		UsernamePrefix: req.Username,
+		Status:         req.Status,
+		CreatedAfter:   req.CreatedAfter,
+		CreatedBefore:  req.CreatedBefore,
+	}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return paging.Map(page, func(u *user.User) user.AdminUserResponse {
+		return user.NewAdminUserResponse(u, now)
+	}), nil
+}
+
+// SetUserDisabled 禁用或启用用户（管理员）
+// 禁用后吊销该用户的所有会话和刷新令牌，会话内已签发的访问令牌随之失效；不能禁用最后一个管理员
+func (s *UserService) SetUserDisabled(ctx context.Context, userID uint, disabled bool) error {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		if err := ensureNotLastAdmin(ctx, s.roleRepo, u); err != nil {
+			return err
+		}
+	}
+
+	status := user.StatusActive
+	if disabled {
+		status = user.StatusDisabled
+	}
+	if err := s.repo.UpdateUser(ctx, userID, map[string]any{"status": status}); err != nil {
+		return err
+	}
+	if !disabled {
+		return nil
+	}
+	return s.tokenSvc.RevokeUserTokens(ctx, userID)
+}
+
+// RestoreUser 恢复已删除的用户（管理员）
+// 用户名或邮箱在删除后已被其他用户使用时不能恢复
+func (s *UserService) RestoreUser(ctx context.Context, userID uint) error {
+	u, err := s.repo.GetDeletedUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("deleted user not found")
+		}
+		return err
+	}
+
+	if _, err := s.repo.GetUserByUsername(ctx, u.Username); err == nil {
+		return ErrUsernameTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if u.Email != nil {
+		if _, err := s.repo.GetUserByEmail(ctx, *u.Email); err == nil {
+			return ErrEmailTaken
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if err := s.repo.RestoreUser(ctx, userID); err != nil {
+		return uniqueViolation(ctx, s.repo, u.Username, u.Email, err)
+	}
+	return nil
+}
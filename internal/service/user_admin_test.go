@@ -0,0 +1,300 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	"github.com/HoronLee/GinHub/internal/util/paging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminTestEnv 创建测试环境并注册 user01 到 user05，注册时间依次间隔一天
+func newAdminTestEnv(t *testing.T) (*userTestEnv, []uint) {
+	t.Helper()
+
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var ids []uint
+	for i := 1; i <= 5; i++ {
+		username := fmt.Sprintf("user%02d", i)
+		require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: username, Password: "password123"}))
+		u, err := env.userRepo.GetUserByUsername(ctx, username)
+		require.NoError(t, err)
+		require.NoError(t, env.userRepo.UpdateUser(ctx, u.ID, map[string]any{"created_at": base.AddDate(0, 0, i)}))
+		ids = append(ids, u.ID)
+	}
+	return env, ids
+}
+
+// usernames 返回列表中的用户名
+func usernames(items []user.AdminUserResponse) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Username)
+	}
+	return names
+}
+
+// sortBy 解析排序参数
+func sortBy(t *testing.T, sort string) []paging.Sort {
+	t.Helper()
+	sorts, err := paging.ParseSort(sort)
+	require.NoError(t, err)
+	return sorts
+}
+
+func TestListUsersCursor(t *testing.T) {
+	env, _ := newAdminTestEnv(t)
+	ctx := context.Background()
+	req := user.ListUsersRequest{Username: "user0"}
+
+	for _, sort := range []string{"id", "-created_at", "username"} {
+		t.Run(sort, func(t *testing.T) {
+			var seen []string
+			params := paging.Params{Size: 2, Sort: sortBy(t, sort)}
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 3)
+				resp, err := env.userSvc.ListUsers(ctx, req, params)
+				require.NoError(t, err)
+				assert.Nil(t, resp.Total)
+				seen = append(seen, usernames(resp.Items)...)
+				if resp.NextCursor == "" {
+					break
+				}
+				params.Cursor, err = paging.DecodeCursor(resp.NextCursor)
+				require.NoError(t, err)
+			}
+
+			want := []string{"user01", "user02", "user03", "user04", "user05"}
+			if sort == "-created_at" {
+				want = []string{"user05", "user04", "user03", "user02", "user01"}
+			}
+			assert.Equal(t, want, seen)
+		})
+	}
+
+	// 游标不能用于其他排序方式
+	resp, err := env.userSvc.ListUsers(ctx, user.ListUsersRequest{}, paging.Params{Size: 2, Sort: sortBy(t, "id")})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.NextCursor)
+	cursor, err := paging.DecodeCursor(resp.NextCursor)
+	require.NoError(t, err)
+	_, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{}, paging.Params{Cursor: cursor, Sort: sortBy(t, "-id")})
+	assert.ErrorIs(t, err, paging.ErrInvalidCursor)
+	cursor = &paging.Cursor{Sort: "id", Values: []json.RawMessage{json.RawMessage(`"x"`)}}
+	_, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{}, paging.Params{Cursor: cursor, Sort: sortBy(t, "id")})
+	assert.ErrorIs(t, err, paging.ErrInvalidCursor)
+}
+
+func TestListUsersOffsetAndFilters(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "admin_x", Password: "password123"}))
+
+	resp, err := env.userSvc.ListUsers(ctx, user.ListUsersRequest{Username: "user"}, paging.Params{Page: 2, Size: 2, Sort: sortBy(t, "-id")})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Total)
+	assert.EqualValues(t, 5, *resp.Total)
+	assert.Equal(t, []string{"user03", "user02"}, usernames(resp.Items))
+	assert.Empty(t, resp.NextCursor)
+
+	// 前缀中的通配符按字面匹配
+	resp, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{Username: "admin_"}, paging.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin_x"}, usernames(resp.Items))
+	resp, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{Username: "user_"}, paging.Params{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Items)
+
+	after := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	resp, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{CreatedAfter: &after, CreatedBefore: &before}, paging.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user02", "user03"}, usernames(resp.Items))
+
+	// 按状态筛选
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[0], true))
+	lockedUntil := time.Now().Add(time.Hour)
+	require.NoError(t, env.userRepo.UpdateLoginState(ctx, ids[1], 10, &lockedUntil))
+
+	for status, want := range map[string][]string{
+		"disabled": {"user01"},
+		"locked":   {"user02"},
+		"active":   {"user03", "user04", "user05"},
+	} {
+		resp, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{Username: "user", Status: status}, paging.Params{})
+		require.NoError(t, err)
+		assert.Equal(t, want, usernames(resp.Items), status)
+	}
+	resp, err = env.userSvc.ListUsers(ctx, user.ListUsersRequest{Username: "user0"}, paging.Params{Size: 2})
+	require.NoError(t, err)
+	assert.Equal(t, user.StatusDisabled, resp.Items[0].Status)
+	assert.True(t, resp.Items[1].Locked)
+}
+
+func TestDisableUser(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	login := user.LoginRequest{Username: "user01", Password: "password123"}
+
+	session, err := env.userSvc.Login(ctx, login)
+	require.NoError(t, err)
+
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[0], true))
+	_, err = env.userSvc.Login(ctx, login)
+	assert.ErrorIs(t, err, service.ErrUserDisabled)
+	_, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "user01", Password: "wrong"})
+	assert.EqualError(t, err, "invalid username or password")
+	_, err = env.svc.Refresh(ctx, session.RefreshToken)
+	assert.Error(t, err)
+
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[0], false))
+	_, err = env.userSvc.Login(ctx, login)
+	assert.NoError(t, err)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	env.cfg.Auth.PasswordReset.URL = "https://ginhub.dev/reset?token={token}"
+	resetSvc := service.NewPasswordResetService(env.cfg, data.NewPasswordResetRepo(env.data), env.userRepo, env.roleRepo, env.apiKeyRepo, env.hasher, env.svc, env.throttle, env.attempts, env.mailer)
+
+	// 未设置邮箱的用户无法完成重置，不能要求其重置密码
+	assert.ErrorIs(t, resetSvc.ForceReset(ctx, ids[1]), service.ErrNoEmail)
+	_, err := env.userSvc.Login(ctx, user.LoginRequest{Username: "user02", Password: "password123"})
+	assert.NoError(t, err)
+
+	// 不能要求最后一个管理员重置密码
+	require.NoError(t, env.userRepo.UpdateUser(ctx, ids[0], map[string]any{"email": "user01@example.com"}))
+	require.NoError(t, env.roleRepo.AssignRole(ctx, ids[0], user.RoleAdmin))
+	assert.ErrorIs(t, resetSvc.ForceReset(ctx, ids[0]), service.ErrLastAdmin)
+	require.NoError(t, env.roleRepo.RemoveRole(ctx, ids[0], user.RoleAdmin))
+
+	apiKeySvc := service.NewAPIKeyService(env.cfg, env.apiKeyRepo, env.userRepo, env.roleRepo)
+	key, err := apiKeySvc.CreateAPIKey(ctx, &user.Claims{UserID: ids[0]}, user.CreateAPIKeyRequest{Name: "ci"})
+	require.NoError(t, err)
+	require.NoError(t, resetSvc.ForceReset(ctx, ids[0]))
+
+	// 重置密码前不能登录，也不能使用 API Key
+	_, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "user01", Password: "password123"})
+	assert.ErrorIs(t, err, service.ErrPasswordResetRequired)
+	_, err = apiKeySvc.ValidateAPIKey(ctx, key.Key)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+
+	token := extractToken(t, env.mailer.receive(t).Body)
+	require.NoError(t, resetSvc.ResetPassword(ctx, user.ResetPasswordRequest{Token: token, NewPassword: "newpassword456"}))
+	_, err = env.userSvc.Login(ctx, user.LoginRequest{Username: "user01", Password: "newpassword456"})
+	assert.NoError(t, err)
+}
+
+func TestDeleteAndRestoreUser(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	login := user.LoginRequest{Username: "user01", Password: "password123"}
+
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[0]))
+	_, err := env.userSvc.Login(ctx, login)
+	assert.EqualError(t, err, "invalid username or password")
+	assert.EqualError(t, env.userSvc.DeleteUser(ctx, ids[0]), "user not found")
+
+	resp, err := env.userSvc.ListUsers(ctx, user.ListUsersRequest{Status: "deleted"}, paging.Params{})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, ids[0], resp.Items[0].ID)
+	assert.NotNil(t, resp.Items[0].DeletedAt)
+
+	require.NoError(t, env.userSvc.RestoreUser(ctx, ids[0]))
+	_, err = env.userSvc.Login(ctx, login)
+	assert.NoError(t, err)
+	assert.EqualError(t, env.userSvc.RestoreUser(ctx, ids[0]), "deleted user not found")
+
+	// 用户名被重新注册后不能恢复
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[1]))
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "user02", Password: "password123"}))
+	assert.ErrorIs(t, env.userSvc.RestoreUser(ctx, ids[1]), service.ErrUsernameTaken)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	env.cfg.Auth.Deletion.GracePeriod = 3600
+	purger := service.NewUserPurger(env.cfg, env.userRepo)
+
+	oauthRepo := data.NewOAuthRepo(env.data)
+	client := &user.OAuthClient{ClientID: "purged-admin-client", Name: "CI", CreatedBy: ids[0]}
+	require.NoError(t, oauthRepo.CreateClient(ctx, client))
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[0]))
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[1]))
+
+	// 未超过保留期的用户不会被清理
+	n, err := purger.Purge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = env.userRepo.PurgeDeletedUsers(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.EqualError(t, env.userSvc.RestoreUser(ctx, ids[0]), "deleted user not found")
+
+	// 用户创建的 OAuth 客户端保留，创建者被匿名化
+	client, err = oauthRepo.GetClientByClientID(ctx, client.ClientID)
+	require.NoError(t, err)
+	assert.Zero(t, client.CreatedBy)
+
+	// 未配置保留期时不清理
+	env.cfg.Auth.Deletion.GracePeriod = 0
+	n, err = service.NewUserPurger(env.cfg, env.userRepo).Purge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestLastAdminGuard(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	roleSvc := service.NewRoleService(env.roleRepo, env.userRepo)
+	require.NoError(t, env.roleRepo.AssignRole(ctx, ids[0], user.RoleAdmin))
+
+	// 最后一个管理员不能被删除、禁用或移除管理员角色
+	assert.ErrorIs(t, env.userSvc.DeleteUser(ctx, ids[0]), service.ErrLastAdmin)
+	assert.ErrorIs(t, env.userSvc.SetUserDisabled(ctx, ids[0], true), service.ErrLastAdmin)
+	assert.ErrorIs(t, roleSvc.RemoveRole(ctx, ids[0], user.RoleAdmin), service.ErrLastAdmin)
+
+	// 普通用户不受影响
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[2], true))
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[2]))
+
+	// 有其他管理员时可以操作
+	require.NoError(t, env.roleRepo.AssignRole(ctx, ids[1], user.RoleAdmin))
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[0], true))
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[0], false))
+	require.NoError(t, roleSvc.RemoveRole(ctx, ids[0], user.RoleAdmin))
+	assert.ErrorIs(t, env.userSvc.DeleteUser(ctx, ids[1]), service.ErrLastAdmin)
+}
+
+func TestLastAdminIgnoresInactiveAdmins(t *testing.T) {
+	env, ids := newAdminTestEnv(t)
+	ctx := context.Background()
+	for _, id := range ids[:3] {
+		require.NoError(t, env.roleRepo.AssignRole(ctx, id, user.RoleAdmin))
+	}
+
+	// 已删除和已禁用的管理员保留角色关联，但不算作有效的管理员
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[1]))
+	require.NoError(t, env.userSvc.SetUserDisabled(ctx, ids[2], true))
+	count, err := env.roleRepo.CountUsersWithRole(ctx, user.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.ErrorIs(t, env.userSvc.DeleteUser(ctx, ids[0]), service.ErrLastAdmin)
+
+	// 已禁用的管理员本身可以被删除
+	require.NoError(t, env.userSvc.DeleteUser(ctx, ids[2]))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	util "github.com/HoronLee/GinHub/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// defaultPurgeInterval 未配置时清理已删除用户的间隔
+const defaultPurgeInterval = time.Hour
+
+// UserPurger 定期彻底删除超过保留期的已删除用户
+type UserPurger struct {
+	repo     UserRepo
+	grace    time.Duration
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewUserPurger 创建UserPurger实例（通过Wire注入）
+func NewUserPurger(cfg *config.AppConfig, repo UserRepo) *UserPurger {
+	interval := time.Duration(cfg.Auth.Deletion.PurgeInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+
+	return &UserPurger{
+		repo:     repo,
+		grace:    time.Duration(cfg.Auth.Deletion.GracePeriod) * time.Second,
+		interval: interval,
+	}
+}
+
+// Purge 彻底删除超过保留期的已删除用户，未配置保留期时不做任何处理
+func (p *UserPurger) Purge(ctx context.Context) (int64, error) {
+	if p.grace <= 0 {
+		return 0, nil
+	}
+	return p.repo.PurgeDeletedUsers(ctx, time.Now().Add(-p.grace))
+}
+
+// Start 在后台定期执行清理，未配置保留期时不启动
+func (p *UserPurger) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.grace <= 0 || p.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+					util.GetLogger().Error("Failed to purge deleted users", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止后台清理并等待正在进行的清理结束
+func (p *UserPurger) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+	p.cancel = nil
+}
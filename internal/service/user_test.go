@@ -0,0 +1,296 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/GinHub/internal/config"
+	"github.com/HoronLee/GinHub/internal/data"
+	"github.com/HoronLee/GinHub/internal/model/user"
+	"github.com/HoronLee/GinHub/internal/service"
+	cryptoUtil "github.com/HoronLee/GinHub/internal/util/crypto"
+	"github.com/HoronLee/GinHub/internal/util/mail"
+	"github.com/HoronLee/GinHub/internal/util/request"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeMailer 记录发送的邮件
+type fakeMailer struct {
+	sent chan *mail.Message
+}
+
+func (m *fakeMailer) Send(_ context.Context, msg *mail.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// receive 等待后台发送的邮件
+func (m *fakeMailer) receive(t *testing.T) *mail.Message {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail not sent")
+		return nil
+	}
+}
+
+// extractToken 从邮件正文的链接中提取令牌
+func extractToken(t *testing.T, body string) string {
+	t.Helper()
+	match := regexp.MustCompile(`token=([\w-]+)`).FindStringSubmatch(body)
+	require.Len(t, match, 2, body)
+	return match[1]
+}
+
+// userTestEnv UserService 测试环境
+type userTestEnv struct {
+	*tokenTestEnv
+	userRepo   service.UserRepo
+	apiKeyRepo service.APIKeyRepo
+	attempts   service.LoginAttemptStore
+	hasher     cryptoUtil.PasswordHasher
+	throttle   *service.LoginThrottle
+	emailSvc   *service.EmailVerificationService
+	mailer     *fakeMailer
+	userSvc    *service.UserService
+}
+
+// newUserTestEnv 基于 env 当前的配置创建 UserService，mfa 为 nil 时不支持两步验证
+func newUserTestEnv(t *testing.T, env *tokenTestEnv, mfa *service.MFAService) *userTestEnv {
+	t.Helper()
+
+	attempts, stopGC, err := data.NewLoginAttemptStore(env.cfg, env.data)
+	require.NoError(t, err)
+	t.Cleanup(stopGC)
+
+	userRepo := data.NewUserRepo(env.data)
+	apiKeyRepo := data.NewAPIKeyRepo(env.data)
+	hasher := cryptoUtil.NewBcryptHasher(4)
+	throttle := service.NewLoginThrottle(env.cfg, attempts, userRepo)
+	mailer := &fakeMailer{sent: make(chan *mail.Message, 4)}
+	emailSvc := service.NewEmailVerificationService(env.cfg, data.NewEmailVerificationRepo(env.data), userRepo, attempts, mailer)
+
+	return &userTestEnv{
+		tokenTestEnv: env,
+		userRepo:     userRepo,
+		apiKeyRepo:   apiKeyRepo,
+		attempts:     attempts,
+		hasher:       hasher,
+		throttle:     throttle,
+		emailSvc:     emailSvc,
+		mailer:       mailer,
+		userSvc:      service.NewUserService(userRepo, env.roleRepo, apiKeyRepo, data.NewTransactor(env.cfg, env.data), hasher, env.svc, mfa, throttle, emailSvc),
+	}
+}
+
+func TestRegisterEmail(t *testing.T) {
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	ctx := context.Background()
+
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{
+		Username: "alice",
+		Password: "password123",
+		Email:    "  Alice@Example.COM ",
+	}))
+	alice, err := env.userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, alice.Email)
+	assert.Equal(t, "alice@example.com", *alice.Email)
+	assert.False(t, alice.EmailVerified())
+	assert.Equal(t, []string{"alice@example.com"}, env.mailer.receive(t).To)
+
+	// 规范化后相同的邮箱不能重复注册
+	err = env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice2", Password: "password123", Email: "ALICE@example.com"})
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+
+	// 不填邮箱的用户可以有多个
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "bob", Password: "password123"}))
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "carol", Password: "password123"}))
+
+	// 可以使用邮箱登录
+	resp, err := env.userSvc.Login(ctx, user.LoginRequest{Username: "Alice@Example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+}
+
+func TestEmailVerificationRequired(t *testing.T) {
+	tokenEnv := newTokenTestEnv(t)
+	tokenEnv.cfg.Auth.EmailVerification.Required = true
+	tokenEnv.cfg.Auth.EmailVerification.URL = "https://ginhub.dev/verify?token={token}"
+	env := newUserTestEnv(t, tokenEnv, nil)
+	ctx := context.Background()
+
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123", Email: "alice@example.com"}))
+	first := extractToken(t, env.mailer.receive(t).Body)
+
+	login := user.LoginRequest{Username: "alice", Password: "password123"}
+	_, err := env.userSvc.Login(ctx, login)
+	assert.ErrorIs(t, err, service.ErrEmailNotVerified)
+
+	// 重新发送后旧令牌失效；未注册的邮箱同样返回成功
+	require.NoError(t, env.emailSvc.Resend(ctx, "nobody@example.com"))
+	require.NoError(t, env.emailSvc.Resend(ctx, "ALICE@example.com"))
+	second := extractToken(t, env.mailer.receive(t).Body)
+	assert.ErrorIs(t, env.emailSvc.Verify(ctx, first), service.ErrInvalidVerificationToken)
+
+	require.NoError(t, env.emailSvc.Verify(ctx, second))
+	assert.ErrorIs(t, env.emailSvc.Verify(ctx, second), service.ErrInvalidVerificationToken)
+
+	resp, err := env.userSvc.Login(ctx, login)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	// 已验证的邮箱不再发送验证邮件
+	require.NoError(t, env.emailSvc.Resend(ctx, "alice@example.com"))
+	assert.Empty(t, env.mailer.sent)
+}
+
+func TestResendVerificationRateLimited(t *testing.T) {
+	tokenEnv := newTokenTestEnv(t)
+	tokenEnv.cfg.Auth.EmailVerification.URL = "https://ginhub.dev/verify?token={token}"
+	env := newUserTestEnv(t, tokenEnv, nil)
+	ctx := context.Background()
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123", Email: "alice@example.com"}))
+	token := extractToken(t, env.mailer.receive(t).Body)
+
+	// 同一 IP 每小时最多请求 10 次，不存在的邮箱同样计数
+	fromIP := func(ip string) context.Context {
+		return request.NewContext(ctx, &request.ClientInfo{IP: ip})
+	}
+	for range 10 {
+		require.NoError(t, env.emailSvc.Resend(fromIP("203.0.113.1"), "nobody@example.com"))
+	}
+	require.NoError(t, env.emailSvc.Resend(fromIP("203.0.113.1"), "alice@example.com"))
+
+	// 同一账户每小时最多重新发送 3 封，被限制的请求不会使之前的令牌失效
+	for range 3 {
+		require.NoError(t, env.emailSvc.Resend(fromIP("203.0.113.2"), "alice@example.com"))
+		token = extractToken(t, env.mailer.receive(t).Body)
+	}
+	require.NoError(t, env.emailSvc.Resend(fromIP("203.0.113.3"), "alice@example.com"))
+	assert.Empty(t, env.mailer.sent)
+	require.NoError(t, env.emailSvc.Verify(ctx, token))
+}
+
+func TestUpdateProfile(t *testing.T) {
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	ctx := context.Background()
+
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123", Email: "alice@example.com"}))
+	alice, err := env.userRepo.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+
+	name, locale, bio := "  Alice  ", "zh-CN", "Gopher"
+	resp, err := env.userSvc.UpdateProfile(ctx, alice.ID, user.UpdateProfileRequest{DisplayName: &name, Locale: &locale, Bio: &bio})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", resp.DisplayName)
+	assert.Equal(t, "zh-CN", resp.Locale)
+	assert.Equal(t, "alice@example.com", resp.Email)
+	assert.NotNil(t, resp.Roles)
+
+	// 未提供的字段保持不变，空字符串清空字段
+	empty := ""
+	resp, err = env.userSvc.UpdateProfile(ctx, alice.ID, user.UpdateProfileRequest{Bio: &empty})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", resp.DisplayName)
+	assert.Empty(t, resp.Bio)
+
+	// 公开资料不包含邮箱
+	profile, err := env.userSvc.GetPublicProfile(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", profile.DisplayName)
+	_, err = env.userSvc.GetPublicProfile(ctx, alice.ID+100)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestUpdateProfileValidation(t *testing.T) {
+	valid := func(s string) *string { return &s }
+	for name, tc := range map[string]struct {
+		req user.UpdateProfileRequest
+		ok  bool
+	}{
+		"empty":            {req: user.UpdateProfileRequest{}, ok: true},
+		"clear fields":     {req: user.UpdateProfileRequest{AvatarURL: valid(""), Locale: valid(""), Timezone: valid("")}, ok: true},
+		"valid":            {req: user.UpdateProfileRequest{AvatarURL: valid("https://example.com/a.png"), Locale: valid("en-US"), Timezone: valid("Asia/Shanghai")}, ok: true},
+		"invalid avatar":   {req: user.UpdateProfileRequest{AvatarURL: valid("javascript:alert(1)")}},
+		"invalid locale":   {req: user.UpdateProfileRequest{Locale: valid("not a locale")}},
+		"invalid timezone": {req: user.UpdateProfileRequest{Timezone: valid("Mars/Olympus")}},
+		"long name":        {req: user.UpdateProfileRequest{DisplayName: valid(strings.Repeat("a", 65))}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tc.req)
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// staleUserRepo 前 stale 次按用户名或邮箱查询时返回不存在，模拟并发注册时预检查读到旧数据
+type staleUserRepo struct {
+	service.UserRepo
+	stale int
+}
+
+func (r *staleUserRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	if r.stale > 0 {
+		r.stale--
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.UserRepo.GetUserByUsername(ctx, username)
+}
+
+func (r *staleUserRepo) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
+	if r.stale > 0 {
+		r.stale--
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.UserRepo.GetUserByEmail(ctx, email)
+}
+
+func TestRegisterUniqueViolation(t *testing.T) {
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	ctx := context.Background()
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123", Email: "alice@example.com"}))
+	env.mailer.receive(t)
+
+	// 预检查通过后写入时唯一索引冲突，仍然返回具体是哪个字段被占用
+	repo := &staleUserRepo{UserRepo: env.userRepo}
+	svc := service.NewUserService(repo, env.roleRepo, data.NewAPIKeyRepo(env.data), data.NewTransactor(env.cfg, env.data), env.hasher, env.svc, nil, env.throttle, env.emailSvc)
+
+	repo.stale = 2
+	err := svc.Register(ctx, user.RegisterRequest{Username: "alice", Password: "password123", Email: "other@example.com"})
+	assert.ErrorIs(t, err, service.ErrUsernameTaken)
+
+	repo.stale = 2
+	err = svc.Register(ctx, user.RegisterRequest{Username: "alice2", Password: "password123", Email: "alice@example.com"})
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+}
+
+func TestRegisterDoesNotGrantAdmin(t *testing.T) {
+	env := newUserTestEnv(t, newTokenTestEnv(t), nil)
+	ctx := context.Background()
+	rbacCfg := config.Config.Auth.RBAC
+	t.Cleanup(func() { config.Config.Auth.RBAC = rbacCfg })
+	config.Config.Auth.RBAC.DefaultRole = user.RoleUser
+	config.Config.Auth.RBAC.Admins = []string{"root"}
+
+	// 配置中的管理员用户名被抢先注册时只获得默认角色
+	require.NoError(t, env.userSvc.Register(ctx, user.RegisterRequest{Username: "root", Password: "password123"}))
+	root, err := env.userRepo.GetUserByUsername(ctx, "root")
+	require.NoError(t, err)
+	roles, err := env.roleRepo.GetUserRoles(ctx, root.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, user.RoleUser, roles[0].Name)
+}
@@ -2,6 +2,9 @@ package crypto
 
 import (
 	"crypto/md5"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"math/rand"
 	"time"
@@ -27,3 +30,27 @@ func GenerateRandomString(length int) string {
 	}
 	return string(b)
 }
+
+// GenerateSecureToken 使用 crypto/rand 生成指定字节数的随机令牌（base64url 编码，无填充）
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRandomID 使用 crypto/rand 生成指定字节数的随机十六进制标识
+func GenerateRandomID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SHA256Hex 计算内容的 SHA-256 摘要并以十六进制返回
+func SHA256Hex(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,315 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的密码哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmMD5      = "md5" // 仅用于校验历史数据，不能作为新哈希的算法
+)
+
+var (
+	// ErrUnknownHashFormat 无法识别的哈希格式
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	// ErrInvalidHash 哈希格式可识别但内容损坏
+	ErrInvalidHash = errors.New("invalid password hash")
+)
+
+// PasswordHasher 密码哈希器接口
+type PasswordHasher interface {
+	// Hash 对明文密码进行哈希，返回包含算法和参数的编码字符串
+	Hash(password string) (string, error)
+	// Verify 以常量时间校验明文密码与编码哈希是否匹配
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash 判断编码哈希是否需要使用当前算法/参数重新生成
+	NeedsRehash(encoded string) bool
+}
+
+// PasswordConfig 密码哈希配置
+type PasswordConfig struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// NewPasswordHasher 根据配置创建密码哈希器
+// 返回的哈希器使用配置的算法生成新哈希，同时能够校验 bcrypt、argon2id 以及历史 MD5 哈希
+func NewPasswordHasher(cfg PasswordConfig) (PasswordHasher, error) {
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+	argon2Hasher := NewArgon2idHasher(cfg.Argon2)
+
+	var primary PasswordHasher
+	switch cfg.Algorithm {
+	case AlgorithmBcrypt:
+		primary = bcryptHasher
+	case AlgorithmArgon2id, "":
+		primary = argon2Hasher
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", cfg.Algorithm)
+	}
+
+	return &multiHasher{
+		primary:   primary,
+		algorithm: cfg.Algorithm,
+		verifiers: map[string]PasswordHasher{
+			AlgorithmBcrypt:   bcryptHasher,
+			AlgorithmArgon2id: argon2Hasher,
+			AlgorithmMD5:      legacyMD5Hasher{},
+		},
+	}, nil
+}
+
+// IdentifyAlgorithm 根据编码哈希识别所使用的算法
+func IdentifyAlgorithm(encoded string) (string, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt, nil
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id, nil
+	case isLegacyMD5(encoded):
+		return AlgorithmMD5, nil
+	default:
+		return "", ErrUnknownHashFormat
+	}
+}
+
+// multiHasher 使用主算法生成哈希，并按哈希前缀分派校验
+type multiHasher struct {
+	primary   PasswordHasher
+	algorithm string
+	verifiers map[string]PasswordHasher
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+func (h *multiHasher) Verify(password, encoded string) (bool, error) {
+	algorithm, err := IdentifyAlgorithm(encoded)
+	if err != nil {
+		return false, err
+	}
+	return h.verifiers[algorithm].Verify(password, encoded)
+}
+
+func (h *multiHasher) NeedsRehash(encoded string) bool {
+	algorithm, err := IdentifyAlgorithm(encoded)
+	if err != nil {
+		return true
+	}
+	primaryAlgorithm := h.algorithm
+	if primaryAlgorithm == "" {
+		primaryAlgorithm = AlgorithmArgon2id
+	}
+	if algorithm != primaryAlgorithm {
+		return true
+	}
+	return h.primary.NeedsRehash(encoded)
+}
+
+// BcryptHasher 基于 bcrypt 的密码哈希器
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 哈希器，cost 非法时使用默认值
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	return true, nil
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // 内存开销，单位 KiB
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度，单位字节
+	KeyLength   uint32 // 输出密钥长度，单位字节
+}
+
+// DefaultArgon2Params 默认 argon2id 参数（参考 RFC 9106 推荐值）
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// argon2MaxFactor 校验时哈希参数相对当前参数（不低于默认参数）的最大倍数，
+// 避免异常或导入的哈希在每次登录时占用过多内存和 CPU
+const argon2MaxFactor = 4
+
+// Argon2idHasher 基于 argon2id 的密码哈希器
+// 编码格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// NewArgon2idHasher 创建 argon2id 哈希器，未设置的参数使用默认值
+func NewArgon2idHasher(params Argon2Params) *Argon2idHasher {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Params.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2Params.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2Params.KeyLength
+	}
+	return &Argon2idHasher{params: params}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	if !h.withinLimits(params) {
+		return false, fmt.Errorf("%w: argon2id parameters out of range", ErrInvalidHash)
+	}
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+// withinLimits 判断哈希参数是否在允许的范围内，迭代次数和并行度不能为 0
+func (h *Argon2idHasher) withinLimits(params Argon2Params) bool {
+	limit := func(current, def uint32) uint32 {
+		return max(current, def) * argon2MaxFactor
+	}
+	return params.Memory <= limit(h.params.Memory, DefaultArgon2Params.Memory) &&
+		params.Iterations >= 1 && params.Iterations <= limit(h.params.Iterations, DefaultArgon2Params.Iterations) &&
+		params.Parallelism >= 1 && uint32(params.Parallelism) <= limit(uint32(h.params.Parallelism), uint32(DefaultArgon2Params.Parallelism))
+}
+
+// decodeArgon2id 解析 argon2id 编码哈希
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// 分割后依次为: "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// legacyMD5Hasher 仅用于校验历史遗留的无盐 MD5 哈希
+type legacyMD5Hasher struct{}
+
+func (legacyMD5Hasher) Hash(string) (string, error) {
+	return "", errors.New("md5 must not be used to hash new passwords")
+}
+
+func (legacyMD5Hasher) Verify(password, encoded string) (bool, error) {
+	actual := MD5Encrypt(password)
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(strings.ToLower(encoded))) == 1, nil
+}
+
+func (legacyMD5Hasher) NeedsRehash(string) bool {
+	return true
+}
+
+// isLegacyMD5 判断是否为 32 位十六进制的 MD5 哈希
+func isLegacyMD5(encoded string) bool {
+	if len(encoded) != 32 {
+		return false
+	}
+	for _, c := range encoded {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试用的低开销参数，避免单测耗时过长
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHashAndVerify(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	encoded, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := hasher.Verify("password123", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("wrong-password", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 同一密码两次哈希的盐不同
+	another, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, another)
+
+	assert.False(t, hasher.NeedsRehash(encoded))
+	stronger := NewArgon2idHasher(Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	assert.True(t, stronger.NeedsRehash(encoded))
+}
+
+func TestArgon2idVerifyInvalidHash(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	for _, encoded := range []string{
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+		// 参数超出上限或为 0
+		"$argon2id$v=19$m=4194304,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1000,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=255$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$aGFzaA",
+	} {
+		_, err := hasher.Verify("password123", encoded)
+		assert.ErrorIs(t, err, ErrInvalidHash, encoded)
+	}
+
+	// 默认参数生成的哈希在当前参数较低时仍能校验
+	encoded, err := NewArgon2idHasher(DefaultArgon2Params).Hash("password123")
+	require.NoError(t, err)
+	ok, err := hasher.Verify("password123", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBcryptHashAndVerify(t *testing.T) {
+	hasher := NewBcryptHasher(4)
+
+	encoded, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$04$"))
+
+	ok, err := hasher.Verify("password123", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("wrong-password", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, hasher.NeedsRehash(encoded))
+	assert.True(t, NewBcryptHasher(5).NeedsRehash(encoded))
+}
+
+func TestPasswordHasherVerifiesAllFormats(t *testing.T) {
+	hasher, err := NewPasswordHasher(PasswordConfig{Algorithm: AlgorithmArgon2id, Argon2: testArgon2Params})
+	require.NoError(t, err)
+
+	bcryptHash, err := NewBcryptHasher(4).Hash("password123")
+	require.NoError(t, err)
+	argon2Hash, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	md5Hash := MD5Encrypt("password123")
+
+	for _, encoded := range []string{bcryptHash, argon2Hash, md5Hash, strings.ToUpper(md5Hash)} {
+		ok, err := hasher.Verify("password123", encoded)
+		assert.NoError(t, err, encoded)
+		assert.True(t, ok, encoded)
+
+		ok, err = hasher.Verify("wrong-password", encoded)
+		assert.NoError(t, err, encoded)
+		assert.False(t, ok, encoded)
+	}
+
+	// 只有当前算法和参数生成的哈希不需要升级
+	assert.False(t, hasher.NeedsRehash(argon2Hash))
+	assert.True(t, hasher.NeedsRehash(bcryptHash))
+	assert.True(t, hasher.NeedsRehash(md5Hash))
+}
+
+func TestPasswordHasherUnknownFormat(t *testing.T) {
+	hasher, err := NewPasswordHasher(PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+
+	ok, err := hasher.Verify("password123", "plaintext-password")
+	assert.ErrorIs(t, err, ErrUnknownHashFormat)
+	assert.False(t, ok)
+	assert.True(t, hasher.NeedsRehash("plaintext-password"))
+}
+
+func TestNewPasswordHasherUnsupportedAlgorithm(t *testing.T) {
+	_, err := NewPasswordHasher(PasswordConfig{Algorithm: AlgorithmMD5})
+	assert.Error(t, err)
+
+	_, err = NewPasswordHasher(PasswordConfig{Algorithm: "scrypt"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package dbctx
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// primaryKey 是一个未导出的类型，用作在上下文中存储读主库标记的键
+type primaryKey struct{}
+
+// primaryState 读主库标记，sticky 为 true 时在该上下文中发生写入后才开始读主库
+type primaryState struct {
+	sticky  bool
+	written atomic.Bool
+}
+
+// WithPrimary 返回强制从主库读取的上下文，用于写入后需要立即读到最新数据的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, &primaryState{})
+}
+
+// WithReadYourWrites 返回写入后读主库的上下文，在该上下文中写入数据后，后续读取都从主库执行
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(primaryKey{}).(*primaryState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, primaryKey{}, &primaryState{sticky: true})
+}
+
+// MarkWritten 记录上下文中发生了写入
+func MarkWritten(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if state, ok := ctx.Value(primaryKey{}).(*primaryState); ok {
+		state.written.Store(true)
+	}
+}
+
+// UsePrimary 判断上下文中的读取是否需要从主库执行
+func UsePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	state, ok := ctx.Value(primaryKey{}).(*primaryState)
+	return ok && (!state.sticky || state.written.Load())
+}
@@ -4,32 +4,217 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	// ErrUnknownKeyID 令牌头部的 kid 不在当前密钥集中
+	ErrUnknownKeyID = errors.New("unknown key id")
+
+	// ErrTokenMalformed 令牌格式错误
+	ErrTokenMalformed = errors.New("token is malformed")
+	// ErrTokenExpired 令牌已过期
+	ErrTokenExpired = errors.New("token is expired")
+	// ErrTokenNotValidYet 令牌尚未生效
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	// ErrTokenInvalidAudience 令牌受众不匹配
+	ErrTokenInvalidAudience = errors.New("token has invalid audience")
+	// ErrTokenInvalidIssuer 令牌签发者不匹配
+	ErrTokenInvalidIssuer = errors.New("token has invalid issuer")
+	// ErrTokenSignatureInvalid 令牌签名无效、签名算法不被允许或签名密钥未知
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	// ErrTokenInvalid 其他原因导致的令牌无效
+	ErrTokenInvalid = errors.New("token is invalid")
+)
+
 // JWT 是一个用于处理 JWT 操作的通用结构体。
 // 类型参数 'T' 应该是你的自定义 claims 结构体（例如，MyUserClaims）。
 // 你的结构体指针 (*T) 必须实现 jwt.Claims 接口。
 // 最简单的方法是在你的结构体中嵌入 jwt.RegisteredClaims。
 type JWT[T any] struct {
+	method jwt.SigningMethod
+
+	// HS256 使用的对称密钥
 	secretKey []byte
+
+	// 非对称算法使用的密钥集，signingKey 为当前用于签名的密钥
+	signingKey *Key
+	keys       map[string]*Key
+
+	// 解析令牌时默认使用的校验选项
+	parseOptions []ParseOption
 }
 
 // Config 保存 JWT 服务的配置。
 type Config struct {
 	SecretKey string
+
+	// Algorithm 签名算法，为空时使用 HS256
+	Algorithm string
+	// Keys 非对称算法的密钥集，轮换期间可以同时包含新旧密钥
+	Keys []*Key
+	// ActiveKeyID 用于签名的密钥 kid，为空时使用第一个包含私钥的密钥
+	ActiveKeyID string
+
+	// Issuer 期望的签发者，为空时不校验
+	Issuer string
+	// Audience 期望的受众，为空时不校验
+	Audience string
+	// Leeway 校验过期时间和生效时间时允许的时钟偏差
+	Leeway time.Duration
 }
 
-// NewJWT 创建一个新的通用 JWT 服务。
+// ParseOption 解析令牌时的校验选项
+type ParseOption func(*parseConfig)
+
+// parseConfig 解析令牌时的校验配置
+type parseConfig struct {
+	issuer     string
+	audience   string
+	algorithms []string
+	leeway     time.Duration
+}
+
+// WithIssuer 要求令牌的 iss 与 issuer 一致
+func WithIssuer(issuer string) ParseOption {
+	return func(c *parseConfig) {
+		c.issuer = issuer
+	}
+}
+
+// WithAudience 要求令牌的 aud 包含 audience
+func WithAudience(audience string) ParseOption {
+	return func(c *parseConfig) {
+		c.audience = audience
+	}
+}
+
+// WithAlgorithms 限制允许的签名算法，默认只允许当前配置的签名算法
+func WithAlgorithms(algorithms ...string) ParseOption {
+	return func(c *parseConfig) {
+		c.algorithms = algorithms
+	}
+}
+
+// WithLeeway 设置校验时间类 claims 时允许的时钟偏差
+func WithLeeway(leeway time.Duration) ParseOption {
+	return func(c *parseConfig) {
+		c.leeway = leeway
+	}
+}
+
+// defaultParseOptions 根据配置生成默认的校验选项
+func defaultParseOptions(cfg *Config) []ParseOption {
+	var opts []ParseOption
+	if cfg.Issuer != "" {
+		opts = append(opts, WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, WithAudience(cfg.Audience))
+	}
+	if cfg.Leeway > 0 {
+		opts = append(opts, WithLeeway(cfg.Leeway))
+	}
+	return opts
+}
+
+// NewJWT 创建一个使用 HS256 对称密钥的通用 JWT 服务。
+// 如需使用非对称算法，请使用 New。
 func NewJWT[T any](cfg *Config) *JWT[T] {
 	return &JWT[T]{
-		secretKey: []byte(cfg.SecretKey),
+		method:       jwt.SigningMethodHS256,
+		secretKey:    []byte(cfg.SecretKey),
+		parseOptions: defaultParseOptions(cfg),
+	}
+}
+
+// New 根据配置创建一个新的通用 JWT 服务，支持 HS256、RS256、ES256 和 EdDSA。
+func New[T any](cfg *Config) (*JWT[T], error) {
+	if cfg.Algorithm == "" || cfg.Algorithm == AlgorithmHS256 {
+		if cfg.SecretKey == "" {
+			return nil, errors.New("HS256 requires a secret key")
+		}
+		return NewJWT[T](cfg), nil
+	}
+
+	method := jwt.GetSigningMethod(cfg.Algorithm)
+	switch cfg.Algorithm {
+	case AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", cfg.Algorithm)
 	}
+
+	j := &JWT[T]{
+		method:       method,
+		keys:         make(map[string]*Key, len(cfg.Keys)),
+		parseOptions: defaultParseOptions(cfg),
+	}
+	for _, key := range cfg.Keys {
+		if key.ID == "" {
+			return nil, errors.New("every key must have a kid")
+		}
+		if _, ok := j.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id: %s", key.ID)
+		}
+		if err := key.checkAlgorithm(cfg.Algorithm); err != nil {
+			return nil, fmt.Errorf("key %s: %w", key.ID, err)
+		}
+		j.keys[key.ID] = key
+
+		if cfg.ActiveKeyID == "" && j.signingKey == nil && key.PrivateKey != nil {
+			j.signingKey = key
+		}
+	}
+
+	if cfg.ActiveKeyID != "" {
+		key, ok := j.keys[cfg.ActiveKeyID]
+		if !ok {
+			return nil, fmt.Errorf("active key %s: %w", cfg.ActiveKeyID, ErrUnknownKeyID)
+		}
+		j.signingKey = key
+	}
+	if j.signingKey == nil || j.signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("%s requires a signing key with a private key", cfg.Algorithm)
+	}
+
+	return j, nil
+}
+
+// Derive 基于已有的 JWT 服务创建另一种 claims 类型的 JWT 服务。
+// 新服务共享签名算法和密钥集，并在原有校验选项之后追加 opts，
+// 通常用于签发受众不同的短期令牌（例如二次验证挑战令牌）。
+func Derive[U, T any](j *JWT[T], opts ...ParseOption) *JWT[U] {
+	parseOptions := make([]ParseOption, 0, len(j.parseOptions)+len(opts))
+	parseOptions = append(parseOptions, j.parseOptions...)
+	parseOptions = append(parseOptions, opts...)
+
+	return &JWT[U]{
+		method:       j.method,
+		secretKey:    j.secretKey,
+		signingKey:   j.signingKey,
+		keys:         j.keys,
+		parseOptions: parseOptions,
+	}
+}
+
+// Algorithm 返回签发令牌使用的签名算法名称，例如 "HS256"。
+func (j *JWT[T]) Algorithm() string {
+	return j.method.Alg()
 }
 
 // GenerateToken 使用提供的 claims 创建一个新的 JWT 令牌。
 // claims 参数必须是你的自定义 claims 结构体的指针。
+// 使用非对称算法时，令牌头部会带上签名密钥的 kid。
 func (j *JWT[T]) GenerateToken(claims *T) (string, error) {
 	// claims 结构体的指针必须实现 jwt.Claims。
 	jwtClaims, ok := any(claims).(jwt.Claims)
@@ -37,12 +222,19 @@ func (j *JWT[T]) GenerateToken(claims *T) (string, error) {
 		return "", fmt.Errorf("claims type *%T does not implement jwt.Claims. Did you forget to embed jwt.RegisteredClaims?", *claims)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims)
-	return token.SignedString(j.secretKey)
+	token := jwt.NewWithClaims(j.method, jwtClaims)
+	if j.signingKey == nil {
+		return token.SignedString(j.secretKey)
+	}
+
+	token.Header["kid"] = j.signingKey.ID
+	return token.SignedString(j.signingKey.PrivateKey)
 }
 
 // ParseToken 解析令牌字符串并返回填充的自定义 claims。
-func (j *JWT[T]) ParseToken(tokenString string) (*T, error) {
+// 默认只接受当前配置的签名算法，并按 Config 校验 iss、aud 和时钟偏差；
+// opts 会覆盖对应的默认选项。失败时返回的错误可以用 errors.Is 与 ErrTokenXxx 比较。
+func (j *JWT[T]) ParseToken(tokenString string, opts ...ParseOption) (*T, error) {
 	// 创建一个指向 T 类型零值 claims 对象的新指针。
 	claims := new(T)
 
@@ -52,21 +244,80 @@ func (j *JWT[T]) ParseToken(tokenString string) (*T, error) {
 		return nil, fmt.Errorf("claims type *%T does not implement jwt.Claims. Did you forget to embed jwt.RegisteredClaims?", *claims)
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, claimsInterface, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claimsInterface, j.keyFunc, j.parserOptions(opts)...)
 
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, ErrTokenInvalid
 	}
 
 	return claims, nil
 }
 
+// parserOptions 合并默认选项和调用方选项，转换为 jwt.ParserOption
+func (j *JWT[T]) parserOptions(opts []ParseOption) []jwt.ParserOption {
+	cfg := &parseConfig{algorithms: []string{j.method.Alg()}}
+	for _, opt := range j.parseOptions {
+		opt(cfg)
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(cfg.algorithms)}
+	if cfg.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.issuer))
+	}
+	if cfg.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.audience))
+	}
+	if cfg.leeway > 0 {
+		parserOpts = append(parserOpts, jwt.WithLeeway(cfg.leeway))
+	}
+	return parserOpts
+}
+
+// classifyError 将 jwt 库的错误归类为本包的哨兵错误，同时保留原始错误
+func classifyError(err error) error {
+	var sentinel error
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		sentinel = ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid),
+		errors.Is(err, jwt.ErrTokenUnverifiable),
+		errors.Is(err, ErrUnknownKeyID):
+		sentinel = ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		sentinel = ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		sentinel = ErrTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		sentinel = ErrTokenInvalidAudience
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		sentinel = ErrTokenInvalidIssuer
+	default:
+		sentinel = ErrTokenInvalid
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// keyFunc 根据令牌头部的 kid 选择验证密钥。
+func (j *JWT[T]) keyFunc(token *jwt.Token) (interface{}, error) {
+	if j.keys == nil {
+		return j.secretKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	return key.PublicKey, nil
+}
+
 // authKey 是一个未导出的类型，用作在上下文中存储 claims 的键
 // 以防止与其他包发生冲突。
 type authKey struct{}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -124,8 +125,81 @@ func TestParseTokenExpired(t *testing.T) {
 
 	// Try to parse the expired token
 	_, err = jwtService.ParseToken(token)
-	if err == nil {
-		t.Error("ParseToken should fail with expired token")
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("ParseToken should fail with ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestParseTokenValidation(t *testing.T) {
+	jwtService := NewJWT[TestClaims](&Config{
+		SecretKey: "test-secret-key",
+		Issuer:    "ginhub",
+		Audience:  "ginhub-api",
+		Leeway:    time.Minute,
+	})
+
+	newToken := func(issuer, audience string, notBefore time.Time) string {
+		token, err := jwtService.GenerateToken(&TestClaims{
+			UserID: 1,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				NotBefore: jwt.NewNumericDate(notBefore),
+				Issuer:    issuer,
+				Audience:  []string{audience},
+			},
+		})
+		if err != nil {
+			t.Fatalf("GenerateToken failed: %v", err)
+		}
+		return token
+	}
+
+	// 使用其他密钥签发的令牌
+	otherService := NewJWT[TestClaims](&Config{SecretKey: "other-secret-key"})
+	forged, err := otherService.GenerateToken(&TestClaims{UserID: 1})
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// 使用不允许的签名算法签发的令牌
+	hs384, err := jwt.NewWithClaims(jwt.SigningMethodHS384, &TestClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "ginhub", Audience: []string{"ginhub-api"}},
+	}).SignedString([]byte("test-secret-key"))
+	if err != nil {
+		t.Fatalf("SignedString failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []ParseOption
+		wantErr error
+	}{
+		{"valid", newToken("ginhub", "ginhub-api", time.Now()), nil, nil},
+		{"within leeway", newToken("ginhub", "ginhub-api", time.Now().Add(30*time.Second)), nil, nil},
+		{"not valid yet", newToken("ginhub", "ginhub-api", time.Now().Add(10*time.Minute)), nil, ErrTokenNotValidYet},
+		{"wrong issuer", newToken("someone-else", "ginhub-api", time.Now()), nil, ErrTokenInvalidIssuer},
+		{"wrong audience", newToken("ginhub", "other-api", time.Now()), nil, ErrTokenInvalidAudience},
+		{"audience override", newToken("ginhub", "other-api", time.Now()), []ParseOption{WithAudience("other-api")}, nil},
+		{"bad signature", forged, nil, ErrTokenSignatureInvalid},
+		{"algorithm not allowed", hs384, nil, ErrTokenSignatureInvalid},
+		{"algorithm allowed", hs384, []ParseOption{WithAlgorithms(AlgorithmHS256, "HS384")}, nil},
+		{"malformed", "invalid.token.here", nil, ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwtService.ParseToken(tt.token, tt.opts...)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ParseToken failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
 	}
 }
 
@@ -200,3 +274,40 @@ func TestDifferentClaimsTypes(t *testing.T) {
 		t.Errorf("Expected Role %s, got %s", claims.Role, parsedClaims.Role)
 	}
 }
+
+func TestDerive(t *testing.T) {
+	base := NewJWT[TestClaims](&Config{
+		SecretKey: "test-secret-key",
+		Audience:  "ginhub-api",
+	})
+
+	type challengeClaims struct {
+		Nonce string `json:"nonce"`
+		jwt.RegisteredClaims
+	}
+	derived := Derive[challengeClaims](base, WithAudience("ginhub-mfa"))
+
+	token, err := derived.GenerateToken(&challengeClaims{
+		Nonce: "abc",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			Audience:  []string{"ginhub-mfa"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	claims, err := derived.ParseToken(token)
+	if err != nil {
+		t.Fatalf("derived service should parse its own token: %v", err)
+	}
+	if claims.Nonce != "abc" {
+		t.Errorf("unexpected nonce %q", claims.Nonce)
+	}
+
+	// 受众不同，原服务不接受派生服务签发的令牌
+	if _, err := base.ParseToken(token); !errors.Is(err, ErrTokenInvalidAudience) {
+		t.Errorf("base service should reject derived token with ErrTokenInvalidAudience, got %v", err)
+	}
+}
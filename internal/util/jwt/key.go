@@ -0,0 +1,196 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"slices"
+)
+
+// Key 非对称签名密钥。
+// 只包含公钥的密钥仅用于验证，适用于密钥轮换期间保留旧密钥。
+type Key struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// LoadKey 从 PEM 文件加载密钥。
+// privateKeyFile 和 publicKeyFile 至少提供一个；提供私钥时公钥由私钥推导。
+func LoadKey(id, privateKeyFile, publicKeyFile string) (*Key, error) {
+	key := &Key{ID: id}
+
+	switch {
+	case privateKeyFile != "":
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key %s: %w", privateKeyFile, err)
+		}
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	case publicKeyFile != "":
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %s: %w", publicKeyFile, err)
+		}
+		key.PublicKey = pub
+	default:
+		return nil, errors.New("either a private key or a public key file is required")
+	}
+
+	return key, nil
+}
+
+// ParsePrivateKeyPEM 解析 PKCS#8、PKCS#1 (RSA) 或 SEC 1 (EC) 格式的 PEM 私钥。
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPEM 解析 PKIX 或 PKCS#1 (RSA) 格式的 PEM 公钥。
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// checkAlgorithm 校验密钥类型与签名算法是否匹配。
+func (k *Key) checkAlgorithm(algorithm string) error {
+	if k.PublicKey == nil {
+		return errors.New("public key is required")
+	}
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if algorithm != AlgorithmRS256 {
+			return fmt.Errorf("RSA key cannot be used with %s", algorithm)
+		}
+	case *ecdsa.PublicKey:
+		if algorithm != AlgorithmES256 {
+			return fmt.Errorf("EC key cannot be used with %s", algorithm)
+		}
+		if pub.Curve != elliptic.P256() {
+			return errors.New("ES256 requires a P-256 key")
+		}
+	case ed25519.PublicKey:
+		if algorithm != AlgorithmEdDSA {
+			return fmt.Errorf("Ed25519 key cannot be used with %s", algorithm)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// JWK JSON Web Key（RFC 7517）中的公钥表示。
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC / OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JSON Web Key Set。
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回当前密钥集中所有公钥的 JWKS 表示。
+// HS256 的对称密钥不会被公开，此时返回空集合。
+// 密钥按 kid 排序，保证相同的密钥集每次输出相同，便于 HTTP 缓存。
+func (j *JWT[T]) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	alg := j.method.Alg()
+	for _, kid := range slices.Sorted(maps.Keys(j.keys)) {
+		if jwk, ok := j.keys[kid].jwk(alg); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// jwk 将公钥转换为 JWK。
+func (k *Key) jwk(alg string) (JWK, bool) {
+	jwk := JWK{Use: "sig", Alg: alg, Kid: k.ID}
+	enc := base64.RawURLEncoding
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = enc.EncodeToString(pub.N.Bytes())
+		jwk.E = enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhKey, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, false
+		}
+		// 未压缩格式: 0x04 || X || Y
+		raw := ecdhKey.Bytes()
+		size := (len(raw) - 1) / 2
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = enc.EncodeToString(raw[1 : 1+size])
+		jwk.Y = enc.EncodeToString(raw[1+size:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = enc.EncodeToString(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
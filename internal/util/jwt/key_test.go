@@ -0,0 +1,258 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestSigner 生成测试用的私钥
+func newTestSigner(t *testing.T, algorithm string) crypto.Signer {
+	t.Helper()
+
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch algorithm {
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatalf("generate %s key failed: %v", algorithm, err)
+	}
+	return signer
+}
+
+func newTestClaims() *TestClaims {
+	return &TestClaims{
+		UserID:   7,
+		Username: "asymmetric",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestAsymmetricRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			signer := newTestSigner(t, algorithm)
+			jwtService, err := New[TestClaims](&Config{
+				Algorithm: algorithm,
+				Keys:      []*Key{{ID: "k1", PrivateKey: signer, PublicKey: signer.Public()}},
+			})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			token, err := jwtService.GenerateToken(newTestClaims())
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &TestClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified failed: %v", err)
+			}
+			if parsed.Header["kid"] != "k1" || parsed.Header["alg"] != algorithm {
+				t.Errorf("unexpected header: %v", parsed.Header)
+			}
+
+			claims, err := jwtService.ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken failed: %v", err)
+			}
+			if claims.UserID != 7 || claims.Username != "asymmetric" {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldSigner := newTestSigner(t, AlgorithmES256)
+	newSigner := newTestSigner(t, AlgorithmES256)
+
+	// 轮换前只有旧密钥
+	before, err := New[TestClaims](&Config{
+		Algorithm: AlgorithmES256,
+		Keys:      []*Key{{ID: "old", PrivateKey: oldSigner, PublicKey: oldSigner.Public()}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	oldToken, err := before.GenerateToken(newTestClaims())
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// 轮换期间：新密钥签名，旧密钥只保留公钥用于验证
+	during, err := New[TestClaims](&Config{
+		Algorithm:   AlgorithmES256,
+		ActiveKeyID: "new",
+		Keys: []*Key{
+			{ID: "old", PublicKey: oldSigner.Public()},
+			{ID: "new", PrivateKey: newSigner, PublicKey: newSigner.Public()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := during.ParseToken(oldToken); err != nil {
+		t.Errorf("token signed by old key should still verify: %v", err)
+	}
+	newToken, err := during.GenerateToken(newTestClaims())
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, err := during.ParseToken(newToken); err != nil {
+		t.Errorf("token signed by new key should verify: %v", err)
+	}
+	if jwks := during.JWKS(); len(jwks.Keys) != 2 {
+		t.Errorf("JWKS should publish both keys during rotation, got %d", len(jwks.Keys))
+	} else if jwks.Keys[0].Kid != "new" || jwks.Keys[1].Kid != "old" {
+		t.Errorf("JWKS keys should be sorted by kid, got %s, %s", jwks.Keys[0].Kid, jwks.Keys[1].Kid)
+	}
+
+	// 轮换完成后旧令牌被拒绝
+	after, err := New[TestClaims](&Config{
+		Algorithm: AlgorithmES256,
+		Keys:      []*Key{{ID: "new", PrivateKey: newSigner, PublicKey: newSigner.Public()}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := after.ParseToken(oldToken); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("token signed by removed key should fail with ErrUnknownKeyID, got %v", err)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	rsaSigner := newTestSigner(t, AlgorithmRS256)
+
+	tests := map[string]*Config{
+		"empty secret":           {Algorithm: AlgorithmHS256},
+		"unsupported algorithm":  {Algorithm: "none"},
+		"missing keys":           {Algorithm: AlgorithmRS256},
+		"verification-only keys": {Algorithm: AlgorithmRS256, Keys: []*Key{{ID: "k1", PublicKey: rsaSigner.Public()}}},
+		"key algorithm mismatch": {Algorithm: AlgorithmES256, Keys: []*Key{{ID: "k1", PrivateKey: rsaSigner, PublicKey: rsaSigner.Public()}}},
+		"missing kid":            {Algorithm: AlgorithmRS256, Keys: []*Key{{PrivateKey: rsaSigner, PublicKey: rsaSigner.Public()}}},
+		"unknown active key":     {Algorithm: AlgorithmRS256, ActiveKeyID: "k2", Keys: []*Key{{ID: "k1", PrivateKey: rsaSigner, PublicKey: rsaSigner.Public()}}},
+		"duplicate kid":          {Algorithm: AlgorithmRS256, Keys: []*Key{{ID: "k1", PrivateKey: rsaSigner, PublicKey: rsaSigner.Public()}, {ID: "k1", PublicKey: rsaSigner.Public()}}},
+		"ed25519 key with ES256": {Algorithm: AlgorithmES256, Keys: []*Key{{ID: "k1", PublicKey: newTestSigner(t, AlgorithmEdDSA).Public()}}},
+	}
+
+	for name, cfg := range tests {
+		if _, err := New[TestClaims](cfg); err == nil {
+			t.Errorf("%s: New should fail", name)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	hs := NewJWT[TestClaims](&Config{SecretKey: "test-secret-key"})
+	if jwks := hs.JWKS(); len(jwks.Keys) != 0 {
+		t.Errorf("HS256 must not publish any key, got %d", len(jwks.Keys))
+	}
+
+	expected := map[string]struct{ kty, crv string }{
+		AlgorithmRS256: {"RSA", ""},
+		AlgorithmES256: {"EC", "P-256"},
+		AlgorithmEdDSA: {"OKP", "Ed25519"},
+	}
+	for algorithm, want := range expected {
+		signer := newTestSigner(t, algorithm)
+		jwtService, err := New[TestClaims](&Config{
+			Algorithm: algorithm,
+			Keys:      []*Key{{ID: "k1", PrivateKey: signer, PublicKey: signer.Public()}},
+		})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+
+		jwks := jwtService.JWKS()
+		if len(jwks.Keys) != 1 {
+			t.Fatalf("%s: expected 1 key, got %d", algorithm, len(jwks.Keys))
+		}
+		jwk := jwks.Keys[0]
+		if jwk.Kty != want.kty || jwk.Crv != want.crv || jwk.Kid != "k1" || jwk.Alg != algorithm || jwk.Use != "sig" {
+			t.Errorf("%s: unexpected JWK %+v", algorithm, jwk)
+		}
+		if algorithm == AlgorithmES256 && (len(jwk.X) != 43 || len(jwk.Y) != 43) {
+			t.Errorf("ES256 coordinates should be 32 bytes, got x=%q y=%q", jwk.X, jwk.Y)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	signer := newTestSigner(t, AlgorithmRS256)
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("marshal private key failed: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("marshal public key failed: %v", err)
+	}
+	privFile := filepath.Join(dir, "private.pem")
+	pubFile := filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signingKey, err := LoadKey("k1", privFile, "")
+	if err != nil {
+		t.Fatalf("LoadKey with private key failed: %v", err)
+	}
+	verifyKey, err := LoadKey("k1", "", pubFile)
+	if err != nil {
+		t.Fatalf("LoadKey with public key failed: %v", err)
+	}
+	if verifyKey.PrivateKey != nil {
+		t.Error("public-only key must not have a private key")
+	}
+
+	signing, err := New[TestClaims](&Config{Algorithm: AlgorithmRS256, Keys: []*Key{signingKey}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	token, err := signing.GenerateToken(newTestClaims())
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// 只持有公钥的一方（例如其他服务）可以验证令牌
+	verifyOnly := &JWT[TestClaims]{method: jwt.SigningMethodRS256, keys: map[string]*Key{"k1": verifyKey}}
+	if _, err := verifyOnly.ParseToken(token); err != nil {
+		t.Errorf("ParseToken with public key failed: %v", err)
+	}
+
+	if _, err := LoadKey("k1", "", ""); err == nil {
+		t.Error("LoadKey without files should fail")
+	}
+	if _, err := LoadKey("k1", filepath.Join(dir, "missing.pem"), ""); err == nil {
+		t.Error("LoadKey with missing file should fail")
+	}
+}
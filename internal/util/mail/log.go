@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LogMailer 将邮件写入日志而不实际发送，用于本地开发
+type LogMailer struct {
+	logger *zap.Logger
+}
+
+// NewLogMailer 创建LogMailer实例
+func NewLogMailer(logger *zap.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send 将邮件内容写入日志
+func (m *LogMailer) Send(_ context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipient
+	}
+	m.logger.Info("Mail sent to log",
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+// FileMailer 将邮件保存为目录下的 .eml 文件，用于本地开发和测试
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+// NewFileMailer 创建FileMailer实例，目录不存在时自动创建
+func NewFileMailer(from, dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+// Send 将邮件写入以发送时间命名的 .eml 文件
+func (m *FileMailer) Send(_ context.Context, msg *Message) error {
+	content, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), content, 0o600)
+}
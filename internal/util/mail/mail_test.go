@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := NewFileMailer("GinHub <noreply@ginhub.dev>", dir)
+	if err != nil {
+		t.Fatalf("NewFileMailer failed: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), &Message{
+		To:      []string{"alice@example.com"},
+		Subject: "重置密码",
+		Body:    "line1\nline2",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected 1 mail file, got %d (%v)", len(files), err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"From: GinHub <noreply@ginhub.dev>\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"\r\n\r\nline1\r\nline2",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("mail content missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestBuildInvalidMessage(t *testing.T) {
+	if _, err := build("noreply@ginhub.dev", &Message{Subject: "hi"}); err != ErrNoRecipient {
+		t.Errorf("expected ErrNoRecipient, got %v", err)
+	}
+	if _, err := build("noreply@ginhub.dev", &Message{To: []string{"not an address"}}); err == nil {
+		t.Error("invalid recipient should fail")
+	}
+}
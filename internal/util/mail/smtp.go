@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不进行认证
+	Password string
+	From     string
+}
+
+// SMTPMailer 通过 SMTP 服务器发送邮件，服务器支持时自动使用 STARTTLS
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer 创建SMTPMailer实例
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send 通过 SMTP 发送邮件
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	content, err := build(m.cfg.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+
+	// smtp.SendMail 不支持 context，在独立的 goroutine 中发送以便请求取消时及时返回
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.cfg.From, msg.To, content)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
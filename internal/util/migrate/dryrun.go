@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// plan 将迁移会执行的 SQL 写入 Output
+func (m *Migrator) plan(db *gorm.DB, direction string, mig *Migration, fn func(tx *gorm.DB) error, sql string) error {
+	stmts := splitStatements(sql)
+	if fn != nil {
+		// DryRun 会话中写操作只生成 SQL 不执行，通过日志接口收集
+		rec := &recorder{}
+		if err := fn(db.Session(&gorm.Session{DryRun: true, Logger: rec})); err != nil {
+			return fmt.Errorf("failed to plan migration %s: %w", mig, err)
+		}
+		stmts = rec.stmts
+	}
+
+	fmt.Fprintf(m.dryRun, "-- %s %s\n", direction, mig)
+	for _, stmt := range stmts {
+		fmt.Fprintf(m.dryRun, "%s;\n", stmt)
+	}
+	fmt.Fprintln(m.dryRun)
+	return nil
+}
+
+// recorder 收集 DryRun 会话生成的 SQL，忽略表结构检查等只读查询
+type recorder struct {
+	stmts []string
+}
+
+func (r *recorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *recorder) Info(context.Context, string, ...any) {}
+
+func (r *recorder) Warn(context.Context, string, ...any) {}
+
+func (r *recorder) Error(context.Context, string, ...any) {}
+
+func (r *recorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "SELECT") {
+		return
+	}
+	r.stmts = append(r.stmts, sql)
+}
@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// lockTable 不支持会话锁的数据库使用的迁移锁表
+	lockTable = TableName + "_lock"
+	// lockLease 锁表中锁的有效期，持有锁的实例异常退出后，过期的锁会被其他实例清理
+	lockLease = 10 * time.Minute
+	// lockRetryInterval 获取锁失败后的重试间隔
+	lockRetryInterval = 200 * time.Millisecond
+)
+
+// withLock 持有迁移锁执行 fn，MySQL 使用 GET_LOCK，PostgreSQL 使用咨询锁，其他数据库使用锁表。DryRun 时不修改数据库，不加锁
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if m.dryRun != nil {
+		return fn(db)
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		return withMySQLLock(ctx, db, m.cfg.LockTimeout, fn)
+	case "postgres":
+		return withPostgresLock(ctx, db, m.cfg.LockTimeout, fn)
+	default:
+		return withTableLock(ctx, db, m.cfg.LockTimeout, fn)
+	}
+}
+
+// withMySQLLock 使用 MySQL 的命名锁，锁与连接绑定，连接断开时自动释放
+func withMySQLLock(ctx context.Context, db *gorm.DB, timeout time.Duration, fn func(db *gorm.DB) error) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	// 命名锁在整个 MySQL 实例内共享，加上库名避免不同数据库之间互相等待
+	name := "CONCAT(DATABASE(), '." + TableName + "')"
+	var got sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK("+name+", ?)", int(math.Ceil(timeout.Seconds()))).Scan(&got); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if got.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK("+name+")")
+
+	return fn(db)
+}
+
+// withPostgresLock 使用 PostgreSQL 的会话级咨询锁，锁与连接绑定，连接断开时自动释放
+func withPostgresLock(ctx context.Context, db *gorm.DB, timeout time.Duration, fn func(db *gorm.DB) error) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	// 咨询锁在整个 PostgreSQL 实例内共享，加上库名避免不同数据库之间互相等待
+	key := "hashtext(current_database() || '." + TableName + "')"
+	deadline := time.Now().Add(timeout)
+	for {
+		var got bool
+		if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock("+key+")").Scan(&got); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if got {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock("+key+")")
+
+	return fn(db)
+}
+
+// withTableLock 通过向锁表插入固定主键的记录获取锁
+func withTableLock(ctx context.Context, db *gorm.DB, timeout time.Duration, fn func(db *gorm.DB) error) error {
+	// 等待锁期间插入失败是预期的，不输出日志
+	quiet := db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)})
+	if err := quiet.Exec("CREATE TABLE IF NOT EXISTS " + lockTable + " (id integer PRIMARY KEY, expires_at bigint NOT NULL)").Error; err != nil {
+		return fmt.Errorf("failed to create %s table: %w", lockTable, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		now := time.Now()
+		// 清理异常退出的实例遗留的锁
+		if err := quiet.Exec("DELETE FROM "+lockTable+" WHERE id = 1 AND expires_at < ?", now.Unix()).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		err := quiet.Exec("INSERT INTO "+lockTable+" (id, expires_at) VALUES (1, ?)", now.Add(lockLease).Unix()).Error
+		if err == nil {
+			break
+		}
+		if now.After(deadline) {
+			return fmt.Errorf("%w: %v", ErrLockTimeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+	defer quiet.WithContext(context.Background()).Exec("DELETE FROM " + lockTable + " WHERE id = 1")
+
+	return fn(db)
+}
@@ -0,0 +1,452 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TableName 迁移记录表名
+const TableName = "schema_migrations"
+
+var (
+	// ErrChecksumMismatch 已执行的迁移文件内容被修改
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+	// ErrIrreversible 迁移没有回滚步骤
+	ErrIrreversible = errors.New("migration is irreversible")
+	// ErrUnknownMigration 数据库中记录的迁移在当前版本中不存在
+	ErrUnknownMigration = errors.New("unknown migration")
+	// ErrLockTimeout 等待迁移锁超时
+	ErrLockTimeout = errors.New("timed out waiting for migration lock")
+	// ErrNoAppliedMigration 没有已执行的迁移
+	ErrNoAppliedMigration = errors.New("no applied migration")
+)
+
+// Migration 一个版本的迁移，SQL 迁移设置 UpSQL/DownSQL，Go 迁移设置 Up/Down
+type Migration struct {
+	Version uint64
+	Name    string
+
+	UpSQL   string
+	DownSQL string
+
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// Checksum 返回 SQL 迁移内容的校验和，Go 迁移返回空字符串且不做校验
+func (m *Migration) Checksum() string {
+	if m.UpSQL == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(m.UpSQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// Reversible 迁移是否可以回滚
+func (m *Migration) Reversible() bool {
+	return m.Down != nil || m.DownSQL != ""
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
+
+// record 迁移记录
+type record struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	Checksum  string    `gorm:"type:varchar(64)"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (record) TableName() string {
+	return TableName
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // 为 nil 表示尚未执行
+
+	// ChecksumMismatch 已执行后迁移文件内容被修改
+	ChecksumMismatch bool `json:"checksum_mismatch,omitempty"`
+	// Unknown 数据库中有记录但当前版本中不存在，通常是数据库被更新的版本迁移过
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Config 迁移器配置
+type Config struct {
+	// LockTimeout 等待其他实例释放迁移锁的最长时间，为 0 时使用 1 分钟
+	LockTimeout time.Duration
+
+	// Adopt 迁移记录表不存在时调用，用于接管启用版本化迁移之前创建的数据库。
+	// 返回非 0 版本时，该版本及之前的迁移直接标记为已执行
+	Adopt func(db *gorm.DB) (uint64, error)
+}
+
+// Migrator 按版本顺序执行迁移，执行记录保存在 schema_migrations 表中。
+// 执行迁移时持有数据库级别的锁，多个实例同时启动时只有一个实例执行迁移。
+// 每个迁移在一个事务中执行，MySQL 的 DDL 语句会隐式提交事务，
+// 执行失败时需要人工检查表结构。
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+	cfg        Config
+
+	// dryRun 不为 nil 时只输出会执行的 SQL
+	dryRun io.Writer
+}
+
+// New 创建迁移器，迁移版本必须大于 0 且不能重复
+func New(db *gorm.DB, migrations []*Migration, cfg *Config) (*Migrator, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	for i, m := range sorted {
+		if m.Version == 0 {
+			return nil, fmt.Errorf("migration %q: version must be greater than 0", m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		if m.Up == nil && m.UpSQL == "" {
+			return nil, fmt.Errorf("migration %s has no up step", m)
+		}
+	}
+
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.LockTimeout <= 0 {
+		c.LockTimeout = time.Minute
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		cfg:        c,
+	}, nil
+}
+
+// DryRun 返回不修改数据库的迁移器，执行迁移时只将会执行的 SQL 写入 w。
+// Go 迁移中的写操作只生成 SQL 不执行，依赖前序迁移结果的 Go 迁移输出可能不完整
+func (m *Migrator) DryRun(w io.Writer) *Migrator {
+	dry := *m
+	dry.dryRun = w
+	return &dry
+}
+
+// Up 按版本顺序执行未执行的迁移，steps 大于 0 时最多执行 steps 个，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := m.prepare(db)
+		if err != nil {
+			return err
+		}
+		if err = m.verify(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err = m.apply(db, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按版本从新到旧回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, nil
+	}
+
+	var done []*Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		latest, err := m.latest(db, steps)
+		if err != nil {
+			return err
+		}
+		for _, mig := range latest {
+			if err = m.revert(db, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo 回滚最近执行的一个迁移后重新执行
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var mig *Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		latest, err := m.latest(db, 1)
+		if err != nil {
+			return err
+		}
+		if len(latest) == 0 {
+			return ErrNoAppliedMigration
+		}
+		mig = latest[0]
+		if err = m.revert(db, mig); err != nil {
+			return err
+		}
+		return m.apply(db, mig)
+	})
+	return mig, err
+}
+
+// Force 不执行迁移，直接将 version 及之前的迁移记录为已执行并删除之后的记录，
+// 用于迁移执行失败、人工修复表结构后修正迁移记录。version 为 0 时清空所有记录
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if m.dryRun != nil {
+		return errors.New("force does not support dry run")
+	}
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+	}
+
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		if !db.Migrator().HasTable(TableName) {
+			if err := db.Migrator().CreateTable(&record{}); err != nil {
+				return fmt.Errorf("failed to create %s table: %w", TableName, err)
+			}
+		}
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("version > ?", version).Delete(&record{}).Error; err != nil {
+				return err
+			}
+			applied, err := m.applied(tx)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for _, mig := range m.migrations {
+				if mig.Version > version {
+					break
+				}
+				if _, ok := applied[mig.Version]; ok {
+					continue
+				}
+				if err = tx.Create(&record{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum(), AppliedAt: now}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// Status 返回所有迁移的执行状态，按版本排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+	applied := map[uint64]record{}
+	if db.Migrator().HasTable(TableName) {
+		var err error
+		if applied, err = m.applied(db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if r, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &r.AppliedAt
+			s.ChecksumMismatch = r.Checksum != "" && r.Checksum != mig.Checksum()
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, r := range applied {
+		statuses = append(statuses, Status{Version: r.Version, Name: r.Name, AppliedAt: &r.AppliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
+
+// Pending 返回未执行的迁移
+func (m *Migrator) Pending(ctx context.Context) ([]*Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, m.find(s.Version))
+		}
+	}
+	return pending, nil
+}
+
+// prepare 创建迁移记录表并返回已执行的迁移，首次创建时通过 Adopt 接管已有的数据库
+func (m *Migrator) prepare(db *gorm.DB) (map[uint64]record, error) {
+	if db.Migrator().HasTable(TableName) {
+		return m.applied(db)
+	}
+	if m.dryRun != nil {
+		fmt.Fprintf(m.dryRun, "-- %s table does not exist, all migrations are pending\n\n", TableName)
+		return map[uint64]record{}, nil
+	}
+
+	var baseline uint64
+	if m.cfg.Adopt != nil {
+		var err error
+		if baseline, err = m.cfg.Adopt(db); err != nil {
+			return nil, fmt.Errorf("failed to adopt existing database: %w", err)
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&record{}); err != nil {
+			return fmt.Errorf("failed to create %s table: %w", TableName, err)
+		}
+		now := time.Now()
+		for _, mig := range m.migrations {
+			if mig.Version > baseline {
+				break
+			}
+			if err := tx.Create(&record{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum(), AppliedAt: now}).Error; err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", mig, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.applied(db)
+}
+
+// applied 返回已执行的迁移记录
+func (m *Migrator) applied(db *gorm.DB) (map[uint64]record, error) {
+	var records []record
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	applied := make(map[uint64]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// latest 返回最近执行的 n 个迁移，按版本从新到旧排序
+func (m *Migrator) latest(db *gorm.DB, n int) ([]*Migration, error) {
+	if !db.Migrator().HasTable(TableName) {
+		return nil, nil
+	}
+
+	var records []record
+	if err := db.Order("version DESC").Limit(n).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	migrations := make([]*Migration, 0, len(records))
+	for _, r := range records {
+		mig := m.find(r.Version)
+		if mig == nil {
+			return nil, fmt.Errorf("%w: version %d", ErrUnknownMigration, r.Version)
+		}
+		if !mig.Reversible() {
+			return nil, fmt.Errorf("%w: %s", ErrIrreversible, mig)
+		}
+		migrations = append(migrations, mig)
+	}
+	return migrations, nil
+}
+
+// verify 校验已执行的迁移内容未被修改
+func (m *Migrator) verify(applied map[uint64]record) error {
+	for _, mig := range m.migrations {
+		r, ok := applied[mig.Version]
+		if ok && r.Checksum != "" && r.Checksum != mig.Checksum() {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, mig)
+		}
+	}
+	return nil
+}
+
+// apply 在事务中执行迁移并写入记录
+func (m *Migrator) apply(db *gorm.DB, mig *Migration) error {
+	if m.dryRun != nil {
+		return m.plan(db, "up", mig, mig.Up, mig.UpSQL)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := run(tx, mig.Up, mig.UpSQL); err != nil {
+			return err
+		}
+		return tx.Create(&record{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum(), AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", mig, err)
+	}
+	return nil
+}
+
+// revert 在事务中回滚迁移并删除记录
+func (m *Migrator) revert(db *gorm.DB, mig *Migration) error {
+	if m.dryRun != nil {
+		return m.plan(db, "down", mig, mig.Down, mig.DownSQL)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := run(tx, mig.Down, mig.DownSQL); err != nil {
+			return err
+		}
+		return tx.Delete(&record{}, mig.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revert migration %s: %w", mig, err)
+	}
+	return nil
+}
+
+func (m *Migrator) find(version uint64) *Migration {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig
+		}
+	}
+	return nil
+}
+
+// run 执行 Go 迁移函数或逐条执行 SQL 语句
+func run(tx *gorm.DB, fn func(tx *gorm.DB) error, sql string) error {
+	if fn != nil {
+		return fn(tx)
+	}
+	for _, stmt := range splitStatements(sql) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/migrate.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
+
+func testMigrations(t *testing.T) []*Migration {
+	fsys := fstest.MapFS{
+		"sql/000001_create_notes.up.sql": {Data: []byte(`-- 创建 notes 表
+CREATE TABLE notes (
+    id integer PRIMARY KEY,
+    body text NOT NULL
+);
+CREATE INDEX idx_notes_body ON notes(body);
+`)},
+		"sql/000001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;\n")},
+		"sql/000003_add_title.up.sql":      {Data: []byte("ALTER TABLE notes ADD COLUMN title text;\n")},
+	}
+	migrations, err := Load(fsys, "sql")
+	require.NoError(t, err)
+
+	return append(migrations, &Migration{
+		Version: 2,
+		Name:    "seed_notes",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("INSERT INTO notes (id, body) VALUES (1, 'hello')").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM notes WHERE id = 1").Error
+		},
+	})
+}
+
+func TestUpAppliesInOrder(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, testMigrations(t), nil)
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	assert.Equal(t, []uint64{1, 2, 3}, []uint64{applied[0].Version, applied[1].Version, applied[2].Version})
+	assert.True(t, db.Migrator().HasColumn("notes", "title"))
+
+	var count int64
+	require.NoError(t, db.Table("notes").Count(&count).Error)
+	assert.EqualValues(t, 1, count)
+
+	// 再次执行时没有需要执行的迁移
+	applied, err = m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt)
+	}
+}
+
+func TestDown(t *testing.T) {
+	db := openTestDB(t)
+	migrations := testMigrations(t)
+	m, err := New(db, migrations, nil)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background(), 0)
+	require.NoError(t, err)
+
+	// 000003 没有 down 文件，不能回滚
+	_, err = m.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrIrreversible)
+
+	// 去掉 000003 后，数据库中的记录变为未知迁移
+	m, err = New(db, migrations[:1], nil)
+	require.NoError(t, err)
+	_, err = m.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnknownMigration)
+
+	require.NoError(t, db.Exec("ALTER TABLE notes DROP COLUMN title").Error)
+	require.NoError(t, db.Exec("DELETE FROM schema_migrations WHERE version = 3").Error)
+	m, err = New(db, migrations, nil)
+	require.NoError(t, err)
+	reverted, err := m.Down(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, reverted, 2)
+	assert.EqualValues(t, 2, reverted[0].Version)
+	assert.EqualValues(t, 1, reverted[1].Version)
+	assert.False(t, db.Migrator().HasTable("notes"))
+
+	pending, err := m.Pending(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, pending, 3)
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	db := openTestDB(t)
+	migrations := testMigrations(t)
+	m, err := New(db, migrations, nil)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background(), 0)
+	require.NoError(t, err)
+
+	// 已执行的迁移文件被修改后拒绝执行
+	migrations[0].UpSQL += "\n-- edited\n"
+	m, err = New(db, migrations, nil)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, statuses[0].ChecksumMismatch)
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, append(testMigrations(t), &Migration{
+		Version: 4,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("INSERT INTO notes (id, body) VALUES (2, 'partial')").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	}), nil)
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background(), 0)
+	assert.ErrorContains(t, err, "4_broken")
+	assert.Len(t, applied, 3)
+
+	// 失败的迁移在事务中回滚，且没有执行记录
+	var count int64
+	require.NoError(t, db.Table("notes").Count(&count).Error)
+	assert.EqualValues(t, 1, count)
+	pending, err := m.Pending(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.EqualValues(t, 4, pending[0].Version)
+}
+
+func TestAdoptExistingDatabase(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.Exec("CREATE TABLE notes (id integer PRIMARY KEY, body text NOT NULL)").Error)
+
+	m, err := New(db, testMigrations(t), &Config{
+		Adopt: func(db *gorm.DB) (uint64, error) {
+			if db.Migrator().HasTable("notes") {
+				return 2, nil
+			}
+			return 0, nil
+		},
+	})
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.EqualValues(t, 3, applied[0].Version)
+}
+
+func TestConcurrentUp(t *testing.T) {
+	db := openTestDB(t)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := New(db, testMigrations(t), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			applied, err := m.Up(context.Background(), 0)
+			assert.NoError(t, err)
+			mu.Lock()
+			total += len(applied)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// 每个迁移只被一个实例执行
+	assert.Equal(t, 3, total)
+}
+
+func TestLockTimeout(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.Exec("CREATE TABLE "+lockTable+" (id integer PRIMARY KEY, expires_at bigint NOT NULL)").Error)
+	require.NoError(t, db.Exec("INSERT INTO "+lockTable+" (id, expires_at) VALUES (1, ?)", time.Now().Add(time.Hour).Unix()).Error)
+
+	m, err := New(db, testMigrations(t), &Config{LockTimeout: 300 * time.Millisecond})
+	require.NoError(t, err)
+	_, err = m.Up(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	// 过期的锁会被清理
+	require.NoError(t, db.Exec("UPDATE "+lockTable+" SET expires_at = ?", time.Now().Add(-time.Minute).Unix()).Error)
+	_, err = m.Up(context.Background(), 0)
+	assert.NoError(t, err)
+}
+
+func TestNewValidatesMigrations(t *testing.T) {
+	_, err := New(nil, []*Migration{{Version: 1, Name: "a", UpSQL: "SELECT 1"}, {Version: 1, Name: "b", UpSQL: "SELECT 1"}}, nil)
+	assert.ErrorContains(t, err, "duplicate migration version")
+
+	_, err = New(nil, []*Migration{{Version: 0, Name: "a", UpSQL: "SELECT 1"}}, nil)
+	assert.Error(t, err)
+
+	_, err = New(nil, []*Migration{{Version: 1, Name: "a"}}, nil)
+	assert.ErrorContains(t, err, "no up step")
+}
+
+func TestLoadInvalidFiles(t *testing.T) {
+	_, err := Load(fstest.MapFS{"sql/create_notes.up.sql": {Data: []byte("SELECT 1;")}}, "sql")
+	assert.ErrorContains(t, err, "invalid migration file name")
+
+	_, err = Load(fstest.MapFS{"sql/000001_create_notes.down.sql": {Data: []byte("SELECT 1;")}}, "sql")
+	assert.ErrorContains(t, err, "no up file")
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("-- comment\nCREATE TABLE a (\n    id integer\n);\n\nINSERT INTO a VALUES (1);\nSELECT 1")
+	assert.Equal(t, []string{"CREATE TABLE a (\n    id integer\n)", "INSERT INTO a VALUES (1)", "SELECT 1"}, stmts)
+}
+
+func TestUpSteps(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, testMigrations(t), nil)
+	require.NoError(t, err)
+
+	applied, err := m.Up(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.False(t, db.Migrator().HasColumn("notes", "title"))
+
+	pending, err := m.Pending(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.EqualValues(t, 3, pending[0].Version)
+}
+
+func TestRedo(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, testMigrations(t), nil)
+	require.NoError(t, err)
+
+	_, err = m.Redo(context.Background())
+	assert.ErrorIs(t, err, ErrNoAppliedMigration)
+
+	_, err = m.Up(context.Background(), 2)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("UPDATE notes SET body = 'changed' WHERE id = 1").Error)
+
+	mig, err := m.Redo(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, mig.Version)
+
+	var body string
+	require.NoError(t, db.Raw("SELECT body FROM notes WHERE id = 1").Scan(&body).Error)
+	assert.Equal(t, "hello", body)
+}
+
+func TestForce(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, testMigrations(t), nil)
+	require.NoError(t, err)
+
+	// 表结构已人工创建，只修正迁移记录
+	require.NoError(t, db.Exec("CREATE TABLE notes (id integer PRIMARY KEY, body text NOT NULL)").Error)
+	require.NoError(t, m.Force(context.Background(), 2))
+	pending, err := m.Pending(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.EqualValues(t, 3, pending[0].Version)
+
+	require.NoError(t, m.Force(context.Background(), 1))
+	pending, err = m.Pending(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	assert.ErrorIs(t, m.Force(context.Background(), 9), ErrUnknownMigration)
+	assert.Error(t, m.DryRun(io.Discard).Force(context.Background(), 1))
+}
+
+func TestDryRun(t *testing.T) {
+	db := openTestDB(t)
+	m, err := New(db, testMigrations(t), nil)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	applied, err := m.DryRun(&out).Up(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Len(t, applied, 3)
+	assert.Contains(t, out.String(), "-- up 000001_create_notes\nCREATE TABLE notes")
+	assert.Contains(t, out.String(), "INSERT INTO notes (id, body) VALUES (1, 'hello');")
+	assert.Contains(t, out.String(), "ALTER TABLE notes ADD COLUMN title text;")
+
+	// 不修改数据库
+	assert.False(t, db.Migrator().HasTable("notes"))
+	assert.False(t, db.Migrator().HasTable(TableName))
+
+	_, err = m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	out.Reset()
+	_, err = m.DryRun(&out).Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrIrreversible)
+	require.NoError(t, m.Force(context.Background(), 2))
+	_, err = m.DryRun(&out).Down(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "-- down 000002_seed_notes\nDELETE FROM notes WHERE id = 1;\n\n-- down 000001_create_notes\nDROP TABLE notes;\n\n", out.String())
+	assert.True(t, db.Migrator().HasTable("notes"))
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Create(dir, 7, "add_notes")
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir + "/000007_add_notes.up.sql", dir + "/000007_add_notes.down.sql"}, files)
+
+	// 新建的迁移文件可以被加载
+	migrations, err := Load(os.DirFS(dir), ".")
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.EqualValues(t, 7, migrations[0].Version)
+	assert.Empty(t, splitStatements(migrations[0].UpSQL))
+
+	_, err = Create(dir, 7, "add_notes")
+	assert.Error(t, err, "existing files should not be overwritten")
+	_, err = Create(dir, 8, "add notes")
+	assert.ErrorContains(t, err, "invalid migration name")
+}
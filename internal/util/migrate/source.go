@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// sqlFilePattern SQL 迁移文件名格式：<版本>_<名称>.up.sql 或 <版本>_<名称>.down.sql
+	sqlFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	// namePattern 迁移名称只能包含字母、数字和下划线
+	namePattern = regexp.MustCompile(`^\w+$`)
+)
+
+// fileTemplate 新建迁移文件的内容
+const fileTemplate = "-- %s %s\n-- 每条语句以分号结尾，分号后换行\n"
+
+// Load 读取 dir 目录下的 SQL 迁移文件，每个版本必须有 up 文件，down 文件可选
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	var migrations []*Migration
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := sqlFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+			migrations = append(migrations, mig)
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has different names: %s, %s", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.UpSQL = string(content)
+		} else {
+			mig.DownSQL = string(content)
+		}
+	}
+
+	for _, mig := range migrations {
+		if mig.UpSQL == "" {
+			return nil, fmt.Errorf("migration %s has no up file", mig)
+		}
+	}
+	return migrations, nil
+}
+
+// Create 在 dir 目录中创建指定版本的 up 和 down 迁移文件，返回创建的文件路径
+func Create(dir string, version uint64, name string) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q: only letters, digits and underscores are allowed", name)
+	}
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return files, fmt.Errorf("failed to create migration file: %w", err)
+		}
+		_, err = fmt.Fprintf(f, fileTemplate, fmt.Sprintf("%06d_%s", version, name), direction)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return files, fmt.Errorf("failed to write migration file: %w", err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// splitStatements 按行尾的分号拆分 SQL 语句，忽略 -- 开头的注释行。
+// 部分驱动（如默认配置的 MySQL）不支持一次执行多条语句
+func splitStatements(sql string) []string {
+	var (
+		stmts []string
+		cur   strings.Builder
+	)
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(cur.String()), ";"))
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
@@ -0,0 +1,74 @@
+package paging
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options 分页参数的默认值和允许排序的字段
+type Options struct {
+	DefaultSize int      // 未指定 size 时的每页数量，为 0 时使用 DefaultSize
+	MaxSize     int      // 每页数量上限，为 0 时使用 MaxSize
+	DefaultSort string   // 未指定 sort 时的排序方式，例如 "-created_at"
+	Sortable    []string // 允许排序的字段，为空时不允许通过参数指定排序
+}
+
+// Bind 从查询参数 page、size、sort 和 cursor 中读取分页参数，例如 ?page=2&size=20&sort=-created_at。
+// 提供 page 时使用偏移分页，否则使用游标分页；page 和 cursor 不能同时提供
+func Bind(ctx *gin.Context, opts Options) (Params, error) {
+	var params Params
+
+	if v := ctx.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 || page > MaxPage {
+			return params, fmt.Errorf("%w: page must be between 1 and %d", ErrInvalidPage, MaxPage)
+		}
+		params.Page = page
+	}
+
+	params.Size = opts.DefaultSize
+	if params.Size <= 0 {
+		params.Size = DefaultSize
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = MaxSize
+	}
+	if v := ctx.Query("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > maxSize {
+			return params, fmt.Errorf("%w: size must be between 1 and %d", ErrInvalidPage, maxSize)
+		}
+		params.Size = size
+	}
+
+	sort := ctx.Query("sort")
+	var allowed []string
+	if sort == "" {
+		sort = opts.DefaultSort
+	} else if allowed = opts.Sortable; len(allowed) == 0 {
+		return params, fmt.Errorf("%w: sorting is not supported", ErrInvalidSort)
+	}
+	sorts, err := ParseSort(sort, allowed...)
+	if err != nil {
+		return params, err
+	}
+	params.Sort = sorts
+
+	if v := ctx.Query("cursor"); v != "" {
+		if params.Page > 0 {
+			return params, fmt.Errorf("%w: page and cursor cannot be used together", ErrInvalidPage)
+		}
+		cursor, err := DecodeCursor(v)
+		if err != nil {
+			return params, err
+		}
+		if cursor.Sort != FormatSort(params.Sort) {
+			return params, fmt.Errorf("%w: sort does not match", ErrInvalidCursor)
+		}
+		params.Cursor = cursor
+	}
+	return params, nil
+}
@@ -0,0 +1,145 @@
+package paging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultSize 未指定时的每页数量
+	DefaultSize = 20
+	// MaxSize 默认的每页数量上限
+	MaxSize = 100
+	// MaxPage 页码上限，避免过大的页码使偏移量溢出
+	MaxPage = 1_000_000
+)
+
+var (
+	// ErrInvalidPage 页码或每页数量无效
+	ErrInvalidPage = errors.New("invalid page parameters")
+	// ErrInvalidSort 排序字段不存在或不允许排序
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrInvalidCursor 游标格式错误或与排序方式不匹配
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Sort 排序字段
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort 解析逗号分隔的排序参数，前缀 - 表示降序，例如 "-created_at,username"；
+// allowed 不为空时字段必须在其中
+func ParseSort(s string, allowed ...string) ([]Sort, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var sorts []Sort
+	for part := range strings.SplitSeq(s, ",") {
+		sort := Sort{Field: strings.TrimSpace(part)}
+		if field, ok := strings.CutPrefix(sort.Field, "-"); ok {
+			sort.Field, sort.Desc = field, true
+		}
+		if sort.Field == "" || (len(allowed) > 0 && !slices.Contains(allowed, sort.Field)) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSort, part)
+		}
+		if slices.ContainsFunc(sorts, func(s Sort) bool { return s.Field == sort.Field }) {
+			return nil, fmt.Errorf("%w: duplicated %q", ErrInvalidSort, sort.Field)
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// FormatSort 将排序字段格式化为排序参数，是 ParseSort 的逆操作
+func FormatSort(sorts []Sort) string {
+	parts := make([]string, 0, len(sorts))
+	for _, s := range sorts {
+		if s.Desc {
+			parts = append(parts, "-"+s.Field)
+		} else {
+			parts = append(parts, s.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Params 分页查询参数。Page 大于 0 时使用偏移分页并返回总数，否则使用游标分页，
+// 下一页通过 Cursor 传入上一页返回的 next_cursor
+type Params struct {
+	Page   int
+	Size   int
+	Sort   []Sort
+	Cursor *Cursor
+}
+
+// Limit 每页数量，未指定时使用 DefaultSize
+func (p Params) Limit() int {
+	if p.Size <= 0 {
+		return DefaultSize
+	}
+	return p.Size
+}
+
+// Offset 偏移分页跳过的记录数，页码超过 MaxPage 时按 MaxPage 计算
+func (p Params) Offset() int {
+	if p.Page <= 1 {
+		return 0
+	}
+	return (min(p.Page, MaxPage) - 1) * p.Limit()
+}
+
+// Page 分页查询结果
+// swagger:model Page
+type Page[T any] struct {
+	Items      []T    `json:"items" description:"当前页的数据"`
+	Total      *int64 `json:"total,omitempty" example:"42" description:"总数，仅偏移分页时返回"`
+	Page       int    `json:"page,omitempty" example:"1" description:"当前页码，仅偏移分页时返回"`
+	Size       int    `json:"size" example:"20" description:"每页数量"`
+	NextCursor string `json:"next_cursor,omitempty" description:"下一页游标，仅游标分页且存在下一页时返回"`
+}
+
+// Map 转换分页结果中的每一项，用于将模型转换为响应结构
+func Map[T, U any](p *Page[T], fn func(*T) U) *Page[U] {
+	items := make([]U, 0, len(p.Items))
+	for i := range p.Items {
+		items = append(items, fn(&p.Items[i]))
+	}
+	return &Page[U]{
+		Items:      items,
+		Total:      p.Total,
+		Page:       p.Page,
+		Size:       p.Size,
+		NextCursor: p.NextCursor,
+	}
+}
+
+// Cursor 游标分页位置，Values 为上一页最后一条记录各排序字段的值，只能用于生成它的排序方式
+type Cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Encode 将游标编码为可放在查询参数中的字符串
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor 解析 Encode 生成的游标
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
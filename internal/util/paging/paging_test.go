@@ -0,0 +1,87 @@
+package paging
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	sorts, err := ParseSort("-created_at, username")
+	require.NoError(t, err)
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "username"}}, sorts)
+	assert.Equal(t, "-created_at,username", FormatSort(sorts))
+
+	_, err = ParseSort("-created_at,password", "created_at", "username")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = ParseSort("id,-id")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = ParseSort("id,")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestCursor(t *testing.T) {
+	cursor := &Cursor{Sort: "-created_at", Values: []json.RawMessage{json.RawMessage(`"2024-01-01T00:00:00Z"`), json.RawMessage(`42`)}}
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = DecodeCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	opts := Options{DefaultSort: "-created_at", Sortable: []string{"created_at", "username"}}
+	bind := func(query string, opts Options) (Params, error) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/?"+query, nil)
+		return Bind(ctx, opts)
+	}
+
+	// 默认值
+	params, err := bind("", opts)
+	require.NoError(t, err)
+	assert.Equal(t, Params{Size: DefaultSize, Sort: []Sort{{Field: "created_at", Desc: true}}}, params)
+
+	params, err = bind("page=3&size=10&sort=username", opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, params.Page)
+	assert.Equal(t, 10, params.Limit())
+	assert.Equal(t, 20, params.Offset())
+	// 直接构造的过大页码不会使偏移量溢出
+	assert.Equal(t, (MaxPage-1)*DefaultSize, Params{Page: math.MaxInt}.Offset())
+	assert.Equal(t, []Sort{{Field: "username"}}, params.Sort)
+
+	// 游标的排序方式必须与本次请求一致
+	cursor := (&Cursor{Sort: "username", Values: []json.RawMessage{json.RawMessage(`"bob"`)}}).Encode()
+	params, err = bind("sort=username&cursor="+cursor, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "username", params.Cursor.Sort)
+	_, err = bind("cursor="+cursor, opts)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	for _, query := range []string{"page=0", "page=x", "page=1000001", "page=9223372036854775807", "size=0", "size=101", "page=1&cursor=" + cursor} {
+		_, err = bind(query, opts)
+		assert.ErrorIs(t, err, ErrInvalidPage, query)
+	}
+	_, err = bind("sort=password", opts)
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = bind("sort=username", Options{})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestMap(t *testing.T) {
+	total := int64(2)
+	page := Map(&Page[int]{Items: []int{1, 2}, Total: &total, Page: 1, Size: 20}, func(v *int) string {
+		return string(rune('a' + *v - 1))
+	})
+	assert.Equal(t, []string{"a", "b"}, page.Items)
+	assert.Equal(t, &total, page.Total)
+	assert.Equal(t, 20, page.Size)
+}
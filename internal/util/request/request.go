@@ -0,0 +1,25 @@
+package request
+
+import "context"
+
+// ClientInfo 发起请求的客户端信息
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// clientInfoKey 是一个未导出的类型，用作在上下文中存储客户端信息的键
+type clientInfoKey struct{}
+
+// NewContext 将客户端信息存储到上下文中
+func NewContext(ctx context.Context, info *ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// FromContext 从上下文中检索客户端信息，不存在时返回空的 ClientInfo
+func FromContext(ctx context.Context) *ClientInfo {
+	if info, ok := ctx.Value(clientInfoKey{}).(*ClientInfo); ok {
+		return info
+	}
+	return &ClientInfo{}
+}
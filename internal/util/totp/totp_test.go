@@ -0,0 +1,102 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 中 SHA1 的测试向量
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, want := range vectors {
+		got := hotp(key, uint64(Step(time.Unix(unix, 0))), 8)
+		if got != want {
+			t.Errorf("T=%d: got %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	code, err := GenerateCode(secret, now)
+	if err != nil {
+		t.Fatalf("GenerateCode failed: %v", err)
+	}
+	if len(code) != Digits {
+		t.Fatalf("code should have %d digits, got %q", Digits, code)
+	}
+
+	step, ok := Validate(secret, code, now, 1)
+	if !ok || step != Step(now) {
+		t.Errorf("current code should validate at step %d, got %d %v", Step(now), step, ok)
+	}
+
+	// 上一个时间步的验证码在允许的偏差内
+	previous, _ := GenerateCode(secret, now.Add(-Period*time.Second))
+	if step, ok := Validate(secret, previous, now, 1); !ok || step != Step(now)-1 {
+		t.Errorf("previous code should validate with skew 1, got %d %v", step, ok)
+	}
+	if _, ok := Validate(secret, previous, now, 0); ok {
+		t.Error("previous code should not validate with skew 0")
+	}
+
+	// 超出偏差的验证码被拒绝
+	old, _ := GenerateCode(secret, now.Add(-5*Period*time.Second))
+	if _, ok := Validate(secret, old, now, 1); ok {
+		t.Error("old code should not validate")
+	}
+
+	for _, bad := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := Validate(secret, bad, now, 1); ok {
+			t.Errorf("code %q should not validate", bad)
+		}
+	}
+	if _, ok := Validate("not base32!", code, now, 1); ok {
+		t.Error("invalid secret should not validate")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret failed: %v", err)
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) != SecretSize {
+		t.Errorf("secret should decode to %d bytes, got %d (%v)", SecretSize, len(key), err)
+	}
+
+	// 小写和空格分组的密钥同样可用
+	code, err := GenerateCode(strings.ToLower(secret[:4]+" "+secret[4:]), time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("GenerateCode with formatted secret failed: %v", err)
+	}
+	want, _ := GenerateCode(secret, time.Unix(0, 0))
+	if code != want {
+		t.Errorf("formatted secret produced %s, want %s", code, want)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("GinHub", "john doe", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/GinHub:john%20doe?algorithm=SHA1&digits=6&issuer=GinHub&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != want {
+		t.Errorf("got %s, want %s", uri, want)
+	}
+}